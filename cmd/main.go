@@ -25,6 +25,13 @@ Perfect for maintaining consistent API documentation across microservices and do
 
 	rootCmd.AddCommand(internal.NewConvertCommand())
 	rootCmd.AddCommand(internal.NewSyncCommand())
+	rootCmd.AddCommand(internal.NewNginxCheckCommand())
+	rootCmd.AddCommand(internal.NewRoutesCommand())
+	rootCmd.AddCommand(internal.NewMockCommand())
+	rootCmd.AddCommand(internal.NewExamplesCommand())
+	rootCmd.AddCommand(internal.NewContractCommand())
+	rootCmd.AddCommand(internal.NewGenerateCommand())
+	rootCmd.AddCommand(internal.NewJSONSchemaCommand())
 
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
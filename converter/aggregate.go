@@ -0,0 +1,161 @@
+package converter
+
+import (
+	"fmt"
+	"github.com/nimling/openapi-converter/utils"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// GatewayAggregate collects the Nginx output of every spec in a convert run so routes can be
+// checked against each other and combined into a single server block
+type GatewayAggregate struct {
+	HTTPContext *HTTPContext
+	specs       []*aggregatedSpec
+}
+
+type aggregatedSpec struct {
+	FilePath     string
+	Name         string
+	Upstream     string
+	Locations    string
+	upstreamName string
+	routes       []aggregatedRoute
+}
+
+type aggregatedRoute struct {
+	path     string
+	location string
+	regex    *regexp.Regexp
+}
+
+func NewGatewayAggregate(httpContext *HTTPContext) *GatewayAggregate {
+	if httpContext == nil {
+		httpContext = NewHTTPContext()
+	}
+
+	return &GatewayAggregate{HTTPContext: httpContext}
+}
+
+// Add renders the locations and upstream of a spec and records its routes
+func (g *GatewayAggregate) Add(conv *OpenAPIConverter) error {
+	conv.HTTPContext = g.HTTPContext
+
+	locations, err := conv.WriteNginxConfiguration()
+	if err != nil {
+		return err
+	}
+
+	upstream, err := conv.WriteNginxUpstream()
+	if err != nil {
+		return err
+	}
+
+	table, err := conv.RouteTable()
+	if err != nil {
+		return err
+	}
+
+	spec := &aggregatedSpec{
+		FilePath:     conv.filePath,
+		Name:         strings.TrimSuffix(filepath.Base(conv.filePath), filepath.Ext(conv.filePath)),
+		Upstream:     upstream,
+		Locations:    locations,
+		upstreamName: table.Upstream.Name,
+	}
+
+	for _, route := range table.Routes {
+		aggregated := aggregatedRoute{path: route.Path, location: nginxLocationMatch(route.Path)}
+		if route.Match.Templated {
+			aggregated.regex = regexp.MustCompile(route.Match.Regex)
+		}
+		spec.routes = append(spec.routes, aggregated)
+	}
+
+	g.specs = append(g.specs, spec)
+	return nil
+}
+
+// Conflicts lists the routes of different specs that Nginx could not tell apart: identical
+// locations, regex locations matching the literal path of another spec, which only the exact
+// location keeps from the regex spec, and overlapping regex locations, where file order would decide
+func (g *GatewayAggregate) Conflicts() []string {
+	var conflicts []string
+
+	for i, a := range g.specs {
+		for _, b := range g.specs[i+1:] {
+			if a.upstreamName == b.upstreamName {
+				conflicts = append(conflicts, fmt.Sprintf("'%s' and '%s' both define upstream '%s'", a.FilePath, b.FilePath, a.upstreamName))
+			}
+
+			for _, ra := range a.routes {
+				for _, rb := range b.routes {
+					if conflict := routeConflict(a, ra, b, rb); conflict != "" {
+						conflicts = append(conflicts, conflict)
+					}
+				}
+			}
+		}
+	}
+
+	return conflicts
+}
+
+func routeConflict(a *aggregatedSpec, ra aggregatedRoute, b *aggregatedSpec, rb aggregatedRoute) string {
+	switch {
+	case ra.location == rb.location:
+		return fmt.Sprintf("'%s' and '%s' both claim location '%s'", a.FilePath, b.FilePath, ra.location)
+	case ra.regex != nil && rb.regex == nil && ra.regex.MatchString(rb.path):
+		return fmt.Sprintf("'%s' path '%s' shadows '%s' path '%s'", a.FilePath, ra.path, b.FilePath, rb.path)
+	case rb.regex != nil && ra.regex == nil && rb.regex.MatchString(ra.path):
+		return fmt.Sprintf("'%s' path '%s' shadows '%s' path '%s'", b.FilePath, rb.path, a.FilePath, ra.path)
+	case ra.regex != nil && rb.regex != nil && (ra.regex.MatchString(samplePath(rb.path)) || rb.regex.MatchString(samplePath(ra.path))):
+		return fmt.Sprintf("'%s' path '%s' overlaps '%s' path '%s'", a.FilePath, ra.path, b.FilePath, rb.path)
+	}
+
+	return ""
+}
+
+// samplePath fills every path parameter with a placeholder segment
+func samplePath(path string) string {
+	return pathParameterPattern.ReplaceAllString(path, "sample")
+}
+
+// WriteServer renders the http-level definitions, the upstreams and one server block holding
+// the locations of every spec
+func (g *GatewayAggregate) WriteServer() (string, error) {
+	httpConfig, err := g.HTTPContext.Render()
+	if err != nil {
+		return "", err
+	}
+
+	data := struct {
+		HTTPContext string
+		Specs       []*aggregatedSpec
+	}{
+		HTTPContext: httpConfig,
+		Specs:       g.specs,
+	}
+
+	return utils.ExecuteTemplate(utils.FormatRaw, "gateway", gatewayServerTemplate, data)
+}
+
+// WriteIncludeIndex renders a server block including the per-spec location files, which are
+// expected as <spec>.conf in includePath once envsubst has rendered the templates
+func (g *GatewayAggregate) WriteIncludeIndex(includePath string) (string, error) {
+	data := struct {
+		IncludePath string
+		Specs       []*aggregatedSpec
+	}{
+		IncludePath: strings.TrimSuffix(includePath, "/"),
+		Specs:       g.specs,
+	}
+
+	return utils.ExecuteTemplate(utils.FormatRaw, "gateway-index", gatewayIndexTemplate, data)
+}
+
+// IndentedLocations prefixes every non-empty line of the locations for nesting inside the server block
+func (s *aggregatedSpec) IndentedLocations() string {
+	return indentLines(s.Locations, "    ")
+}
@@ -0,0 +1,266 @@
+package converter
+
+import (
+	"fmt"
+	"github.com/nimling/openapi-converter/utils"
+	"os"
+	"strings"
+)
+
+const defaultCredentialsPath = "/etc/nginx/auth"
+
+// nginxAuth holds the checks of a location. Rejected explains the operations whose requirement
+// Nginx cannot check; they are answered with 401, for every method or, when RejectVariable is set,
+// for the methods the variable marks with 1
+type nginxAuth struct {
+	APIKeys        []nginxAPIKeyCheck
+	Basic          *nginxBasicAuth
+	Bearer         *nginxBearerAuth
+	Rejected       []string
+	RejectVariable string
+}
+
+// nginxAPIKeyCheck rejects the request when Variable is 0, which happens when the key is
+// missing or not listed in the key file of the scheme
+type nginxAPIKeyCheck struct {
+	Variable string
+}
+
+type nginxBasicAuth struct {
+	Realm    string
+	UserFile string
+}
+
+// nginxBearerAuth is the internal location the auth_request subrequest is sent to. Methods lists
+// the methods that need a token when not every method of the route does
+type nginxBearerAuth struct {
+	Path             string
+	Location         string
+	IntrospectionURL string
+	TLS              bool
+	Methods          string
+	Scopes           string
+}
+
+// auth resolves the security requirements of the operations of a route into the Nginx checks
+// enforcing them. API keys are looked up in a map including the key file of the scheme, Basic
+// auth uses the htpasswd file of the scheme and bearer tokens are sent to the introspection
+// endpoint of x-auth. When the operations of a route disagree, the checks are keyed on the request
+// method with maps registered in the http context. Operations whose requirement Nginx cannot check
+// fail closed: they are rejected with 401 and a warning is printed, so the conversion still succeeds
+func (n *OpenAPIConverter) auth(table *RouteTable, route *Route) (*nginxAuth, error) {
+	credentialsPath, introspectionURL := defaultCredentialsPath, ""
+	if table.Auth != nil {
+		if table.Auth.CredentialsPath != "" {
+			credentialsPath = strings.TrimSuffix(table.Auth.CredentialsPath, "/")
+		}
+		introspectionURL = table.Auth.IntrospectionURL
+	}
+
+	apiKeys := map[string][]string{}
+	basic := map[string][]string{}
+	var bearerMethods []string
+	scopes := map[string][]string{}
+
+	var rejected, rejectedMethods []string
+	reject := func(method string, reason string) {
+		rejected = append(rejected, fmt.Sprintf("%s: %s", method, reason))
+		rejectedMethods = append(rejectedMethods, method)
+		warnRejectedOperation("Nginx", n.filePath, route.Path, method, reason)
+	}
+
+	for _, op := range route.Operations {
+		requirement, err := op.Requirement()
+		if err != nil {
+			reject(op.Method, err.Error())
+			continue
+		}
+
+		reason := ""
+		for _, name := range sortedKeys(requirement) {
+			scheme, err := table.SecurityScheme(name)
+			if err != nil {
+				return nil, fmt.Errorf("file '%s': path '%s' %s operation: %w", n.filePath, route.Path, op.Method, err)
+			}
+			if reason = nginxAuthGap(name, scheme, introspectionURL); reason != "" {
+				break
+			}
+		}
+		if reason != "" {
+			reject(op.Method, reason)
+			continue
+		}
+
+		bearer := false
+		for _, name := range sortedKeys(requirement) {
+			scheme := table.SecuritySchemes[name]
+
+			switch {
+			case scheme.Type == "apiKey":
+				apiKeys[name] = append(apiKeys[name], op.Method)
+			case scheme.Type == "http" && strings.EqualFold(scheme.Scheme, "basic"):
+				basic[name] = append(basic[name], op.Method)
+			default:
+				bearer = true
+				scopes[op.Method] = append(scopes[op.Method], requirement[name]...)
+			}
+		}
+
+		if bearer {
+			bearerMethods = append(bearerMethods, op.Method)
+		}
+	}
+
+	if len(apiKeys) == 0 && len(basic) == 0 && len(bearerMethods) == 0 && len(rejected) == 0 {
+		return nil, nil
+	}
+
+	result := &nginxAuth{Rejected: rejected}
+	source := fmt.Sprintf("'%s' path '%s'", n.filePath, route.Path)
+
+	if len(rejectedMethods) > 0 && len(rejectedMethods) < len(route.Methods) {
+		rejectMap := &nginxMap{
+			Source:   source,
+			Input:    "$request_method",
+			Variable: "$auth_rejected_" + route.Name,
+			Default:  "0",
+		}
+		for _, method := range rejectedMethods {
+			rejectMap.Entries = append(rejectMap.Entries, nginxMapEntry{Key: method, Value: "1"})
+		}
+		if err := n.httpContext().addMap(rejectMap); err != nil {
+			return nil, err
+		}
+		result.RejectVariable = rejectMap.Variable
+	}
+
+	for _, name := range sortedKeys(apiKeys) {
+		scheme := table.SecuritySchemes[name]
+		identifier := table.Spec + "_" + invalidIdentifierChars.ReplaceAllString(name, "_")
+
+		input := "$arg_" + scheme.Name
+		if scheme.In == "header" {
+			input = "$http_" + strings.ToLower(strings.ReplaceAll(scheme.Name, "-", "_"))
+		}
+
+		keyMap := &nginxMap{
+			Source:   fmt.Sprintf("'%s'", n.filePath),
+			Input:    input,
+			Variable: "$auth_key_" + identifier,
+			Default:  "0",
+			Include:  credentialsPath + "/" + identifier + ".map",
+		}
+		if err := n.httpContext().addMap(keyMap); err != nil {
+			return nil, err
+		}
+
+		check := nginxAPIKeyCheck{Variable: keyMap.Variable}
+		if methods := apiKeys[name]; len(methods) < len(route.Methods) {
+			allowedMap := &nginxMap{
+				Source:   source,
+				Input:    utils.QuoteConfigValue("$request_method:" + keyMap.Variable),
+				Variable: "$auth_key_allowed_" + route.Name + "_" + invalidIdentifierChars.ReplaceAllString(name, "_"),
+				Default:  "1",
+			}
+			for _, method := range methods {
+				allowedMap.Entries = append(allowedMap.Entries, nginxMapEntry{Key: method + ":0", Value: "0"})
+			}
+			if err := n.httpContext().addMap(allowedMap); err != nil {
+				return nil, err
+			}
+			check.Variable = allowedMap.Variable
+		}
+
+		result.APIKeys = append(result.APIKeys, check)
+	}
+
+	if len(basic) > 1 {
+		return nil, fmt.Errorf("file '%s': path '%s': Nginx can check only one Basic auth scheme per location, got %s", n.filePath, route.Path, strings.Join(sortedKeys(basic), ", "))
+	}
+	for name, methods := range basic {
+		identifier := table.Spec + "_" + invalidIdentifierChars.ReplaceAllString(name, "_")
+		result.Basic = &nginxBasicAuth{
+			Realm:    utils.QuoteConfigValue(name),
+			UserFile: credentialsPath + "/" + identifier + ".htpasswd",
+		}
+
+		if len(methods) < len(route.Methods) {
+			realmMap := &nginxMap{
+				Source:   source,
+				Input:    "$request_method",
+				Variable: "$auth_basic_realm_" + route.Name,
+				Default:  "off",
+			}
+			for _, method := range methods {
+				realmMap.Entries = append(realmMap.Entries, nginxMapEntry{Key: method, Value: result.Basic.Realm})
+			}
+			if err := n.httpContext().addMap(realmMap); err != nil {
+				return nil, err
+			}
+			result.Basic.Realm = realmMap.Variable
+		}
+	}
+
+	if len(bearerMethods) > 0 {
+		result.Bearer = &nginxBearerAuth{
+			Path:             route.Path,
+			Location:         "/_auth/" + route.Name,
+			IntrospectionURL: introspectionURL,
+			TLS:              strings.HasPrefix(introspectionURL, "https://"),
+		}
+		if len(bearerMethods) < len(route.Methods) {
+			result.Bearer.Methods = strings.Join(bearerMethods, "|")
+		}
+
+		scopeMap := &nginxMap{
+			Source:   source,
+			Input:    "$request_method",
+			Variable: "$auth_scopes_" + route.Name,
+			Default:  `""`,
+		}
+		distinct := map[string]bool{}
+		for _, method := range bearerMethods {
+			value := utils.QuoteConfigValue(strings.Join(scopes[method], " "))
+			distinct[value] = true
+			if len(scopes[method]) > 0 {
+				scopeMap.Entries = append(scopeMap.Entries, nginxMapEntry{Key: method, Value: value})
+			}
+		}
+
+		switch {
+		case len(scopeMap.Entries) == 0:
+		case len(distinct) == 1 && len(scopeMap.Entries) == len(bearerMethods):
+			result.Bearer.Scopes = scopeMap.Entries[0].Value
+		default:
+			if err := n.httpContext().addMap(scopeMap); err != nil {
+				return nil, err
+			}
+			result.Bearer.Scopes = scopeMap.Variable
+		}
+	}
+
+	return result, nil
+}
+
+// warnRejectedOperation tells on stderr that a gateway rejects every request of an operation
+// because it cannot check the operation's security requirement
+func warnRejectedOperation(gateway string, filePath string, path string, method string, reason string) {
+	fmt.Fprintf(os.Stderr, "⚠ %s rejects '%s' path '%s' %s with 401: %s\n", gateway, filePath, path, method, reason)
+}
+
+// nginxAuthGap explains why Nginx cannot check a security scheme, or returns "" when it can
+func nginxAuthGap(name string, scheme *SecurityScheme, introspectionURL string) string {
+	switch {
+	case scheme.Type == "apiKey" && (scheme.In == "header" || scheme.In == "query"):
+		return ""
+	case scheme.Type == "http" && strings.EqualFold(scheme.Scheme, "basic"):
+		return ""
+	case scheme.Type == "http" && strings.EqualFold(scheme.Scheme, "bearer"), scheme.Type == "oauth2", scheme.Type == "openIdConnect":
+		if introspectionURL == "" {
+			return fmt.Sprintf("security scheme '%s' needs x-auth introspectionUrl to check bearer tokens", name)
+		}
+		return ""
+	}
+
+	return fmt.Sprintf("security scheme '%s' of type '%s' cannot be checked by Nginx", name, scheme.Type)
+}
@@ -0,0 +1,134 @@
+package converter
+
+import (
+	"fmt"
+	"github.com/nimling/openapi-converter/utils"
+	"strconv"
+	"strings"
+)
+
+type caddyRoute struct {
+	Name           string
+	Path           string
+	Matcher        string
+	Methods        string
+	BasePath       string
+	Headers        []nginxHeader
+	Upstreams      string
+	LbPolicy       string
+	TLSServerName  string
+	DialTimeout    string
+	ReadTimeout    string
+	WriteTimeout   string
+	MaxRequestBody string
+}
+
+// WriteCaddyConfiguration renders the spec as a Caddyfile fragment meant to be imported inside a
+// site block. Every path gets a named matcher on its path and methods with a handle block proxying
+// to the upstream servers; paths below the stripped prefix are nested in a handle_path block,
+// which removes the prefix like the Nginx rewrite and comes first as the more specific route.
+// Caddy has no backup servers, so they are left out
+func (n *OpenAPIConverter) WriteCaddyConfiguration() (string, error) {
+	table, err := n.RouteTable()
+	if err != nil {
+		return "", err
+	}
+
+	return renderCaddy(table)
+}
+
+func renderCaddy(table *RouteTable) (string, error) {
+	upstream := table.Upstream
+
+	var upstreams, weights []string
+	weighted := false
+	for _, server := range upstream.Servers {
+		if server.Backup {
+			continue
+		}
+
+		upstreams = append(upstreams, server.Scheme+"://"+server.Address())
+		weight := server.Weight
+		if weight > 0 {
+			weighted = true
+		} else {
+			weight = 1
+		}
+		weights = append(weights, strconv.Itoa(weight))
+	}
+
+	lbPolicy := upstream.Balancing
+	if weighted && (lbPolicy == "" || lbPolicy == "round_robin") {
+		lbPolicy = "weighted_round_robin " + strings.Join(weights, " ")
+	}
+
+	var routes, prefixed []string
+	for _, route := range table.OrderedRoutes() {
+		settings := route.Settings
+		caddy := caddyRoute{
+			Name:           route.Name,
+			Path:           route.Path,
+			Methods:        strings.Join(route.Methods, " "),
+			BasePath:       strings.TrimSuffix(upstream.BasePath, "/"),
+			Headers:        settings.ResponseHeaders(),
+			Upstreams:      strings.Join(upstreams, " "),
+			LbPolicy:       lbPolicy,
+			MaxRequestBody: settings.ClientMaxBodySize,
+		}
+
+		if upstream.Scheme == "https" {
+			caddy.TLSServerName = upstream.Host
+		}
+
+		timeouts := []struct {
+			dst   *string
+			value string
+		}{
+			{&caddy.DialTimeout, settings.ConnectTimeout},
+			{&caddy.ReadTimeout, settings.ReadTimeout},
+			{&caddy.WriteTimeout, settings.SendTimeout},
+		}
+		for _, timeout := range timeouts {
+			var err error
+			if *timeout.dst, err = durationSeconds(timeout.value); err != nil {
+				return "", fmt.Errorf("file '%s': path '%s': %w", table.FilePath, route.Path, err)
+			}
+		}
+
+		matchPath := route.Path
+		if table.Prefix != "" && strings.HasPrefix(matchPath, table.Prefix+"/") {
+			matchPath = strings.TrimPrefix(matchPath, table.Prefix)
+		}
+
+		if isTemplatedPath(matchPath) {
+			caddy.Matcher = "path_regexp " + utils.QuoteConfigValue(pathTemplateRegex(matchPath))
+		} else {
+			caddy.Matcher = "path " + matchPath
+		}
+
+		block, err := utils.ExecuteTemplate(utils.FormatRaw, "caddy-route", caddyRouteTemplate, caddy)
+		if err != nil {
+			return "", err
+		}
+
+		if matchPath != route.Path {
+			prefixed = append(prefixed, indentLines(block, "\t"))
+		} else {
+			routes = append(routes, block)
+		}
+	}
+
+	data := struct {
+		FilePath string
+		Prefix   string
+		Routes   []string
+		Prefixed []string
+	}{
+		FilePath: table.FilePath,
+		Prefix:   table.Prefix,
+		Routes:   routes,
+		Prefixed: prefixed,
+	}
+
+	return utils.ExecuteTemplate(utils.FormatRaw, "caddy", caddyTemplate, data)
+}
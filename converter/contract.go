@@ -0,0 +1,363 @@
+package converter
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ContractRunner calls every operation of a spec on a running service and checks the responses
+// against the spec. Requests are built from the examples of the spec, or from generated values
+// when there are none
+type ContractRunner struct {
+	BaseURL string
+	Client  *http.Client
+	// Headers are sent with every request, typically the credentials of the security schemes
+	Headers http.Header
+	// Operations limits the run to the listed operation IDs
+	Operations []string
+	// Seed makes the generated parameters and bodies reproducible
+	Seed int64
+
+	table *RouteTable
+}
+
+// ContractResult is the outcome of calling one operation. Error is set when no response was
+// received, Failures lists the ways the response violates the spec
+type ContractResult struct {
+	OperationID string
+	Method      string
+	Path        string
+	URL         string
+	Status      int
+	Duration    time.Duration
+	Error       string
+	Failures    []string
+}
+
+// Name identifies the operation in reports
+func (r *ContractResult) Name() string {
+	if r.OperationID == "" {
+		return r.Method + " " + r.Path
+	}
+
+	return fmt.Sprintf("%s %s (%s)", r.Method, r.Path, r.OperationID)
+}
+
+// Passed reports whether the operation answered as specified
+func (r *ContractResult) Passed() bool {
+	return r.Error == "" && len(r.Failures) == 0
+}
+
+// ContractRunner creates a runner calling the operations of the resolved document on baseURL
+func (n *OpenAPIConverter) ContractRunner(baseURL string) (*ContractRunner, error) {
+	table, err := n.RouteTable()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := url.Parse(baseURL); err != nil || baseURL == "" {
+		return nil, fmt.Errorf("invalid base URL '%s'", baseURL)
+	}
+
+	return &ContractRunner{
+		BaseURL: strings.TrimSuffix(baseURL, "/"),
+		Client:  &http.Client{Timeout: 30 * time.Second},
+		Headers: http.Header{},
+		Seed:    1,
+		table:   table,
+	}, nil
+}
+
+// Run calls the operations in path order and returns one result per operation
+func (c *ContractRunner) Run() []*ContractResult {
+	var results []*ContractResult
+	for _, route := range c.table.Routes {
+		for _, op := range route.Operations {
+			if len(c.Operations) > 0 && !containsString(c.Operations, op.OperationID) {
+				continue
+			}
+			results = append(results, c.runOperation(route, op))
+		}
+	}
+
+	return results
+}
+
+func (c *ContractRunner) runOperation(route *Route, op *RouteOperation) *ContractResult {
+	result := &ContractResult{OperationID: op.OperationID, Method: op.Method, Path: route.Path}
+
+	req, err := c.request(route, op)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.URL = req.URL.String()
+
+	start := time.Now()
+	resp, err := c.Client.Do(req)
+	result.Duration = time.Since(start)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		result.Error = "failed to read response body: " + err.Error()
+		return result
+	}
+
+	result.Status = resp.StatusCode
+	result.Failures = c.table.ValidateResponse(op, resp.StatusCode, resp.Header, body)
+
+	return result
+}
+
+// request builds the request of an operation. Required parameters and parameters with an
+// example are sent; the body uses the JSON content type when the operation accepts one
+func (c *ContractRunner) request(route *Route, op *RouteOperation) (*http.Request, error) {
+	generator := NewExampleGenerator(c.table.Schemas, c.Seed)
+
+	path := route.Path
+	query := url.Values{}
+	header := http.Header{}
+	var cookies []*http.Cookie
+
+	for _, param := range route.OperationParams(op) {
+		if !param.Required && param.Example == nil {
+			continue
+		}
+
+		value := param.Example
+		if value == nil {
+			value = generator.generate(param.Schema, param.Name)
+		}
+
+		switch param.In {
+		case "path":
+			path = strings.ReplaceAll(path, "{"+param.Name+"}", url.PathEscape(paramString(value)))
+		case "query":
+			if items, ok := value.([]interface{}); ok {
+				for _, item := range items {
+					query.Add(param.Name, paramString(item))
+				}
+			} else {
+				query.Set(param.Name, paramString(value))
+			}
+		case "header":
+			header.Set(param.Name, paramString(value))
+		case "cookie":
+			cookies = append(cookies, &http.Cookie{Name: param.Name, Value: paramString(value)})
+		}
+	}
+
+	target := c.BaseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	var body io.Reader
+	contentType := ""
+	if op.Body != nil {
+		contentType = op.Body.ContentTypes[0]
+		for _, declared := range op.Body.ContentTypes {
+			if isJSONMediaType(declared) {
+				contentType = declared
+				break
+			}
+		}
+
+		value, ok := op.Body.Examples[contentType]
+		if !ok {
+			value = generator.Generate(op.Body.Schemas[contentType])
+		}
+
+		data, err := requestBody(value, contentType)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %s request body: %w", contentType, err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(op.Method, target, body)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, values := range c.Headers {
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if accept := responseContentTypes(op); len(accept) > 0 && req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", strings.Join(accept, ", "))
+	}
+
+	return req, nil
+}
+
+// paramString formats a parameter value the way it appears in a URL or header
+func paramString(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case []interface{}:
+		items := make([]string, 0, len(value))
+		for _, item := range value {
+			items = append(items, paramString(item))
+		}
+		return strings.Join(items, ",")
+	case map[string]interface{}:
+		data, _ := json.Marshal(value)
+		return string(data)
+	}
+
+	return fmt.Sprint(value)
+}
+
+// requestBody encodes a body value for a content type: JSON for JSON media types and form fields
+// for url encoded forms. Strings are sent as they are for any other content type
+func requestBody(value interface{}, contentType string) ([]byte, error) {
+	if text, ok := value.(string); ok && !isJSONMediaType(contentType) {
+		return []byte(text), nil
+	}
+
+	if mediaType(contentType) == "application/x-www-form-urlencoded" {
+		if fields, ok := value.(map[string]interface{}); ok {
+			form := url.Values{}
+			for _, name := range sortedKeys(fields) {
+				form.Set(name, paramString(fields[name]))
+			}
+			return []byte(form.Encode()), nil
+		}
+	}
+
+	return json.Marshal(value)
+}
+
+// responseContentTypes lists the content types any response of an operation declares
+func responseContentTypes(op *RouteOperation) []string {
+	seen := map[string]bool{}
+	for _, response := range op.Responses {
+		if response == nil {
+			continue
+		}
+		for contentType := range response.Content {
+			seen[contentType] = true
+		}
+	}
+
+	contentTypes := sortedKeys(seen)
+	sort.SliceStable(contentTypes, func(i, j int) bool {
+		return isJSONMediaType(contentTypes[i]) && !isJSONMediaType(contentTypes[j])
+	})
+
+	return contentTypes
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitProblem `xml:"failure,omitempty"`
+	Error     *junitProblem `xml:"error,omitempty"`
+}
+
+type junitProblem struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnitReport renders contract results as a JUnit XML report with one test suite named
+// after the spec and one test case per operation
+func WriteJUnitReport(suite string, results []*ContractResult, timestamp time.Time) (string, error) {
+	testSuite := junitTestSuite{
+		Name:      suite,
+		Tests:     len(results),
+		Timestamp: timestamp.UTC().Format(time.RFC3339),
+	}
+
+	var total time.Duration
+	for _, result := range results {
+		total += result.Duration
+
+		testCase := junitTestCase{
+			Name:      result.Name(),
+			ClassName: suite,
+			Time:      fmt.Sprintf("%.3f", result.Duration.Seconds()),
+		}
+
+		switch {
+		case result.Error != "":
+			testSuite.Errors++
+			testCase.Error = &junitProblem{Message: result.Error, Type: "request", Text: result.URL}
+		case len(result.Failures) > 0:
+			testSuite.Failures++
+			testCase.Failure = &junitProblem{
+				Message: result.Failures[0],
+				Type:    "contract",
+				Text:    fmt.Sprintf("%s %s answered %d:\n%s", result.Method, result.URL, result.Status, strings.Join(result.Failures, "\n")),
+			}
+		}
+
+		testSuite.Cases = append(testSuite.Cases, testCase)
+	}
+	testSuite.Time = fmt.Sprintf("%.3f", total.Seconds())
+
+	report := junitTestSuites{
+		Name:     suite,
+		Tests:    testSuite.Tests,
+		Failures: testSuite.Failures,
+		Errors:   testSuite.Errors,
+		Time:     testSuite.Time,
+		Suites:   []junitTestSuite{testSuite},
+	}
+
+	data, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal JUnit report: %w", err)
+	}
+
+	return xml.Header + string(data) + "\n", nil
+}
@@ -0,0 +1,68 @@
+package converter
+
+import (
+	"fmt"
+	"github.com/nimling/openapi-converter/utils"
+	"strings"
+)
+
+var defaultCORSHeaders = []string{"Authorization", "Content-Type"}
+
+type nginxCORS struct {
+	OriginVariable string
+	Methods        string
+	Headers        string
+	ExposeHeaders  string
+	Credentials    bool
+	MaxAge         int
+}
+
+// cors resolves the x-cors definition of a route and registers the map translating the request
+// Origin into the allowed origin. Origins that are not listed map to an empty value, which makes
+// Nginx skip the Access-Control-Allow-Origin header
+func (n *OpenAPIConverter) cors(route *Route) (*nginxCORS, error) {
+	definition := route.EffectiveCORS()
+	if definition == nil {
+		return nil, nil
+	}
+
+	path, variable := route.Path, "$cors_origin_"+n.specName()
+	if route.CORSScope == ScopePath {
+		variable = "$cors_origin_" + route.Name
+	}
+
+	originMap := &nginxMap{
+		Source:   fmt.Sprintf("'%s'", n.filePath),
+		Input:    "$http_origin",
+		Variable: variable,
+		Default:  `""`,
+	}
+	if route.CORSScope == ScopePath {
+		originMap.Source = fmt.Sprintf("'%s' path '%s'", n.filePath, path)
+	}
+
+	for _, origin := range definition.Origins {
+		switch {
+		case origin == "*" && definition.Credentials:
+			// Browsers reject a wildcard together with credentials, so the origin is echoed instead
+			originMap.Default = "$http_origin"
+		case origin == "*":
+			originMap.Default = `"*"`
+		default:
+			originMap.Entries = append(originMap.Entries, nginxMapEntry{Key: utils.QuoteConfigValue(origin), Value: "$http_origin"})
+		}
+	}
+
+	if err := n.httpContext().addMap(originMap); err != nil {
+		return nil, err
+	}
+
+	return &nginxCORS{
+		OriginVariable: variable,
+		Methods:        strings.Join(definition.Methods, ", "),
+		Headers:        strings.Join(definition.Headers, ", "),
+		ExposeHeaders:  strings.Join(definition.ExposeHeaders, ", "),
+		Credentials:    definition.Credentials,
+		MaxAge:         definition.MaxAge,
+	}, nil
+}
@@ -0,0 +1,156 @@
+package converter
+
+import (
+	"fmt"
+	"github.com/nimling/openapi-converter/envoy"
+	"gopkg.in/yaml.v3"
+	"strconv"
+)
+
+var envoyLbPolicies = map[string]string{
+	"":            "ROUND_ROBIN",
+	"round_robin": "ROUND_ROBIN",
+	"least_conn":  "LEAST_REQUEST",
+	"ip_hash":     "RING_HASH",
+	"random":      "RANDOM",
+}
+
+// WriteEnvoyConfiguration renders the spec as an Envoy RouteConfiguration with one virtual host
+// and the cluster its routes forward to. Every operation becomes a route matching its path and
+// :method; routes are ordered like Nginx picks locations, regex paths first and then the longest
+// literal prefix, since Envoy uses the first route that matches
+func (n *OpenAPIConverter) WriteEnvoyConfiguration() (string, error) {
+	table, err := n.RouteTable()
+	if err != nil {
+		return "", err
+	}
+
+	return renderEnvoy(table)
+}
+
+func renderEnvoy(table *RouteTable) (string, error) {
+	cluster, err := envoyCluster(table)
+	if err != nil {
+		return "", err
+	}
+
+	var rewrite *envoy.RegexMatchAndSubstitute
+	if pattern, replacement, ok := table.PathRewrite(); ok {
+		rewrite = &envoy.RegexMatchAndSubstitute{
+			Pattern:      &envoy.RegexMatcher{Regex: pattern},
+			Substitution: replacement,
+		}
+	}
+
+	var hashPolicy []*envoy.HashPolicy
+	if cluster.LbPolicy == "RING_HASH" {
+		hashPolicy = []*envoy.HashPolicy{{ConnectionProperties: &envoy.ConnectionProperties{SourceIP: true}}}
+	}
+
+	var routes []*envoy.Route
+	for _, route := range table.OrderedRoutes() {
+		timeout, err := durationSeconds(route.Settings.ReadTimeout)
+		if err != nil {
+			return "", fmt.Errorf("file '%s': path '%s': %w", table.FilePath, route.Path, err)
+		}
+
+		match := envoy.RouteMatch{Path: route.Path}
+		if route.Match.Templated {
+			match = envoy.RouteMatch{SafeRegex: &envoy.RegexMatcher{Regex: route.Match.Regex}}
+		}
+
+		for _, op := range route.Operations {
+			methodMatch := match
+			methodMatch.Headers = []*envoy.HeaderMatcher{{
+				Name:        ":method",
+				StringMatch: &envoy.StringMatch{Exact: op.Method},
+			}}
+
+			routes = append(routes, &envoy.Route{
+				Name:  op.OperationID,
+				Match: &methodMatch,
+				Route: &envoy.RouteAction{
+					Cluster:      cluster.Name,
+					RegexRewrite: rewrite,
+					Timeout:      timeout,
+					HashPolicy:   hashPolicy,
+				},
+			})
+		}
+	}
+
+	config := &envoy.Config{
+		RouteConfig: &envoy.RouteConfiguration{
+			Name: table.Spec + "_routes",
+			VirtualHosts: []*envoy.VirtualHost{{
+				Name:    table.Spec,
+				Domains: []string{"*"},
+				Routes:  routes,
+			}},
+		},
+		Clusters: []*envoy.Cluster{cluster},
+	}
+
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return "", fmt.Errorf("file '%s': failed to marshal Envoy configuration: %w", table.FilePath, err)
+	}
+
+	return string(data), nil
+}
+
+// envoyCluster builds the cluster from the upstream servers. Backup servers are placed in a
+// lower priority so they only receive traffic once the primary servers are unhealthy
+func envoyCluster(table *RouteTable) (*envoy.Cluster, error) {
+	upstream := table.Upstream
+
+	connectTimeout, err := durationSeconds(table.Settings.ConnectTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("file '%s': %w", table.FilePath, err)
+	}
+
+	primary := &envoy.LocalityLbEndpoints{}
+	backup := &envoy.LocalityLbEndpoints{Priority: 1}
+	for _, server := range upstream.Servers {
+		port, err := strconv.Atoi(server.PortOrDefault())
+		if err != nil {
+			return nil, fmt.Errorf("file '%s': server '%s' needs a numeric port for Envoy", table.FilePath, server.Origin())
+		}
+
+		endpoint := &envoy.LbEndpoint{
+			Endpoint:            &envoy.Endpoint{Address: &envoy.Address{SocketAddress: &envoy.SocketAddress{Address: server.Host, PortValue: port}}},
+			LoadBalancingWeight: server.Weight,
+		}
+
+		if server.Backup {
+			backup.LbEndpoints = append(backup.LbEndpoints, endpoint)
+		} else {
+			primary.LbEndpoints = append(primary.LbEndpoints, endpoint)
+		}
+	}
+
+	endpoints := []*envoy.LocalityLbEndpoints{primary}
+	if len(backup.LbEndpoints) > 0 {
+		endpoints = append(endpoints, backup)
+	}
+
+	cluster := &envoy.Cluster{
+		Name:           upstream.Name,
+		Type:           "STRICT_DNS",
+		ConnectTimeout: connectTimeout,
+		LbPolicy:       envoyLbPolicies[upstream.Balancing],
+		LoadAssignment: &envoy.ClusterLoadAssignment{ClusterName: upstream.Name, Endpoints: endpoints},
+	}
+
+	if upstream.Scheme == "https" {
+		cluster.TransportSocket = &envoy.TransportSocket{
+			Name: "envoy.transport_sockets.tls",
+			TypedConfig: &envoy.UpstreamTLSContext{
+				Type: "type.googleapis.com/envoy.extensions.transport_sockets.tls.v3.UpstreamTlsContext",
+				SNI:  upstream.Host,
+			},
+		}
+	}
+
+	return cluster, nil
+}
@@ -0,0 +1,366 @@
+package converter
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// ExampleGenerator synthesizes instances of schemas for documentation, mock responses and
+// contract tests. Values follow the type, format, enum and bounds of the schema and property
+// names hint at realistic strings. The same seed always produces the same values
+type ExampleGenerator struct {
+	// UseExamples returns the example or default of a schema instead of a generated value
+	UseExamples bool
+	// RequiredOnly leaves optional object properties out
+	RequiredOnly bool
+
+	schemas  map[string]*Schema
+	rand     *rand.Rand
+	visiting map[string]bool
+}
+
+var (
+	exampleFirstNames = []string{"Alice", "Bob", "Carol", "David", "Emma", "Frank", "Grace", "Henry"}
+	exampleLastNames  = []string{"Smith", "Johnson", "Brown", "Garcia", "Miller", "Davis", "Wilson", "Moore"}
+	exampleCities     = []string{"Oslo", "Berlin", "Lisbon", "Toronto", "Melbourne", "Nairobi", "Osaka", "Lima"}
+	exampleCountries  = []string{"Norway", "Germany", "Portugal", "Canada", "Australia", "Kenya", "Japan", "Peru"}
+	exampleWords      = []string{"alpha", "bravo", "delta", "echo", "harbor", "meadow", "summit", "willow"}
+	exampleEpoch      = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// NewExampleGenerator creates a generator resolving references against the component schemas
+func NewExampleGenerator(schemas map[string]*Schema, seed int64) *ExampleGenerator {
+	return &ExampleGenerator{
+		UseExamples: true,
+		schemas:     schemas,
+		rand:        rand.New(rand.NewSource(seed)),
+		visiting:    map[string]bool{},
+	}
+}
+
+// Generate returns an instance of a schema as a JSON compatible value
+func (g *ExampleGenerator) Generate(schema *Schema) interface{} {
+	return g.generate(schema, "")
+}
+
+// generate builds a value for a schema. name is the property the value is generated for, used to
+// pick realistic strings
+func (g *ExampleGenerator) generate(schema *Schema, name string) interface{} {
+	if schema == nil {
+		return nil
+	}
+
+	if schema.Ref != nil {
+		ref := strings.TrimPrefix(*schema.Ref, "#/components/schemas/")
+		// A schema that contains itself is left out the second time it is reached
+		if g.visiting[ref] {
+			return nil
+		}
+		g.visiting[ref] = true
+		defer delete(g.visiting, ref)
+
+		return g.generate(g.schemas[ref], name)
+	}
+
+	if g.UseExamples {
+		if schema.Example != nil {
+			return schema.Example
+		}
+		if schema.Default != nil {
+			return schema.Default
+		}
+	}
+
+	if len(schema.Enum) > 0 {
+		return schema.Enum[g.rand.Intn(len(schema.Enum))]
+	}
+
+	if len(schema.AllOf) > 0 {
+		return g.generateAllOf(schema, name)
+	}
+	if len(schema.OneOf) > 0 {
+		return g.generate(schema.OneOf[g.rand.Intn(len(schema.OneOf))], name)
+	}
+	if len(schema.AnyOf) > 0 {
+		return g.generate(schema.AnyOf[g.rand.Intn(len(schema.AnyOf))], name)
+	}
+
+	switch schemaType(schema) {
+	case "object":
+		return g.generateObject(schema)
+	case "array":
+		return g.generateArray(schema, name)
+	case "integer":
+		low, high := g.bounds(schema)
+		low, high = math.Ceil(low), math.Floor(high)
+		if high < low {
+			return int64(low)
+		}
+		return int64(low) + g.rand.Int63n(int64(high-low)+1)
+	case "number":
+		low, high := g.bounds(schema)
+		value := math.Round((low+g.rand.Float64()*(high-low))*100) / 100
+		return math.Max(low, math.Min(high, value))
+	case "boolean":
+		return g.rand.Intn(2) == 0
+	case "string":
+		return g.generateString(schema, name)
+	}
+
+	return nil
+}
+
+// generateAllOf merges the properties of every object in allOf. When a part is not an object,
+// the value of the last such part is used
+func (g *ExampleGenerator) generateAllOf(schema *Schema, name string) interface{} {
+	merged := map[string]interface{}{}
+	var scalar interface{}
+	for _, part := range schema.AllOf {
+		switch value := g.generate(part, name).(type) {
+		case map[string]interface{}:
+			for key, property := range value {
+				merged[key] = property
+			}
+		case nil:
+		default:
+			scalar = value
+		}
+	}
+
+	if len(merged) == 0 && scalar != nil {
+		return scalar
+	}
+
+	return merged
+}
+
+func (g *ExampleGenerator) generateObject(schema *Schema) interface{} {
+	required := map[string]bool{}
+	for _, name := range schema.Required {
+		if name != nil {
+			required[*name] = true
+		}
+	}
+
+	object := map[string]interface{}{}
+	// Properties are visited in a stable order so the same seed draws the same values
+	for _, name := range sortedKeys(schema.Properties) {
+		if g.RequiredOnly && !required[name] {
+			continue
+		}
+
+		value := g.generate(schema.Properties[name], name)
+		if value == nil && !required[name] {
+			continue
+		}
+		object[name] = value
+	}
+
+	return object
+}
+
+func (g *ExampleGenerator) generateArray(schema *Schema, name string) interface{} {
+	low, high := 1, 2
+	if schema.MinItems != nil {
+		low = *schema.MinItems
+		high = max(high, low)
+	}
+	if schema.MaxItems != nil {
+		high = *schema.MaxItems
+		low = min(low, high)
+	}
+
+	items := make([]interface{}, 0, high)
+	for count := low + g.rand.Intn(high-low+1); len(items) < count; {
+		item := g.generate(schema.Items, name)
+		if item == nil {
+			break
+		}
+		items = append(items, item)
+	}
+
+	return items
+}
+
+func (g *ExampleGenerator) generateString(schema *Schema, name string) string {
+	format := ""
+	if schema.Format != nil {
+		format = *schema.Format
+	}
+	identifier := name
+	name = strings.ToLower(name)
+
+	first := exampleFirstNames[g.rand.Intn(len(exampleFirstNames))]
+	last := exampleLastNames[g.rand.Intn(len(exampleLastNames))]
+	word := exampleWords[g.rand.Intn(len(exampleWords))]
+	moment := exampleEpoch.Add(time.Duration(g.rand.Intn(365*24*60)) * time.Minute)
+
+	var value string
+	switch {
+	case format == "email" || strings.Contains(name, "email"):
+		value = strings.ToLower(first+"."+last) + "@example.com"
+	case format == "uuid":
+		data := make([]byte, 16)
+		g.rand.Read(data)
+		data[6], data[8] = data[6]&0x0f|0x40, data[8]&0x3f|0x80
+		value = fmt.Sprintf("%x-%x-%x-%x-%x", data[0:4], data[4:6], data[6:8], data[8:10], data[10:])
+	case format == "date-time":
+		value = moment.Format(time.RFC3339)
+	case format == "date":
+		value = moment.Format(time.DateOnly)
+	case format == "time":
+		value = moment.Format(time.TimeOnly)
+	case format == "uri" || format == "url" || strings.HasSuffix(name, "url"):
+		value = "https://example.com/" + word
+	case format == "hostname":
+		value = word + ".example.com"
+	case format == "ipv4":
+		value = fmt.Sprintf("192.0.2.%d", 1+g.rand.Intn(254))
+	case format == "ipv6":
+		value = fmt.Sprintf("2001:db8::%x", 1+g.rand.Intn(0xfffe))
+	case format == "byte":
+		value = base64.StdEncoding.EncodeToString([]byte(word))
+	case format == "password":
+		value = "s3cr3t-" + word
+	case name == "id" || strings.HasSuffix(name, "_id") || strings.HasSuffix(identifier, "Id"):
+		value = fmt.Sprintf("%d", 1000+g.rand.Intn(9000))
+	case strings.Contains(name, "firstname") || strings.Contains(name, "first_name"):
+		value = first
+	case strings.Contains(name, "lastname") || strings.Contains(name, "last_name"):
+		value = last
+	case strings.Contains(name, "name"):
+		value = first + " " + last
+	case strings.Contains(name, "city"):
+		value = exampleCities[g.rand.Intn(len(exampleCities))]
+	case strings.Contains(name, "country"):
+		value = exampleCountries[g.rand.Intn(len(exampleCountries))]
+	case strings.Contains(name, "phone"):
+		value = fmt.Sprintf("+1-555-%04d", g.rand.Intn(10000))
+	default:
+		value = word
+	}
+
+	if schema.MinLength != nil {
+		for len(value) < *schema.MinLength {
+			value += "-" + exampleWords[g.rand.Intn(len(exampleWords))]
+		}
+	}
+	if schema.MaxLength != nil && len(value) > *schema.MaxLength {
+		value = value[:*schema.MaxLength]
+	}
+
+	return value
+}
+
+// bounds returns the range numbers are drawn from: the declared minimum and maximum, or a range
+// of 100 next to the declared one, or 1 to 100
+func (g *ExampleGenerator) bounds(schema *Schema) (float64, float64) {
+	switch {
+	case schema.Minimum != nil && schema.Maximum != nil:
+		return *schema.Minimum, *schema.Maximum
+	case schema.Minimum != nil:
+		return *schema.Minimum, *schema.Minimum + 100
+	case schema.Maximum != nil:
+		return *schema.Maximum - 100, *schema.Maximum
+	}
+
+	return 1, 100
+}
+
+// schemaType returns the declared type of a schema or the type its keywords imply
+func schemaType(schema *Schema) string {
+	switch {
+	case schema.Type != nil:
+		return *schema.Type
+	case len(schema.Properties) > 0:
+		return "object"
+	case schema.Items != nil:
+		return "array"
+	}
+
+	return ""
+}
+
+// WriteExamples generates an instance of the named component schemas, or of all of them when no
+// name is given, and renders them as indented JSON keyed by schema name
+func (n *OpenAPIConverter) WriteExamples(names []string, seed int64) (string, error) {
+	var schemas map[string]*Schema
+	if n.doc.Components != nil {
+		schemas = n.doc.Components.Schemas
+	}
+
+	if len(names) == 0 {
+		names = sortedKeys(schemas)
+	}
+
+	examples := map[string]interface{}{}
+	for _, name := range names {
+		if _, ok := schemas[name]; !ok {
+			return "", fmt.Errorf("file '%s': schema '%s' is not defined in components.schemas", n.filePath, name)
+		}
+
+		// Every schema gets its own generator so its example does not depend on the others. It is
+		// generated through a reference so properties referring back to it are left out
+		ref := "#/components/schemas/" + name
+		examples[name] = NewExampleGenerator(schemas, seed).Generate(&Schema{Ref: &ref})
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(examples); err != nil {
+		return "", fmt.Errorf("file '%s': failed to marshal examples: %w", n.filePath, err)
+	}
+
+	return buf.String(), nil
+}
+
+// FillExamples adds a generated example to every request body, response content and parameter
+// of the document that has none, neither directly nor on its schema
+func (n *OpenAPIConverter) FillExamples(seed int64) {
+	var schemas map[string]*Schema
+	if n.doc.Components != nil {
+		schemas = n.doc.Components.Schemas
+	}
+	generator := NewExampleGenerator(schemas, seed)
+
+	fillContent := func(content map[string]*ResponseContent) {
+		for _, contentType := range sortedKeys(content) {
+			media := content[contentType]
+			if media == nil || media.Schema == nil || media.Example != nil || len(media.Examples) > 0 {
+				continue
+			}
+			media.Example = generator.Generate(media.Schema)
+		}
+	}
+
+	for _, path := range n.sortedPaths() {
+		pathItem := n.doc.Paths[path]
+		parameters := pathItem.Parameters
+		for _, op := range pathItem.SortedOperations() {
+			parameters = append(parameters, op.Parameters...)
+			if op.RequestBody != nil {
+				fillContent(op.RequestBody.Content)
+			}
+			for _, status := range sortedKeys(op.Responses) {
+				if response := op.Responses[status]; response != nil {
+					fillContent(response.Content)
+				}
+			}
+		}
+
+		for _, parameter := range parameters {
+			if parameter == nil || parameter.Schema == nil || parameter.Example != nil {
+				continue
+			}
+			parameter.Example = generator.generate(parameter.Schema, parameter.Name)
+		}
+	}
+}
@@ -0,0 +1,329 @@
+package converter
+
+import (
+	"fmt"
+	"github.com/nimling/openapi-converter/utils"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// goClientIdentifiers are the identifiers of the client code that generated types must not take
+var goClientIdentifiers = []string{"APIError", "Client", "ClientOption", "DefaultServerURL", "NewClient", "RequestEditorFn", "WithHTTPClient", "WithRequestEditor"}
+
+// goOperation is an operation of the spec as a Go method
+type goOperation struct {
+	Name        string
+	Method      string
+	Path        string
+	Summary     string
+	Description string
+	// PathParams are passed as arguments, in the order of the path template
+	PathParams []*goParam
+	// Params names the struct holding the query, header and cookie parameters
+	Params      string
+	ParamFields []*goParam
+	// Body is the Go type of a JSON request body. BodyReader is set for other content types, which
+	// are passed as a reader
+	Body            string
+	BodyReader      bool
+	BodyContentType string
+	BodyRequired    bool
+	// Result is the Go type of the JSON body of the first successful response
+	Result string
+
+	op *RouteOperation
+}
+
+// goParam is a parameter of an operation as a method argument or a field of its params struct
+type goParam struct {
+	Name     string
+	Param    string
+	In       string
+	Type     string
+	Required bool
+	Doc      string
+	// Wildcard is the name of a path parameter in ServeMux patterns
+	Wildcard string
+}
+
+// goOperations converts the operations of the routes into Go methods, declaring the types of
+// their parameters and bodies
+func goOperations(table *RouteTable, types *goTypes) []*goOperation {
+	var operations []*goOperation
+	for _, route := range table.Routes {
+		for _, op := range route.Operations {
+			name := goName(op.OperationID)
+			if name == "" {
+				name = goName(strings.ToLower(op.Method) + " " + route.Path)
+			}
+
+			operation := &goOperation{
+				Name:        name,
+				Method:      op.Method,
+				Path:        route.Path,
+				Summary:     op.Summary,
+				Description: op.Description,
+				op:          op,
+			}
+
+			params := route.OperationParams(op)
+			for _, paramName := range pathParameterNames(route.Path) {
+				param := findRouteParam(params, paramName, "path")
+				schema := &Schema{Type: utils.StringPtr("string")}
+				if param != nil && param.Schema != nil {
+					schema = param.Schema
+				}
+
+				operation.PathParams = append(operation.PathParams, &goParam{
+					Name:     goLocalName(paramName, "ctx", "body", "params", "contentType"),
+					Param:    paramName,
+					In:       "path",
+					Type:     types.goType(schema, name+goName(paramName)),
+					Required: true,
+					Wildcard: goLocalName(paramName),
+				})
+			}
+
+			fields := map[string]bool{}
+			for _, param := range params {
+				if param.In == "path" {
+					continue
+				}
+
+				field := goName(param.Name)
+				if fields[field] {
+					field += goName(param.In)
+				}
+				fields[field] = true
+
+				operation.ParamFields = append(operation.ParamFields, &goParam{
+					Name:     field,
+					Param:    param.Name,
+					In:       param.In,
+					Type:     types.fieldType(param.Schema, name+field, param.Required),
+					Required: param.Required,
+					Doc:      schemaDoc(param.Schema),
+				})
+			}
+
+			if op.Body != nil {
+				operation.BodyRequired = op.Body.Required
+				operation.BodyContentType = op.Body.ContentTypes[0]
+				operation.BodyReader = true
+				for _, contentType := range op.Body.ContentTypes {
+					if isJSONMediaType(contentType) {
+						operation.BodyContentType = contentType
+						operation.BodyReader = false
+						operation.Body = types.goType(op.Body.Schemas[contentType], name+"Body")
+						if types.isStruct(operation.Body) {
+							operation.Body = "*" + operation.Body
+						}
+						break
+					}
+				}
+			}
+
+			operations = append(operations, operation)
+		}
+	}
+
+	return operations
+}
+
+// goResultType is the Go type of the JSON body of the first successful response declaring one
+func goResultType(op *RouteOperation, types *goTypes, name string) string {
+	for _, code := range sortedKeys(op.Responses) {
+		response := op.Responses[code]
+		if !strings.HasPrefix(code, "2") || response == nil {
+			continue
+		}
+
+		for _, contentType := range sortedKeys(response.Content) {
+			if !isJSONMediaType(contentType) || response.Content[contentType] == nil {
+				continue
+			}
+
+			result := types.goType(response.Content[contentType].Schema, name+"Result")
+			if types.isStruct(result) {
+				result = "*" + result
+			}
+			return result
+		}
+	}
+
+	return ""
+}
+
+// Signature lists the arguments of the client method after the context
+func (op *goOperation) Signature() string {
+	var args []string
+	for _, param := range op.PathParams {
+		args = append(args, param.Name+" "+param.Type)
+	}
+	switch {
+	case op.BodyReader:
+		args = append(args, "contentType string", "body io.Reader")
+	case op.Body != "":
+		args = append(args, "body "+op.Body)
+	}
+	if op.Params != "" {
+		args = append(args, "params *"+op.Params)
+	}
+
+	if len(args) == 0 {
+		return ""
+	}
+
+	return ", " + strings.Join(args, ", ")
+}
+
+// PathExpression is the Go expression building the request path from the path arguments
+func (op *goOperation) PathExpression() string {
+	var parts []string
+	last := 0
+	for i, match := range pathParameterPattern.FindAllStringIndex(op.Path, -1) {
+		if literal := op.Path[last:match[0]]; literal != "" {
+			parts = append(parts, strconv.Quote(literal))
+		}
+		parts = append(parts, "url.PathEscape(formatParam("+op.PathParams[i].Name+"))")
+		last = match[1]
+	}
+	if literal := op.Path[last:]; literal != "" || len(parts) == 0 {
+		parts = append(parts, strconv.Quote(literal))
+	}
+
+	return strings.Join(parts, " + ")
+}
+
+// HasQuery reports whether the operation has query parameters
+func (op *goOperation) HasQuery() bool {
+	for _, param := range op.ParamFields {
+		if param.In == "query" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HasHeader reports whether the operation has header or cookie parameters
+func (op *goOperation) HasHeader() bool {
+	for _, param := range op.ParamFields {
+		if param.In != "query" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ResultZero is the value the client method returns with an error
+func (op *goOperation) ResultZero() string {
+	if strings.HasPrefix(op.Result, "*") || strings.HasPrefix(op.Result, "[]") || strings.HasPrefix(op.Result, "map[") || op.Result == "json.RawMessage" || op.Result == "interface{}" {
+		return "nil"
+	}
+
+	return "result"
+}
+
+// ResultType is the type of the variable the response is decoded into
+func (op *goOperation) ResultType() string {
+	return strings.TrimPrefix(op.Result, "*")
+}
+
+// Call lists the arguments of the request helper
+func (op *goOperation) Call() string {
+	query, header, contentType, body, result := "nil", "nil", `""`, "nil", "nil"
+	if op.HasQuery() {
+		query = "query"
+	}
+	if op.HasHeader() {
+		header = "header"
+	}
+	switch {
+	case op.BodyReader:
+		contentType, body = "contentType", "body"
+	case op.Body != "":
+		contentType, body = strconv.Quote(op.BodyContentType), "body"
+	}
+	if op.Result != "" {
+		result = "&result"
+	}
+
+	return fmt.Sprintf("ctx, %s, path, %s, %s, %s, %s, %s", strconv.Quote(op.Method), query, header, contentType, body, result)
+}
+
+// Encode is the Go statement adding the parameter from the params struct to the request
+func (p *goParam) Encode() string {
+	value := "params." + p.Name
+	pointer := strings.HasPrefix(p.Type, "*")
+	slice := strings.HasPrefix(p.Type, "[]") && p.Type != "[]byte"
+
+	add := func(value string) string {
+		switch p.In {
+		case "query":
+			return fmt.Sprintf("query.Add(%s, formatParam(%s))", strconv.Quote(p.Param), value)
+		case "cookie":
+			return fmt.Sprintf("header.Add(\"Cookie\", (&http.Cookie{Name: %s, Value: formatParam(%s)}).String())", strconv.Quote(p.Param), value)
+		}
+		return fmt.Sprintf("header.Add(%s, formatParam(%s))", strconv.Quote(p.Param), value)
+	}
+
+	switch {
+	case slice:
+		return fmt.Sprintf("for _, value := range %s {\n%s\n}", value, add("value"))
+	case pointer:
+		return fmt.Sprintf("if %s != nil {\n%s\n}", value, add("*"+value))
+	}
+
+	return add(value)
+}
+
+// WriteGoClient renders a typed Go client of the spec: a type for every component schema, and a
+// method per operation taking the path parameters, the request body and a struct of the other
+// parameters and returning the decoded body of the successful response. The code is gofmt'd
+func (n *OpenAPIConverter) WriteGoClient(packageName string) (string, error) {
+	table, err := n.RouteTable()
+	if err != nil {
+		return "", err
+	}
+
+	if packageName == "" {
+		packageName = goPackageName(table.Spec)
+	}
+
+	types := newGoTypes(n.doc.Components, goClientIdentifiers...)
+	operations := goOperations(table, types)
+	for _, operation := range operations {
+		if len(operation.ParamFields) > 0 {
+			operation.Params = types.uniqueName(operation.Name + "Params")
+		}
+		operation.Result = goResultType(operation.op, types, operation.Name)
+	}
+
+	data := struct {
+		FilePath   string
+		Package    string
+		Title      string
+		Version    string
+		ServerURL  string
+		Types      []*goTypeDecl
+		Operations []*goOperation
+	}{
+		FilePath:   filepath.ToSlash(table.FilePath),
+		Package:    packageName,
+		Title:      n.doc.Info.Title,
+		Version:    n.doc.Info.Version,
+		ServerURL:  strconv.Quote(table.ServerURL),
+		Types:      types.sortedDecls(),
+		Operations: operations,
+	}
+
+	source, err := utils.ExecuteTemplate(utils.FormatRaw, "go-client", goClientTemplate+goTypesTemplate, data)
+	if err != nil {
+		return "", err
+	}
+
+	return formatGoSource(table.FilePath, source)
+}
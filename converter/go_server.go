@@ -0,0 +1,218 @@
+package converter
+
+import (
+	"fmt"
+	"github.com/nimling/openapi-converter/utils"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// goServerIdentifiers are the identifiers of the server code that generated types must not take
+var goServerIdentifiers = []string{"BasePath", "Handler", "HandlerOptions", "RegisterHandlers", "RequestError", "Server"}
+
+// goServerOperation is an operation of the spec as a method of the server interface
+type goServerOperation struct {
+	*goOperation
+	Request  string
+	Response string
+	Handler  string
+	// Pattern is the path of the ServeMux pattern, with the path parameters as wildcards
+	Pattern string
+	// Fields are the path parameters followed by the other parameters of the request struct
+	Fields    []*goParam
+	Responses []*goResponse
+}
+
+// goResponse is a response an operation declares, as a type of the server code
+type goResponse struct {
+	Name        string
+	Code        string
+	Description string
+	// Status is the status code, empty for ranges such as 4XX and default responses whose status
+	// is set by the implementation
+	Status      string
+	Body        string
+	ContentType string
+}
+
+// ServerBody is the type of the body field of the request struct. Optional bodies are pointers
+func (op *goServerOperation) ServerBody() string {
+	if op.BodyReader {
+		return "io.Reader"
+	}
+	if op.BodyRequired {
+		return strings.TrimPrefix(op.Body, "*")
+	}
+
+	return op.Body
+}
+
+// ResponseNames lists the response types of the operation for the doc comment of its interface
+func (op *goServerOperation) ResponseNames() string {
+	names := make([]string, 0, len(op.Responses))
+	for _, response := range op.Responses {
+		names = append(names, response.Name)
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// goServerOperations adds the request, response and routing details the server code needs to
+// the operations
+func goServerOperations(table *RouteTable, types *goTypes) ([]*goServerOperation, error) {
+	var operations []*goServerOperation
+	for _, operation := range goOperations(table, types) {
+		pattern, err := serveMuxPattern(operation)
+		if err != nil {
+			return nil, fmt.Errorf("file '%s': %w", table.FilePath, err)
+		}
+
+		server := &goServerOperation{
+			goOperation: operation,
+			Request:     types.uniqueName(operation.Name + "Request"),
+			Response:    types.uniqueName(operation.Name + "Response"),
+			Handler:     "handle" + operation.Name,
+			Pattern:     strconv.Quote(pattern),
+		}
+
+		fields := map[string]bool{"Body": operation.Body != "" || operation.BodyReader}
+		for _, param := range append(append([]*goParam{}, operation.PathParams...), operation.ParamFields...) {
+			field := *param
+			field.Name = goName(param.Param)
+			if fields[field.Name] {
+				field.Name += goName(param.In)
+			}
+			for i, name := 2, field.Name; fields[field.Name]; i++ {
+				field.Name = name + strconv.Itoa(i)
+			}
+			fields[field.Name] = true
+			server.Fields = append(server.Fields, &field)
+		}
+
+		for _, code := range sortedKeys(operation.op.Responses) {
+			server.Responses = append(server.Responses, goServerResponse(types, server, code, operation.op.Responses[code]))
+		}
+		if len(server.Responses) == 0 {
+			return nil, fmt.Errorf("file '%s': operation '%s' declares no response", table.FilePath, operation.op.OperationID)
+		}
+
+		operations = append(operations, server)
+	}
+
+	return operations, nil
+}
+
+func goServerResponse(types *goTypes, op *goServerOperation, code string, response *Response) *goResponse {
+	suffix := strings.ToUpper(code)
+	if code == "default" {
+		suffix = "Default"
+	}
+
+	result := &goResponse{
+		Name:        types.uniqueName(op.Name + suffix + "Response"),
+		Code:        code,
+		ContentType: `""`,
+	}
+	if _, err := strconv.Atoi(code); err == nil {
+		result.Status = code
+	}
+	if response == nil {
+		return result
+	}
+	if response.Description != nil {
+		result.Description = *response.Description
+	}
+
+	// A JSON content type gets a typed body; otherwise the body is streamed from a reader
+	contentTypes := sortedKeys(response.Content)
+	for _, contentType := range contentTypes {
+		if isJSONMediaType(contentType) {
+			var schema *Schema
+			if content := response.Content[contentType]; content != nil {
+				schema = content.Schema
+			}
+			result.Body = types.goType(schema, strings.TrimSuffix(result.Name, "Response")+"Body")
+			result.ContentType = strconv.Quote(contentType)
+			return result
+		}
+	}
+	if len(contentTypes) > 0 {
+		result.Body = "io.Reader"
+		result.ContentType = strconv.Quote(contentTypes[0])
+	}
+
+	return result
+}
+
+// serveMuxPattern converts the path template of an operation into the path of a Go 1.22 ServeMux
+// pattern. Wildcards have to be whole segments named with Go identifiers, and a trailing slash
+// would match the whole subtree unless it is anchored with {$}
+func serveMuxPattern(op *goOperation) (string, error) {
+	segments := strings.Split(op.Path, "/")
+	param := 0
+	for i, segment := range segments {
+		matches := pathParameterPattern.FindAllStringIndex(segment, -1)
+		if len(matches) == 0 {
+			continue
+		}
+		if len(matches) > 1 || matches[0][0] != 0 || matches[0][1] != len(segment) {
+			return "", fmt.Errorf("path '%s' has a parameter that is not a whole segment, which ServeMux patterns cannot match", op.Path)
+		}
+
+		segments[i] = "{" + op.PathParams[param].Wildcard + "}"
+		param++
+	}
+
+	pattern := strings.Join(segments, "/")
+	if strings.HasSuffix(pattern, "/") {
+		pattern += "{$}"
+	}
+
+	return pattern, nil
+}
+
+// WriteGoServer renders Go server code of the spec: a Server interface with a method per
+// operation, request and response types per operation and RegisterHandlers, which wires a Server
+// onto a net/http ServeMux with Go 1.22 method and path patterns. The code is gofmt'd
+func (n *OpenAPIConverter) WriteGoServer(packageName string) (string, error) {
+	table, err := n.RouteTable()
+	if err != nil {
+		return "", err
+	}
+
+	if packageName == "" {
+		packageName = goPackageName(table.Spec)
+	}
+
+	types := newGoTypes(n.doc.Components, goServerIdentifiers...)
+	operations, err := goServerOperations(table, types)
+	if err != nil {
+		return "", err
+	}
+
+	data := struct {
+		FilePath   string
+		Package    string
+		Title      string
+		Version    string
+		BasePath   string
+		Types      []*goTypeDecl
+		Operations []*goServerOperation
+	}{
+		FilePath:   filepath.ToSlash(table.FilePath),
+		Package:    packageName,
+		Title:      n.doc.Info.Title,
+		Version:    n.doc.Info.Version,
+		BasePath:   strconv.Quote(strings.TrimSuffix(table.Upstream.BasePath, "/")),
+		Types:      types.sortedDecls(),
+		Operations: operations,
+	}
+
+	source, err := utils.ExecuteTemplate(utils.FormatRaw, "go-server", goServerTemplate+goTypesTemplate, data)
+	if err != nil {
+		return "", err
+	}
+
+	return formatGoSource(table.FilePath, source)
+}
@@ -0,0 +1,458 @@
+package converter
+
+import (
+	"fmt"
+	"go/format"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// goInitialisms are the words Go spells in capitals within identifiers
+var goInitialisms = map[string]bool{
+	"API": true, "CPU": true, "CSS": true, "DNS": true, "HTML": true, "HTTP": true, "HTTPS": true,
+	"ID": true, "IP": true, "JSON": true, "JWT": true, "SQL": true, "TLS": true, "TTL": true,
+	"UI": true, "URI": true, "URL": true, "UUID": true, "XML": true,
+}
+
+// goTypeDecl is a named type of the generated code: a struct, an enum with its constants, or a
+// type defined by (or aliasing) another type
+type goTypeDecl struct {
+	Name   string
+	Source string
+	Doc    string
+	Type   string
+	Alias  bool
+	Fields []*goField
+	Values []*goEnumValue
+}
+
+// goField is a field of a generated struct
+type goField struct {
+	Name string
+	Type string
+	Tag  string
+	Doc  string
+}
+
+// goEnumValue is a constant of a generated enum type, with its value as a Go literal
+type goEnumValue struct {
+	Name  string
+	Value string
+}
+
+// goTypes converts schemas into Go types. Component schemas become named types; inline objects
+// and enums are declared as named types too, named after the property holding them
+type goTypes struct {
+	components *Components
+	decls      []*goTypeDecl
+	names      map[string]bool
+	structs    map[string]bool
+	schemas    map[string]string
+}
+
+// newGoTypes declares a type for every component schema. reserved lists the identifiers of the
+// generated code that component types must not take
+func newGoTypes(components *Components, reserved ...string) *goTypes {
+	if components == nil {
+		components = &Components{}
+	}
+
+	g := &goTypes{
+		components: components,
+		names:      map[string]bool{},
+		structs:    map[string]bool{},
+		schemas:    map[string]string{},
+	}
+	for _, name := range reserved {
+		g.names[name] = true
+	}
+
+	// Names are assigned before any schema is converted so references resolve to them
+	for _, name := range sortedKeys(components.Schemas) {
+		g.schemas[name] = g.uniqueName(goName(name))
+	}
+	for _, name := range sortedKeys(components.Schemas) {
+		g.declare(g.schemas[name], "the "+name+" schema", components.Schemas[name])
+	}
+
+	return g
+}
+
+// uniqueName returns name, numbered when another type already uses it, and marks it as used
+func (g *goTypes) uniqueName(name string) string {
+	unique := name
+	for i := 2; g.names[unique]; i++ {
+		unique = name + strconv.Itoa(i)
+	}
+	g.names[unique] = true
+
+	return unique
+}
+
+// declare adds the named type of a schema to the declarations. source tells where the schema is
+// defined in the spec
+func (g *goTypes) declare(name string, source string, schema *Schema) {
+	decl := &goTypeDecl{Name: name, Source: source, Doc: schemaDoc(schema)}
+	g.decls = append(g.decls, decl)
+
+	if schema == nil {
+		decl.Type = "interface{}"
+		return
+	}
+
+	if schema.Ref != nil || len(schema.OneOf) > 0 || len(schema.AnyOf) > 0 {
+		// Aliases keep the JSON methods of json.RawMessage and of the referenced type
+		decl.Type = g.goType(schema, name)
+		decl.Alias = true
+		if len(schema.OneOf)+len(schema.AnyOf) > 0 {
+			decl.Doc = joinDoc(decl.Doc, g.alternativesDoc(schema))
+		}
+		return
+	}
+
+	if len(schema.AllOf) > 0 {
+		merged, err := mergeAllOf(schema.AllOf, g.components)
+		if err == nil && merged != nil {
+			if merged.Description == nil {
+				merged.Description = schema.Description
+			}
+			g.declareStruct(decl, merged)
+			return
+		}
+	}
+
+	if len(schema.Enum) > 0 && (schemaType(schema) == "string" || schemaType(schema) == "integer") {
+		decl.Type = g.scalarType(schema)
+		for i, value := range schema.Enum {
+			if value == nil {
+				continue
+			}
+
+			// Values without letters or digits are numbered; numeric values need no N prefix
+			// after the type name
+			literal := fmt.Sprint(value)
+			constant := name + goName(literal)
+			if words := identifierWords(literal); len(words) == 0 {
+				constant = name + strconv.Itoa(i+1)
+			} else if unicode.IsDigit([]rune(words[0])[0]) {
+				constant = name + strings.TrimPrefix(goName(literal), "N")
+			}
+			if decl.Type == "string" {
+				literal = strconv.Quote(literal)
+			}
+			decl.Values = append(decl.Values, &goEnumValue{Name: g.uniqueName(constant), Value: literal})
+		}
+		return
+	}
+
+	if schemaType(schema) == "object" && len(schema.Properties) > 0 {
+		g.declareStruct(decl, schema)
+		return
+	}
+
+	// Defined types lose the JSON methods of time.Time, so those are aliased as well
+	decl.Type = g.goType(schema, name)
+	decl.Alias = decl.Type == "time.Time" || decl.Type == "json.RawMessage" || decl.Type == "interface{}"
+}
+
+func (g *goTypes) declareStruct(decl *goTypeDecl, schema *Schema) {
+	decl.Type = "struct"
+	g.structs[decl.Name] = true
+
+	required := map[string]bool{}
+	for _, name := range schema.Required {
+		if name != nil {
+			required[*name] = true
+		}
+	}
+
+	fields := map[string]bool{}
+	for _, property := range sortedKeys(schema.Properties) {
+		propertySchema := schema.Properties[property]
+
+		name := goName(property)
+		if name == "" {
+			name = "Field"
+		}
+		for i := 2; fields[name]; i++ {
+			name = goName(property) + strconv.Itoa(i)
+		}
+		fields[name] = true
+
+		tag := property
+		if !required[property] {
+			tag += ",omitempty"
+		}
+
+		decl.Fields = append(decl.Fields, &goField{
+			Name: name,
+			Type: g.fieldType(propertySchema, decl.Name+name, required[property]),
+			Tag:  fmt.Sprintf("`json:%s`", strconv.Quote(tag)),
+			Doc:  joinDoc(schemaDoc(propertySchema), g.alternativesDoc(propertySchema)),
+		})
+	}
+}
+
+// fieldType is the type of a struct field or parameter. Optional and nullable values are pointers
+// unless their type already has a nil value
+func (g *goTypes) fieldType(schema *Schema, hint string, required bool) string {
+	goType := g.goType(schema, hint)
+	if required && !g.nullable(schema) {
+		return goType
+	}
+
+	if strings.HasPrefix(goType, "[]") || strings.HasPrefix(goType, "map[") || goType == "interface{}" || goType == "json.RawMessage" {
+		return goType
+	}
+
+	return "*" + goType
+}
+
+// nullable reports whether a schema, or the schema it refers to, allows null
+func (g *goTypes) nullable(schema *Schema) bool {
+	for depth := 0; schema != nil && depth < maxRefDepth; depth++ {
+		if schema.Nullable != nil && *schema.Nullable {
+			return true
+		}
+		if schema.Ref == nil {
+			return false
+		}
+		schema = g.components.GetSchema(*schema.Ref)
+	}
+
+	return false
+}
+
+// goType returns the Go type of a schema. hint names the types declared for inline objects and enums
+func (g *goTypes) goType(schema *Schema, hint string) string {
+	if schema == nil {
+		return "interface{}"
+	}
+
+	if schema.Ref != nil {
+		if name, ok := g.schemas[strings.TrimPrefix(*schema.Ref, "#/components/schemas/")]; ok {
+			return name
+		}
+		return "interface{}"
+	}
+
+	if len(schema.OneOf) > 0 || len(schema.AnyOf) > 0 {
+		return "json.RawMessage"
+	}
+
+	if len(schema.AllOf) > 0 || (schemaType(schema) == "object" && len(schema.Properties) > 0) || (len(schema.Enum) > 0 && (schemaType(schema) == "string" || schemaType(schema) == "integer")) {
+		name := g.uniqueName(hint)
+		g.declare(name, "an inline schema", schema)
+		return name
+	}
+
+	switch schemaType(schema) {
+	case "object":
+		return "map[string]interface{}"
+	case "array":
+		return "[]" + g.goType(schema.Items, hint+"Item")
+	case "string", "integer", "number", "boolean":
+		return g.scalarType(schema)
+	}
+
+	return "interface{}"
+}
+
+// scalarType maps a primitive schema and its format to a Go type
+func (g *goTypes) scalarType(schema *Schema) string {
+	format := ""
+	if schema.Format != nil {
+		format = *schema.Format
+	}
+
+	switch schemaType(schema) {
+	case "integer":
+		if format == "int32" {
+			return "int32"
+		}
+		return "int64"
+	case "number":
+		if format == "float" {
+			return "float32"
+		}
+		return "float64"
+	case "boolean":
+		return "bool"
+	}
+
+	switch format {
+	case "date-time":
+		return "time.Time"
+	case "byte":
+		return "[]byte"
+	}
+
+	return "string"
+}
+
+// isStruct reports whether a Go type is a generated struct, which is passed by pointer
+func (g *goTypes) isStruct(goType string) bool {
+	return g.structs[goType]
+}
+
+// sortedDecls returns the declarations ordered by name
+func (g *goTypes) sortedDecls() []*goTypeDecl {
+	decls := append([]*goTypeDecl{}, g.decls...)
+	sort.Slice(decls, func(i, j int) bool {
+		return decls[i].Name < decls[j].Name
+	})
+
+	return decls
+}
+
+// alternativesDoc names the schemas a oneOf or anyOf value holds, which Go cannot express as a type
+func (g *goTypes) alternativesDoc(schema *Schema) string {
+	if schema == nil {
+		return ""
+	}
+
+	keyword, alternatives := "one of", schema.OneOf
+	if len(alternatives) == 0 {
+		keyword, alternatives = "any of", schema.AnyOf
+	}
+	if len(alternatives) == 0 {
+		return ""
+	}
+
+	names := make([]string, 0, len(alternatives))
+	for i, alternative := range alternatives {
+		if alternative != nil && alternative.Ref != nil {
+			names = append(names, g.goType(alternative, ""))
+		} else {
+			names = append(names, fmt.Sprintf("inline schema %d", i+1))
+		}
+	}
+
+	return fmt.Sprintf("The raw JSON holds %s %s", keyword, strings.Join(names, ", "))
+}
+
+// schemaDoc is the description of a schema as a single line
+func schemaDoc(schema *Schema) string {
+	if schema == nil || schema.Description == nil {
+		return ""
+	}
+
+	return strings.Join(strings.Fields(*schema.Description), " ")
+}
+
+func joinDoc(docs ...string) string {
+	var parts []string
+	for _, doc := range docs {
+		if doc != "" {
+			parts = append(parts, strings.TrimSuffix(doc, "."))
+		}
+	}
+
+	return strings.Join(parts, ". ")
+}
+
+// goName converts a name of the spec into an exported Go identifier: words are capitalized and
+// joined, initialisms such as ID and URL are spelled in capitals
+func goName(name string) string {
+	var builder strings.Builder
+	for _, word := range identifierWords(name) {
+		if goInitialisms[strings.ToUpper(word)] {
+			builder.WriteString(strings.ToUpper(word))
+			continue
+		}
+
+		runes := []rune(word)
+		builder.WriteString(string(unicode.ToUpper(runes[0])) + string(runes[1:]))
+	}
+
+	identifier := builder.String()
+	if identifier != "" && unicode.IsDigit([]rune(identifier)[0]) {
+		identifier = "N" + identifier
+	}
+
+	return identifier
+}
+
+// goLocalName converts a name of the spec into an unexported Go identifier that is neither a
+// keyword nor one of the taken names of the generated function
+func goLocalName(name string, taken ...string) string {
+	words := identifierWords(name)
+	if len(words) == 0 {
+		return "value"
+	}
+
+	first := strings.ToLower(words[0])
+	identifier := first + strings.TrimPrefix(goName(name), goName(words[0]))
+	if unicode.IsDigit([]rune(identifier)[0]) {
+		identifier = "n" + identifier
+	}
+
+	if token.IsKeyword(identifier) || containsString(taken, identifier) {
+		identifier += "Param"
+	}
+
+	return identifier
+}
+
+// identifierWords splits a name at separators and at changes of case, keeping runs of capitals
+// such as HTTP in HTTPServer together
+func identifierWords(name string) []string {
+	var words []string
+	var word []rune
+
+	runes := []rune(name)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if len(word) > 0 {
+				words = append(words, string(word))
+				word = nil
+			}
+			continue
+		}
+
+		if len(word) > 0 && unicode.IsUpper(r) {
+			previous := word[len(word)-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(previous) || unicode.IsDigit(previous) || (unicode.IsUpper(previous) && nextLower) {
+				words = append(words, string(word))
+				word = nil
+			}
+		}
+		word = append(word, r)
+	}
+	if len(word) > 0 {
+		words = append(words, string(word))
+	}
+
+	return words
+}
+
+// goPackageName converts a name into a Go package name: lower case letters and digits
+func goPackageName(name string) string {
+	var builder strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9' && builder.Len() > 0) {
+			builder.WriteRune(r)
+		}
+	}
+
+	if builder.Len() == 0 || token.IsKeyword(builder.String()) {
+		return "api"
+	}
+
+	return builder.String()
+}
+
+// formatGoSource gofmts generated code, reporting the spec the code was generated from when the
+// generated code does not parse
+func formatGoSource(filePath string, source string) (string, error) {
+	formatted, err := format.Source([]byte(source))
+	if err != nil {
+		return "", fmt.Errorf("file '%s': generated Go code is invalid: %w", filePath, err)
+	}
+
+	return string(formatted), nil
+}
@@ -0,0 +1,142 @@
+package converter
+
+import (
+	"fmt"
+	"github.com/nimling/openapi-converter/utils"
+	"strings"
+)
+
+var haproxyBalancing = map[string]string{
+	"":            "",
+	"round_robin": "roundrobin",
+	"least_conn":  "leastconn",
+	"ip_hash":     "source",
+	"random":      "random",
+}
+
+type haproxyRoute struct {
+	Name    string
+	Path    string
+	Regex   string
+	Methods string
+}
+
+type haproxyBackend struct {
+	Name           string
+	Balance        string
+	ConnectTimeout string
+	ServerTimeout  string
+	Rewrite        string
+	Headers        []nginxHeader
+	Servers        []haproxyServer
+}
+
+type haproxyServer struct {
+	Name       string
+	Address    string
+	Parameters string
+}
+
+// WriteHAProxyConfiguration renders the spec as an HAProxy frontend and one backend per path, the
+// counterpart of the Nginx locations. The frontend selects a backend with path_reg and method ACLs,
+// ordered like Nginx picks locations since HAProxy uses the first matching use_backend rule.
+// The frontend binds to the address in the GATEWAY_BIND environment variable
+func (n *OpenAPIConverter) WriteHAProxyConfiguration() (string, error) {
+	table, err := n.RouteTable()
+	if err != nil {
+		return "", err
+	}
+
+	return renderHAProxy(table)
+}
+
+func renderHAProxy(table *RouteTable) (string, error) {
+	upstream := table.Upstream
+
+	var servers []haproxyServer
+	for i, server := range upstream.Servers {
+		var parameters []string
+		if server.Weight > 0 {
+			parameters = append(parameters, fmt.Sprintf("weight %d", server.Weight))
+		}
+		if server.Backup {
+			parameters = append(parameters, "backup")
+		}
+		if server.Scheme == "https" {
+			// Like proxy_pass, the upstream certificate is not verified
+			parameters = append(parameters, "ssl verify none sni str("+upstream.Host+")")
+		}
+
+		servers = append(servers, haproxyServer{
+			Name:       fmt.Sprintf("%s_%d", upstream.Name, i+1),
+			Address:    server.Address(),
+			Parameters: strings.Join(parameters, " "),
+		})
+	}
+
+	rewrite := ""
+	if pattern, replacement, ok := table.PathRewrite(); ok {
+		rewrite = haproxyEscape(pattern) + " " + haproxyEscape(replacement)
+	}
+
+	var routes []haproxyRoute
+	var backends []haproxyBackend
+	for _, route := range table.OrderedRoutes() {
+		backend := haproxyBackend{
+			Name:    route.Name,
+			Balance: haproxyBalancing[upstream.Balancing],
+			Rewrite: rewrite,
+			Servers: servers,
+		}
+
+		timeouts := []struct {
+			dst   *string
+			value string
+		}{
+			{&backend.ConnectTimeout, route.Settings.ConnectTimeout},
+			{&backend.ServerTimeout, route.Settings.ReadTimeout},
+		}
+		for _, timeout := range timeouts {
+			duration, err := parseNginxDuration(timeout.value)
+			if err != nil {
+				return "", fmt.Errorf("file '%s': path '%s': %w", table.FilePath, route.Path, err)
+			}
+			// HAProxy reads values without a unit as milliseconds
+			*timeout.dst = fmt.Sprintf("%dms", duration.Milliseconds())
+		}
+
+		for _, header := range route.Settings.ResponseHeaders() {
+			// Header values are log-format strings, where % starts a variable
+			value := utils.QuoteConfigValue(strings.ReplaceAll(header.Value, "%", "%%"))
+			backend.Headers = append(backend.Headers, nginxHeader{Name: header.Name, Value: value})
+		}
+
+		routes = append(routes, haproxyRoute{
+			Name:    route.Name,
+			Path:    route.Path,
+			Regex:   haproxyEscape(route.Match.Regex),
+			Methods: strings.Join(route.Methods, " "),
+		})
+		backends = append(backends, backend)
+	}
+
+	data := struct {
+		FilePath string
+		Name     string
+		Routes   []haproxyRoute
+		Backends []haproxyBackend
+	}{
+		FilePath: table.FilePath,
+		Name:     table.Spec,
+		Routes:   routes,
+		Backends: backends,
+	}
+
+	return utils.ExecuteTemplate(utils.FormatRaw, "haproxy", haproxyTemplate, data)
+}
+
+// haproxyEscape escapes the characters HAProxy would otherwise treat as argument separators,
+// comments or quotes
+func haproxyEscape(value string) string {
+	return strings.NewReplacer(`\`, `\\`, " ", `\ `, "#", `\#`, `"`, `\"`, `'`, `\'`).Replace(value)
+}
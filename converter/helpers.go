@@ -1,7 +1,10 @@
 package converter
 
 import (
+	"github.com/nimling/openapi-converter/utils"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
 )
 
@@ -35,6 +38,23 @@ func (p *PathItem) Operations() OperationRecord {
 	return operations
 }
 
+// SortedOperations returns the defined operations ordered by method name
+func (p *PathItem) SortedOperations() []*Operation {
+	operations := p.Operations()
+	methods := make([]string, 0, len(operations))
+	for method := range operations {
+		methods = append(methods, string(method))
+	}
+	sort.Strings(methods)
+
+	result := make([]*Operation, 0, len(methods))
+	for _, method := range methods {
+		result = append(result, operations[OperationMethod(method)])
+	}
+
+	return result
+}
+
 func (p *PathItem) SetMethodOperation(method OperationMethod, operation *Operation) {
 
 	switch method {
@@ -81,3 +101,99 @@ func (c *Components) GetRegister(compType string, compName string) *Component {
 	}
 	return nil
 }
+
+// sortedPaths returns the document paths in a stable order so generated output is reproducible
+func (n *OpenAPIConverter) sortedPaths() []string {
+	paths := make([]string, 0, len(n.doc.Paths))
+	for path := range n.doc.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	return paths
+}
+
+var pathParameterPattern = regexp.MustCompile(`\{[^{}/]+\}`)
+
+// isTemplatedPath reports whether an OpenAPI path contains {parameters}
+func isTemplatedPath(path string) bool {
+	return pathParameterPattern.MatchString(path)
+}
+
+// pathTemplateRegex converts an OpenAPI path template such as /users/{id} into an anchored
+// regex matching one path segment per parameter
+func pathTemplateRegex(path string) string {
+	var builder strings.Builder
+	builder.WriteString("^")
+
+	last := 0
+	for _, match := range pathParameterPattern.FindAllStringIndex(path, -1) {
+		builder.WriteString(regexp.QuoteMeta(path[last:match[0]]))
+		builder.WriteString("[^/]+")
+		last = match[1]
+	}
+	builder.WriteString(regexp.QuoteMeta(path[last:]))
+	builder.WriteString("$")
+
+	return builder.String()
+}
+
+// nginxLocationMatch returns the location arguments for a path: an exact match for literal
+// paths and a regex match for templated ones, since braces cannot appear in a bare location.
+// Nginx prefers regex locations over prefix locations, so a literal path has to be an exact
+// match to win over a templated sibling such as /users/{id} for /users/me
+func nginxLocationMatch(path string) string {
+	if !isTemplatedPath(path) {
+		return "= " + path
+	}
+
+	return "~ " + nginxRegex(pathTemplateRegex(path))
+}
+
+// nginxRegex quotes a regex argument when it contains characters Nginx would otherwise read as
+// block or statement delimiters
+func nginxRegex(pattern string) string {
+	if strings.ContainsAny(pattern, "{};\" \t") {
+		return utils.QuoteConfigValue(pattern)
+	}
+
+	return pattern
+}
+
+// indentLines prefixes every non-empty line of text with indent
+func indentLines(text string, indent string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = indent + line
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// sortedKeys returns the keys of a map in sorted order
+func sortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// example returns the example of a media type or, when it has none, its first named example
+func (c *ResponseContent) example() interface{} {
+	if c.Example != nil {
+		return c.Example
+	}
+
+	for _, name := range sortedKeys(c.Examples) {
+		if example := c.Examples[name]; example != nil && example.Value != nil {
+			return example.Value
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,152 @@
+package converter
+
+import (
+	"fmt"
+	"github.com/nimling/openapi-converter/utils"
+	"reflect"
+	"sort"
+)
+
+// HTTPContext collects the http-level Nginx definitions that locations of several specs refer
+// to, such as limit_req_zone. Share one instance between the converters of a run and render it
+// once into a file included in the http block
+type HTTPContext struct {
+	rateLimitZones map[string]*rateLimitZone
+	maps           map[string]*nginxMap
+	imports        map[string]*njsImport
+}
+
+// njsImport is a js_import of an njs module under Name
+type njsImport struct {
+	Name   string
+	Path   string
+	Source string
+}
+
+// nginxMap translates Input into Variable. Include names a file with further entries, which lets
+// deployments keep secrets such as API keys out of the generated configuration
+type nginxMap struct {
+	Source   string
+	Input    string
+	Variable string
+	Default  string
+	Include  string
+	Entries  []nginxMapEntry
+}
+
+type nginxMapEntry struct {
+	Key   string
+	Value string
+}
+
+// rateLimitZone is a limit_req_zone. Spec is the file the zone was defined in; the paths of
+// that spec may share the zone, other specs must use their own
+type rateLimitZone struct {
+	Name   string
+	Key    string
+	Size   string
+	Rate   string
+	Spec   string
+	Source string
+}
+
+func NewHTTPContext() *HTTPContext {
+	return &HTTPContext{
+		rateLimitZones: map[string]*rateLimitZone{},
+		maps:           map[string]*nginxMap{},
+		imports:        map[string]*njsImport{},
+	}
+}
+
+// IsEmpty reports whether nothing was registered
+func (h *HTTPContext) IsEmpty() bool {
+	return len(h.rateLimitZones) == 0 && len(h.maps) == 0 && len(h.imports) == 0
+}
+
+// Render writes the collected definitions in a stable order
+func (h *HTTPContext) Render() (string, error) {
+	data := struct {
+		Imports        []*njsImport
+		RateLimitZones []*rateLimitZone
+		Maps           []*nginxMap
+	}{}
+
+	for _, name := range sortedKeys(h.imports) {
+		data.Imports = append(data.Imports, h.imports[name])
+	}
+
+	names := make([]string, 0, len(h.rateLimitZones))
+	for name := range h.rateLimitZones {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		data.RateLimitZones = append(data.RateLimitZones, h.rateLimitZones[name])
+	}
+
+	variables := make([]string, 0, len(h.maps))
+	for variable := range h.maps {
+		variables = append(variables, variable)
+	}
+	sort.Strings(variables)
+
+	for _, variable := range variables {
+		data.Maps = append(data.Maps, h.maps[variable])
+	}
+
+	return utils.ExecuteTemplate(utils.FormatRaw, "http", httpContextTemplate, data)
+}
+
+func (h *HTTPContext) addRateLimitZone(zone *rateLimitZone) error {
+	existing, ok := h.rateLimitZones[zone.Name]
+	if !ok {
+		h.rateLimitZones[zone.Name] = zone
+		return nil
+	}
+
+	if existing.Spec != zone.Spec || existing.Key != zone.Key || existing.Size != zone.Size || existing.Rate != zone.Rate {
+		return fmt.Errorf("rate limit zone '%s' from %s collides with the zone of the same name from %s, use a distinct zone name",
+			zone.Name, zone.Source, existing.Source)
+	}
+
+	return nil
+}
+
+func (h *HTTPContext) addMap(m *nginxMap) error {
+	existing, ok := h.maps[m.Variable]
+	if !ok {
+		h.maps[m.Variable] = m
+		return nil
+	}
+
+	if existing.Source != m.Source || existing.Input != m.Input || existing.Default != m.Default ||
+		existing.Include != m.Include || !reflect.DeepEqual(existing.Entries, m.Entries) {
+		return fmt.Errorf("map variable '%s' from %s collides with the map of the same name from %s",
+			m.Variable, m.Source, existing.Source)
+	}
+
+	return nil
+}
+
+func (h *HTTPContext) addImport(i *njsImport) error {
+	existing, ok := h.imports[i.Name]
+	if !ok {
+		h.imports[i.Name] = i
+		return nil
+	}
+
+	if existing.Path != i.Path {
+		return fmt.Errorf("njs module '%s' from %s collides with the module of the same name from %s", i.Name, i.Source, existing.Source)
+	}
+
+	return nil
+}
+
+func (n *OpenAPIConverter) httpContext() *HTTPContext {
+	if n.HTTPContext == nil {
+		n.HTTPContext = NewHTTPContext()
+	}
+
+	return n.HTTPContext
+}
@@ -0,0 +1,209 @@
+package converter
+
+import (
+	"fmt"
+	"github.com/nimling/openapi-converter/utils"
+	"net/url"
+	"strings"
+)
+
+// httpFileRequest is a request of a .http file
+type httpFileRequest struct {
+	Name    string
+	ID      string
+	Doc     []string
+	Method  string
+	URL     string
+	Headers []string
+	Body    string
+}
+
+// httpFile is the .http file of a tag
+type httpFile struct {
+	Tag       string
+	Variables []string
+	Requests  []*httpFileRequest
+}
+
+// WriteHTTPFiles renders the operations as .http request files for the VS Code REST Client and the
+// JetBrains HTTP Client, one per tag keyed by its file suffix, .<tag>.http, and .http for untagged
+// operations. Operations with several tags are written to the file of the first one. Every file
+// declares the server URL as @baseUrl and a variable per security scheme its requests
+// authenticate with. Required parameters and parameters with an example are sent, and bodies hold
+// the example of the request body or one generated from its schema
+func (n *OpenAPIConverter) WriteHTTPFiles() (map[string]string, error) {
+	table, err := n.RouteTable()
+	if err != nil {
+		return nil, err
+	}
+
+	files := map[string]*httpFile{}
+	variables := map[string]map[string]bool{}
+	generator := NewExampleGenerator(table.Schemas, 1)
+	for _, route := range table.Routes {
+		for _, op := range route.Operations {
+			suffix, tag := ".http", ""
+			if len(op.Tags) > 0 {
+				tag = op.Tags[0]
+				suffix = "." + pathSlug(tag) + ".http"
+			}
+
+			file, ok := files[suffix]
+			if !ok {
+				file = &httpFile{Tag: tag}
+				files[suffix] = file
+				variables[suffix] = map[string]bool{}
+			}
+
+			request, credentials, err := httpFileRequestOf(table, route, op, generator)
+			if err != nil {
+				return nil, fmt.Errorf("file '%s': %s %s: %w", table.FilePath, op.Method, route.Path, err)
+			}
+			file.Requests = append(file.Requests, request)
+			for _, variable := range credentials {
+				variables[suffix][variable] = true
+			}
+		}
+	}
+
+	rendered := map[string]string{}
+	for suffix, file := range files {
+		file.Variables = sortedKeys(variables[suffix])
+
+		data := struct {
+			Title    string
+			FilePath string
+			BaseURL  string
+			*httpFile
+		}{
+			Title:    n.doc.Info.Title,
+			FilePath: table.FilePath,
+			BaseURL:  table.ServerURL,
+			httpFile: file,
+		}
+
+		content, err := utils.ExecuteTemplate(utils.FormatRaw, "http-file", httpFileTemplate, data)
+		if err != nil {
+			return nil, err
+		}
+		rendered[suffix] = content
+	}
+
+	return rendered, nil
+}
+
+// httpFileRequestOf builds the request of an operation and lists the credential variables it uses
+func httpFileRequestOf(table *RouteTable, route *Route, op *RouteOperation, generator *ExampleGenerator) (*httpFileRequest, []string, error) {
+	request := &httpFileRequest{
+		Name:   op.Summary,
+		ID:     op.OperationID,
+		Method: op.Method,
+	}
+	if request.Name == "" {
+		request.Name = op.Method + " " + route.Path
+	}
+	if op.Description != "" {
+		for _, line := range strings.Split(strings.TrimSpace(op.Description), "\n") {
+			request.Doc = append(request.Doc, strings.TrimSpace(line))
+		}
+	}
+
+	path := route.Path
+	query := url.Values{}
+	var cookies []string
+	for _, param := range route.OperationParams(op) {
+		if !param.Required && param.Example == nil {
+			continue
+		}
+
+		value := param.Example
+		if value == nil {
+			value = generator.generate(param.Schema, param.Name)
+		}
+
+		switch param.In {
+		case "path":
+			path = strings.ReplaceAll(path, "{"+param.Name+"}", url.PathEscape(paramString(value)))
+		case "query":
+			if items, ok := value.([]interface{}); ok {
+				for _, item := range items {
+					query.Add(param.Name, paramString(item))
+				}
+			} else {
+				query.Set(param.Name, paramString(value))
+			}
+		case "header":
+			request.Headers = append(request.Headers, param.Name+": "+paramString(value))
+		case "cookie":
+			cookies = append(cookies, param.Name+"="+paramString(value))
+		}
+	}
+
+	// Credentials are variables named after the security schemes of the first alternative
+	var credentials []string
+	if len(op.Security) > 0 {
+		for _, name := range sortedKeys(op.Security[0]) {
+			scheme := table.SecuritySchemes[name]
+			if scheme == nil {
+				return nil, nil, fmt.Errorf("security scheme '%s' is not defined in components.securitySchemes", name)
+			}
+
+			switch {
+			case scheme.Type == "http" && strings.EqualFold(scheme.Scheme, "basic"):
+				credentials = append(credentials, name+"Username", name+"Password")
+				request.Headers = append(request.Headers, "Authorization: Basic {{"+name+"Username}} {{"+name+"Password}}")
+			case scheme.Type == "http" && strings.EqualFold(scheme.Scheme, "bearer"), scheme.Type == "oauth2", scheme.Type == "openIdConnect":
+				credentials = append(credentials, name)
+				request.Headers = append(request.Headers, "Authorization: Bearer {{"+name+"}}")
+			case scheme.Type == "apiKey" && scheme.In == "query":
+				credentials = append(credentials, name)
+				query.Set(scheme.Name, "{{"+name+"}}")
+			case scheme.Type == "apiKey" && scheme.In == "cookie":
+				credentials = append(credentials, name)
+				cookies = append(cookies, scheme.Name+"={{"+name+"}}")
+			case scheme.Type == "apiKey":
+				credentials = append(credentials, name)
+				request.Headers = append(request.Headers, scheme.Name+": {{"+name+"}}")
+			}
+		}
+	}
+
+	request.URL = "{{baseUrl}}" + path
+	if len(query) > 0 {
+		// Variables have to stay unescaped to be substituted
+		encoded := strings.NewReplacer("%7B%7B", "{{", "%7D%7D", "}}").Replace(query.Encode())
+		request.URL += "?" + encoded
+	}
+
+	if len(cookies) > 0 {
+		request.Headers = append(request.Headers, "Cookie: "+strings.Join(cookies, "; "))
+	}
+	if accept := responseContentTypes(op); len(accept) > 0 {
+		request.Headers = append(request.Headers, "Accept: "+strings.Join(accept, ", "))
+	}
+
+	if op.Body != nil {
+		contentType := op.Body.ContentTypes[0]
+		for _, declared := range op.Body.ContentTypes {
+			if isJSONMediaType(declared) {
+				contentType = declared
+				break
+			}
+		}
+		request.Headers = append(request.Headers, "Content-Type: "+contentType)
+
+		value, ok := op.Body.Examples[contentType]
+		if !ok {
+			value = generator.Generate(op.Body.Schemas[contentType])
+		}
+		if value != nil {
+			body, err := exampleBody(value, contentType)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to encode %s request body: %w", contentType, err)
+			}
+			request.Body = body
+		}
+	}
+
+	return request, credentials, nil
+}
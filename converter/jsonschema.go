@@ -0,0 +1,214 @@
+package converter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// jsonSchemaDialect is the $schema of the exported JSON Schemas
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// jsonSchemaExport converts component schemas into standalone JSON Schemas
+type jsonSchemaExport struct {
+	schemas map[string]*Schema
+	baseID  string
+	defs    bool
+}
+
+// WriteJSONSchemas converts every component schema into a standalone draft 2020-12 JSON Schema
+// keyed by its file name, <name>.json. The $id of a schema is its file name resolved against
+// baseID. References to other component schemas become relative references to their files, or,
+// with defs, references into $defs holding every schema the root refers to
+func (n *OpenAPIConverter) WriteJSONSchemas(baseID string, defs bool) (map[string]string, error) {
+	var schemas map[string]*Schema
+	if n.doc.Components != nil {
+		schemas = n.doc.Components.Schemas
+	}
+	if len(schemas) == 0 {
+		return nil, fmt.Errorf("file '%s': no schemas are defined in components.schemas", n.filePath)
+	}
+
+	if baseID != "" && !strings.HasSuffix(baseID, "/") {
+		baseID += "/"
+	}
+	export := &jsonSchemaExport{schemas: schemas, baseID: baseID, defs: defs}
+
+	files := map[string]string{}
+	for _, name := range sortedKeys(schemas) {
+		for _, ref := range export.refs(schemas[name], map[string]bool{}) {
+			if _, ok := schemas[ref]; !ok {
+				return nil, fmt.Errorf("file '%s': schema '%s' refers to '%s', which is not defined in components.schemas", n.filePath, name, ref)
+			}
+		}
+
+		var buf bytes.Buffer
+		encoder := json.NewEncoder(&buf)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(export.document(name)); err != nil {
+			return nil, fmt.Errorf("file '%s': failed to marshal schema '%s': %w", n.filePath, name, err)
+		}
+
+		files[name+".json"] = buf.String()
+	}
+
+	return files, nil
+}
+
+// document is the JSON Schema of the named component, with $defs of the schemas it refers to
+func (e *jsonSchemaExport) document(name string) map[string]interface{} {
+	document := e.convert(e.schemas[name], name)
+	document["$schema"] = jsonSchemaDialect
+	document["$id"] = e.baseID + name + ".json"
+	document["title"] = name
+
+	if e.defs {
+		defs := map[string]interface{}{}
+		for _, ref := range e.refs(e.schemas[name], map[string]bool{name: true}) {
+			if ref != name {
+				defs[ref] = e.convert(e.schemas[ref], name)
+			}
+		}
+		if len(defs) > 0 {
+			document["$defs"] = defs
+		}
+	}
+
+	return document
+}
+
+// refs lists the component schemas a schema refers to, directly and through the schemas it
+// refers to. seen holds the schemas already visited
+func (e *jsonSchemaExport) refs(schema *Schema, seen map[string]bool) []string {
+	if schema == nil {
+		return nil
+	}
+
+	var refs []string
+	if schema.Ref != nil {
+		name := strings.TrimPrefix(*schema.Ref, "#/components/schemas/")
+		if seen[name] {
+			return nil
+		}
+		seen[name] = true
+		return append([]string{name}, e.refs(e.schemas[name], seen)...)
+	}
+
+	children := append(append(append([]*Schema{schema.Items}, schema.AllOf...), schema.OneOf...), schema.AnyOf...)
+	for _, property := range sortedKeys(schema.Properties) {
+		children = append(children, schema.Properties[property])
+	}
+	for _, child := range children {
+		refs = append(refs, e.refs(child, seen)...)
+	}
+
+	return refs
+}
+
+// convert translates an OpenAPI 3.0 schema into JSON Schema 2020-12: nullable becomes a "null"
+// type, example becomes examples and references are rewritten. root is the component the
+// document is written for
+func (e *jsonSchemaExport) convert(schema *Schema, root string) map[string]interface{} {
+	result := map[string]interface{}{}
+	if schema == nil {
+		return result
+	}
+
+	if schema.Ref != nil {
+		name := strings.TrimPrefix(*schema.Ref, "#/components/schemas/")
+		switch {
+		case name == root:
+			result["$ref"] = "#"
+		case e.defs:
+			result["$ref"] = "#/$defs/" + name
+		default:
+			result["$ref"] = name + ".json"
+		}
+		return result
+	}
+
+	if schema.Description != nil {
+		result["description"] = *schema.Description
+	}
+	if schema.Format != nil {
+		result["format"] = *schema.Format
+	}
+	if schema.Example != nil {
+		result["examples"] = []interface{}{schema.Example}
+	}
+	if schema.Default != nil {
+		result["default"] = schema.Default
+	}
+	if len(schema.Enum) > 0 {
+		result["enum"] = schema.Enum
+	}
+	if schema.Minimum != nil {
+		result["minimum"] = *schema.Minimum
+	}
+	if schema.Maximum != nil {
+		result["maximum"] = *schema.Maximum
+	}
+	if schema.MinLength != nil {
+		result["minLength"] = *schema.MinLength
+	}
+	if schema.MaxLength != nil {
+		result["maxLength"] = *schema.MaxLength
+	}
+	if schema.MinItems != nil {
+		result["minItems"] = *schema.MinItems
+	}
+	if schema.MaxItems != nil {
+		result["maxItems"] = *schema.MaxItems
+	}
+
+	var required []string
+	for _, name := range schema.Required {
+		if name != nil {
+			required = append(required, *name)
+		}
+	}
+	if len(required) > 0 {
+		result["required"] = required
+	}
+
+	if len(schema.Properties) > 0 {
+		properties := map[string]interface{}{}
+		for name, property := range schema.Properties {
+			properties[name] = e.convert(property, root)
+		}
+		result["properties"] = properties
+	}
+	if schema.Items != nil {
+		result["items"] = e.convert(schema.Items, root)
+	}
+
+	for key, composed := range map[string][]*Schema{"allOf": schema.AllOf, "oneOf": schema.OneOf, "anyOf": schema.AnyOf} {
+		if len(composed) == 0 {
+			continue
+		}
+
+		schemas := make([]interface{}, 0, len(composed))
+		for _, item := range composed {
+			schemas = append(schemas, e.convert(item, root))
+		}
+		result[key] = schemas
+	}
+
+	nullable := schema.Nullable != nil && *schema.Nullable
+	switch {
+	case schema.Type != nil && nullable:
+		result["type"] = []string{*schema.Type, "null"}
+		if len(schema.Enum) > 0 {
+			result["enum"] = append(append([]interface{}{}, schema.Enum...), nil)
+		}
+	case schema.Type != nil:
+		result["type"] = *schema.Type
+	case nullable:
+		// Without a type, null is allowed next to the composed schemas
+		return map[string]interface{}{"anyOf": []interface{}{result, map[string]interface{}{"type": "null"}}}
+	}
+
+	return result
+}
@@ -0,0 +1,300 @@
+package converter
+
+import (
+	"fmt"
+	"github.com/nimling/openapi-converter/kong"
+	"gopkg.in/yaml.v3"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// WriteKongConfiguration renders the spec as decK declarative configuration: one service pointing
+// at servers[0] with one route per operation. Security requirements become jwt, key-auth or
+// basic-auth plugins on the routes, and x-cors and x-rate-limit become cors and rate-limiting
+// plugins on the service when defined for the whole document, or on the routes otherwise
+func (n *OpenAPIConverter) WriteKongConfiguration() (string, error) {
+	table, err := n.RouteTable()
+	if err != nil {
+		return "", err
+	}
+
+	return renderKong(table)
+}
+
+func renderKong(table *RouteTable) (string, error) {
+	service := &kong.Service{
+		Name: table.Spec,
+		URL:  table.ServerURL,
+	}
+
+	timeouts := []struct {
+		dst   *int
+		value string
+	}{
+		{&service.ConnectTimeout, table.Settings.ConnectTimeout},
+		{&service.WriteTimeout, table.Settings.SendTimeout},
+		{&service.ReadTimeout, table.Settings.ReadTimeout},
+	}
+	for _, timeout := range timeouts {
+		duration, err := parseNginxDuration(timeout.value)
+		if err != nil {
+			return "", fmt.Errorf("file '%s': %w", table.FilePath, err)
+		}
+		*timeout.dst = int(duration.Milliseconds())
+	}
+
+	if table.CORS != nil {
+		plugin, err := kongCORSPlugin(table.CORS)
+		if err != nil {
+			return "", fmt.Errorf("file '%s': %w", table.FilePath, err)
+		}
+		service.Plugins = append(service.Plugins, plugin)
+	}
+
+	if table.RateLimit != nil {
+		plugin, err := kongRateLimitPlugin(table.RateLimit)
+		if err != nil {
+			return "", fmt.Errorf("file '%s': %w", table.FilePath, err)
+		}
+		service.Plugins = append(service.Plugins, plugin)
+	}
+
+	for _, route := range table.Routes {
+		match := kongPaths(route.Path, table.Prefix, table.Upstream.BasePath)
+
+		var pathPlugins []*kong.Plugin
+		if route.CORSScope == ScopePath {
+			plugin, err := kongCORSPlugin(route.CORS)
+			if err != nil {
+				return "", fmt.Errorf("file '%s': path '%s': %w", table.FilePath, route.Path, err)
+			}
+			pathPlugins = append(pathPlugins, plugin)
+		}
+
+		for _, op := range route.Operations {
+			plugins, err := kongAuthPlugins(table, route, op)
+			if err != nil {
+				return "", fmt.Errorf("file '%s': path '%s' %s operation: %w", table.FilePath, route.Path, op.Method, err)
+			}
+			plugins = append(plugins, pathPlugins...)
+			plugins = append(plugins, match.Plugins...)
+
+			limit := op.RateLimit
+			if limit == nil && route.RateLimitScope == ScopePath {
+				limit = route.RateLimit
+			}
+			if limit != nil {
+				plugin, err := kongRateLimitPlugin(limit)
+				if err != nil {
+					return "", fmt.Errorf("file '%s': path '%s' %s operation: %w", table.FilePath, route.Path, op.Method, err)
+				}
+				plugins = append(plugins, plugin)
+			}
+
+			service.Routes = append(service.Routes, &kong.Route{
+				Name:          table.Spec + "_" + invalidIdentifierChars.ReplaceAllString(op.OperationID, "_"),
+				Paths:         match.Paths,
+				Methods:       []string{op.Method},
+				RegexPriority: match.RegexPriority,
+				StripPath:     match.StripPath,
+				Plugins:       plugins,
+			})
+		}
+
+		// The cors plugin only answers preflight requests on routes accepting OPTIONS, and they
+		// must not pass through the authentication plugins
+		if route.CORS != nil {
+			service.Routes = append(service.Routes, &kong.Route{
+				Name:          route.Name + "_preflight",
+				Paths:         match.Paths,
+				Methods:       []string{"OPTIONS"},
+				RegexPriority: match.RegexPriority,
+				StripPath:     match.StripPath,
+				Plugins:       pathPlugins,
+			})
+		}
+	}
+
+	config := &kong.Config{
+		FormatVersion: kong.FormatVersion,
+		Services:      []*kong.Service{service},
+	}
+
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return "", fmt.Errorf("file '%s': failed to marshal Kong configuration: %w", table.FilePath, err)
+	}
+
+	return string(data), nil
+}
+
+// kongMatch is how the routes of a spec path match requests and forward them to the service
+type kongMatch struct {
+	Paths         []string
+	RegexPriority int
+	StripPath     bool
+	Plugins       []*kong.Plugin
+}
+
+// kongPaths returns how Kong matches a spec path so that the service gets what the Nginx rewrite
+// forwards. Every path is an anchored regex, like the Nginx exact and regex locations, and literal
+// paths get a higher regex_priority so /users/me is tried before /users/{id}. Kong 3.x routers
+// support no lookarounds and strip_path removes the whole regex match, so the stripped prefix
+// itself is stripped, while paths below it capture the rest of the path, which a
+// request-transformer puts after the base path
+func kongPaths(path string, prefix string, basePath string) *kongMatch {
+	priority := 0
+	if !isTemplatedPath(path) {
+		priority = 1
+	}
+
+	switch {
+	case prefix != "" && path == prefix:
+		return &kongMatch{Paths: []string{"~" + regexp.QuoteMeta(path) + "$"}, RegexPriority: priority, StripPath: true}
+	case prefix != "" && strings.HasPrefix(path, prefix+"/"):
+		rest := strings.TrimSuffix(strings.TrimPrefix(pathTemplateRegex(strings.TrimPrefix(path, prefix)), "^"), "$")
+
+		return &kongMatch{
+			Paths:         []string{"~" + regexp.QuoteMeta(prefix) + "(?P<rest>" + rest + ")$"},
+			RegexPriority: priority,
+			Plugins: []*kong.Plugin{{Name: "request-transformer", Config: map[string]interface{}{
+				"replace": map[string]interface{}{"uri": strings.TrimSuffix(basePath, "/") + "$(uri_captures['rest'])"},
+			}}},
+		}
+	}
+
+	return &kongMatch{Paths: []string{"~" + strings.TrimPrefix(pathTemplateRegex(path), "^")}, RegexPriority: priority}
+}
+
+// kongAuthPlugins maps the security requirement of an operation to Kong authentication plugins.
+// Requirements Kong cannot check fail closed, like in the Nginx output: the route gets a
+// request-termination plugin answering 401 and a warning is printed
+func kongAuthPlugins(table *RouteTable, route *Route, op *RouteOperation) ([]*kong.Plugin, error) {
+	requirement, err := op.Requirement()
+	if err != nil {
+		return kongRejectPlugins(table, route, op, err.Error()), nil
+	}
+
+	names := make([]string, 0, len(requirement))
+	for name := range requirement {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var plugins []*kong.Plugin
+	for _, name := range names {
+		scheme, err := table.SecurityScheme(name)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case scheme.Type == "http" && strings.EqualFold(scheme.Scheme, "bearer"):
+			plugins = append(plugins, &kong.Plugin{Name: "jwt", Config: map[string]interface{}{
+				"claims_to_verify": []string{"exp"},
+			}})
+		case scheme.Type == "http" && strings.EqualFold(scheme.Scheme, "basic"):
+			plugins = append(plugins, &kong.Plugin{Name: "basic-auth", Config: map[string]interface{}{
+				"hide_credentials": true,
+			}})
+		case scheme.Type == "apiKey" && (scheme.In == "header" || scheme.In == "query"):
+			plugins = append(plugins, &kong.Plugin{Name: "key-auth", Config: map[string]interface{}{
+				"key_names":     []string{scheme.Name},
+				"key_in_header": scheme.In == "header",
+				"key_in_query":  scheme.In == "query",
+				"key_in_body":   false,
+			}})
+		default:
+			return kongRejectPlugins(table, route, op, fmt.Sprintf("security scheme '%s' of type '%s' has no Kong plugin equivalent", name, scheme.Type)), nil
+		}
+	}
+
+	return plugins, nil
+}
+
+// kongRejectPlugins answers every request of an operation with 401
+func kongRejectPlugins(table *RouteTable, route *Route, op *RouteOperation, reason string) []*kong.Plugin {
+	warnRejectedOperation("Kong", table.FilePath, route.Path, op.Method, reason)
+
+	return []*kong.Plugin{{Name: "request-termination", Config: map[string]interface{}{
+		"status_code": 401,
+		"message":     "Unauthorized",
+	}}}
+}
+
+func kongCORSPlugin(definition *CORS) (*kong.Plugin, error) {
+	if len(definition.Origins) == 0 {
+		return nil, fmt.Errorf("x-cors must list at least one origin")
+	}
+
+	origins := make([]string, 0, len(definition.Origins))
+	for _, origin := range definition.Origins {
+		switch {
+		case strings.HasPrefix(origin, "~*"):
+			origins = append(origins, "(?i)"+strings.TrimPrefix(origin, "~*"))
+		case strings.HasPrefix(origin, "~"):
+			origins = append(origins, strings.TrimPrefix(origin, "~"))
+		default:
+			origins = append(origins, origin)
+		}
+	}
+
+	headers := definition.Headers
+	if len(headers) == 0 {
+		headers = defaultCORSHeaders
+	}
+
+	config := map[string]interface{}{
+		"origins":            origins,
+		"headers":            headers,
+		"credentials":        definition.Credentials,
+		"preflight_continue": false,
+	}
+	if len(definition.Methods) > 0 {
+		config["methods"] = definition.Methods
+	}
+	if len(definition.ExposeHeaders) > 0 {
+		config["exposed_headers"] = definition.ExposeHeaders
+	}
+	if definition.MaxAge > 0 {
+		config["max_age"] = definition.MaxAge
+	}
+
+	return &kong.Plugin{Name: "cors", Config: config}, nil
+}
+
+// kongRateLimitPlugin maps x-rate-limit to the rate-limiting plugin. Kong counts requests in
+// fixed windows, so burst and nodelay have no equivalent
+func kongRateLimitPlugin(limit *RateLimit) (*kong.Plugin, error) {
+	if !rateLimitRatePattern.MatchString(limit.Rate) {
+		return nil, fmt.Errorf("x-rate-limit rate '%s' must look like 10r/s or 100r/m", limit.Rate)
+	}
+
+	count, _ := strconv.Atoi(strings.SplitN(limit.Rate, "r/", 2)[0])
+	window := "second"
+	if strings.HasSuffix(limit.Rate, "/m") {
+		window = "minute"
+	}
+
+	config := map[string]interface{}{
+		window:   count,
+		"policy": "local",
+	}
+
+	switch key := limit.Key; {
+	case key == "" || key == "ip":
+		config["limit_by"] = "ip"
+	case key == "subject":
+		config["limit_by"] = "header"
+		config["header_name"] = "Authorization"
+	case strings.HasPrefix(key, "header:") && strings.TrimSpace(strings.TrimPrefix(key, "header:")) != "":
+		config["limit_by"] = "header"
+		config["header_name"] = strings.TrimSpace(strings.TrimPrefix(key, "header:"))
+	default:
+		return nil, fmt.Errorf("x-rate-limit key '%s' has no Kong equivalent, use ip, subject or header:<name>", key)
+	}
+
+	return &kong.Plugin{Name: "rate-limiting", Config: config}, nil
+}
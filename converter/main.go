@@ -10,13 +10,18 @@ import (
 )
 
 type OpenAPIConverter struct {
-	doc               *OpenAPIDoc
-	CommonPrefix      string
-	filePath          string
-	apiTitle          string
-	apiDescription    string
-	FilePrefix        string
-	WriteIntroduction bool
+	doc                        *OpenAPIDoc
+	CommonPrefix               string
+	filePath                   string
+	apiTitle                   string
+	apiDescription             string
+	FilePrefix                 string
+	WriteIntroduction          bool
+	ServerVariablePlaceholders bool
+	HTTPContext                *HTTPContext
+	// NjsImportPath is the directory Nginx loads the njs validation modules from. When set,
+	// locations with parameters or request bodies are validated by the module of the spec
+	NjsImportPath string
 }
 
 // NewOpenApiConverter creates a new OpenApiConverter
@@ -53,6 +58,27 @@ func NewOpenApiConverter(filePath string) (*OpenAPIConverter, error) {
 	return conv, nil
 }
 
+// Clone returns a converter working on a deep copy of the document, so changes only meant for one
+// output, such as filled examples, do not leak into the others
+func (n *OpenAPIConverter) Clone() (*OpenAPIConverter, error) {
+	data, err := yaml.Marshal(n.doc)
+	if err != nil {
+		return nil, fmt.Errorf("file '%s': failed to copy the document: %w", n.filePath, err)
+	}
+
+	doc := &OpenAPIDoc{}
+	if err := yaml.Unmarshal(data, doc); err != nil {
+		return nil, fmt.Errorf("file '%s': failed to copy the document: %w", n.filePath, err)
+	}
+	if n.doc.Components != nil && doc.Components != nil {
+		doc.Components.Register = n.doc.Components.Register
+	}
+
+	clone := *n
+	clone.doc = doc
+	return &clone, nil
+}
+
 func (n *OpenAPIConverter) ValidateDocument() error {
 	if n.doc.Info == nil {
 		return fmt.Errorf("file '%s': missing required 'info' section in OpenAPIDoc specification", n.filePath)
@@ -78,6 +104,10 @@ func (n *OpenAPIConverter) ValidateDocument() error {
 		return fmt.Errorf("file '%s': missing required 'servers[0].url' in OpenAPIDoc specification", n.filePath)
 	}
 
+	if err := n.validateServerVariables(); err != nil {
+		return err
+	}
+
 	if n.doc.Paths == nil || len(n.doc.Paths) <= 0 {
 		return fmt.Errorf("file '%s': no paths found in OpenAPIDoc specification", n.filePath)
 	}
@@ -138,72 +168,74 @@ func (n *OpenAPIConverter) ValidateDocument() error {
 	return nil
 }
 
-func (n *OpenAPIConverter) convertPath(path string, pathItem *PathItem) (string, error) {
-	var methods []string
-	var summaries []string
-	var descriptions []string
-
-	// Collect security requirements for each method
-	methodSecurity := make(map[string][]string)
-
-	// Get global security requirements
-	globalClaims := make([]string, 0)
-	if n.doc.Security != nil && len(*n.doc.Security) > 0 {
-		for _, sec := range *n.doc.Security {
-			fmt.Println(sec)
-			//for _, entraAuth := range sec.Requirements.Value("oauth2") {
-			//	globalClaims = append(globalClaims, entraAuth)
-			//}
-		}
+func (n *OpenAPIConverter) convertPath(table *RouteTable, route *Route, upstream *nginxUpstream) (string, error) {
+	rateLimits, err := n.rateLimits(table, route)
+	if err != nil {
+		return "", err
 	}
 
-	// Helper function to process operations
-	for _, op := range pathItem.Operations() {
-		methods = append(methods, op.Method)
-		if op.Summary != nil {
-			summaries = append(summaries, fmt.Sprintf("%s: %s", op.Method, op.Summary))
-		}
-		if op.Description != nil {
-			descriptions = append(descriptions, fmt.Sprintf("%s: %s", op.Method, op.Description))
-		}
+	cors, err := n.cors(route)
+	if err != nil {
+		return "", err
+	}
 
-		// Check for operation-specific security
-		for _, sec := range op.Security {
-			fmt.Println(sec)
-			//for _, entraAuth := range sec.Requirements.Value("oauth2") {
-			//	methodSecurity[method] = append(methodSecurity[method], entraAuth)
-			//}
-		}
-		// If no specific claims but global claims exist, use those
-		//if _, hasSpecific := methodSecurity[method]; !hasSpecific && len(globalClaims) > 0 {
-		//	methodSecurity[method] = globalClaims
-		//}
+	auth, err := n.auth(table, route)
+	if err != nil {
+		return "", err
+	}
+
+	validation, err := n.validation(table, route)
+	if err != nil {
+		return "", err
 	}
 
+	// Preflight requests must pass the method restriction
+	allowMethods := route.AllowedMethods()
+
 	data := struct {
 		Path         string
+		Location     string
 		Methods      []string
 		AllowMethods string
-		ServerURL    string
+		ProxyPass    string
+		Rewrites     []nginxRewrite
+		SSLName      string
 		Summaries    []string
 		Descriptions []string
-		Prefix       string
-		GlobalClaims []string
-		MethodClaims map[string][]string
+		Settings     *NginxSettings
+		RateLimits   []*nginxRateLimit
+		CORS         *nginxCORS
+		Auth         *nginxAuth
+		Validation   *nginxValidation
 	}{
-		Path:         path,
-		Methods:      methods,
-		AllowMethods: strings.Join(methods, " "),
-		ServerURL:    n.doc.Servers[0].URL,
-		Summaries:    summaries,
-		Descriptions: descriptions,
-		GlobalClaims: globalClaims,
-		MethodClaims: methodSecurity,
+		Path:         route.Path,
+		Location:     nginxLocationMatch(route.Path),
+		Methods:      route.Methods,
+		AllowMethods: strings.Join(allowMethods, " "),
+		ProxyPass:    upstream.ProxyPass(),
+		Rewrites:     nginxRewrites(table),
+		Summaries:    route.Summaries(),
+		Descriptions: route.Descriptions(),
+		Settings:     route.Settings,
+		RateLimits:   rateLimits,
+		CORS:         cors,
+		Auth:         auth,
+		Validation:   validation,
+	}
+
+	if upstream.Scheme == "https" {
+		data.SSLName = upstream.Host
 	}
 
-	if n.CommonPrefix != "" {
-		data.Prefix = strings.TrimSuffix(n.CommonPrefix, "/")
+	location, err := utils.ExecuteTemplate(utils.FormatRaw, "nginx", locationTemplate, data)
+	if err != nil || auth == nil || auth.Bearer == nil {
+		return location, err
+	}
+
+	introspection, err := utils.ExecuteTemplate(utils.FormatRaw, "nginx-auth", authLocationTemplate, auth.Bearer)
+	if err != nil {
+		return "", err
 	}
 
-	return utils.ExecuteTemplate("nginx", locationTemplate, data)
+	return location + "\n\n" + introspection, nil
 }
@@ -0,0 +1,268 @@
+package converter
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MockServer answers requests to the operations of a spec with the examples of their declared
+// responses. Requests are routed like the gateway outputs route them, using the route table
+type MockServer struct {
+	// Validate answers requests that do not match the parameters or the request body of their
+	// operation with a 400 problem response instead of a mock response
+	Validate bool
+	// Seed makes the payloads generated for responses without examples reproducible
+	Seed int64
+
+	table *RouteTable
+}
+
+// mockPreference holds the Prefer header preferences the mock server honors:
+// code=<status> picks the response, example=<name> one of its named examples and dynamic=true
+// a payload generated from the schema even when the response has examples
+type mockPreference struct {
+	Code    string
+	Example string
+	Dynamic bool
+	Applied []string
+}
+
+// MockServer creates a mock server for the resolved document
+func (n *OpenAPIConverter) MockServer() (*MockServer, error) {
+	table, err := n.RouteTable()
+	if err != nil {
+		return nil, err
+	}
+
+	return &MockServer{Validate: true, table: table}, nil
+}
+
+func (m *MockServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if origin := r.Header.Get("Origin"); origin != "" {
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		w.Header().Add("Vary", "Origin")
+	}
+
+	match := m.table.MatchRequest(r.Method, r.URL.Path)
+	if match == nil {
+		NewProblem(http.StatusNotFound, fmt.Sprintf("no path of %s matches %s", m.table.FilePath, r.URL.Path)).Write(w)
+		return
+	}
+
+	if match.Operation == nil {
+		allowed := strings.Join(append(append([]string{}, match.Route.Methods...), "OPTIONS"), ", ")
+		w.Header().Set("Allow", allowed)
+
+		if r.Method == http.MethodOptions {
+			w.Header().Set("Access-Control-Allow-Methods", allowed)
+			if headers := r.Header.Get("Access-Control-Request-Headers"); headers != "" {
+				w.Header().Set("Access-Control-Allow-Headers", headers)
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		NewProblem(http.StatusMethodNotAllowed, fmt.Sprintf("path %s has no %s operation", match.Route.Path, r.Method)).Write(w)
+		return
+	}
+
+	if m.Validate {
+		if problem := m.table.ValidateRequest(r, match); problem != nil {
+			problem.Write(w)
+			return
+		}
+	}
+
+	preference := parsePrefer(r.Header.Values("Prefer"))
+	status, response, problem := mockResponse(match.Operation, preference)
+	if problem != nil {
+		problem.Write(w)
+		return
+	}
+
+	if response == nil || len(response.Content) == 0 {
+		writePreferenceApplied(w, preference)
+		w.WriteHeader(status)
+		return
+	}
+
+	contentType, ok := negotiateContentType(response.Content, r.Header.Get("Accept"))
+	if !ok {
+		NewProblem(http.StatusNotAcceptable, fmt.Sprintf("the %d response of %s %s is only available as %s", status, r.Method, match.Route.Path, strings.Join(sortedKeys(response.Content), ", "))).Write(w)
+		return
+	}
+
+	body, problem := m.mockBody(response.Content[contentType], &preference)
+	if problem != nil {
+		problem.Write(w)
+		return
+	}
+
+	var data []byte
+	if text, isText := body.(string); isText && !isJSONMediaType(contentType) {
+		data = []byte(text)
+	} else {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			NewProblem(http.StatusInternalServerError, "failed to marshal the mock response: "+err.Error()).Write(w)
+			return
+		}
+	}
+
+	writePreferenceApplied(w, preference)
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	_, _ = w.Write(data)
+}
+
+// parsePrefer reads the mock preferences from Prefer headers such as "code=404, example=missing"
+func parsePrefer(headers []string) mockPreference {
+	var preference mockPreference
+	for _, header := range headers {
+		for _, token := range strings.FieldsFunc(header, func(r rune) bool { return r == ',' || r == ';' }) {
+			key, value, _ := strings.Cut(strings.TrimSpace(token), "=")
+			value = strings.Trim(strings.TrimSpace(value), `"`)
+
+			switch strings.ToLower(strings.TrimSpace(key)) {
+			case "code":
+				preference.Code = value
+			case "example":
+				preference.Example = value
+			case "dynamic":
+				preference.Dynamic = value == "true"
+			}
+		}
+	}
+
+	return preference
+}
+
+func writePreferenceApplied(w http.ResponseWriter, preference mockPreference) {
+	if len(preference.Applied) > 0 {
+		w.Header().Set("Preference-Applied", strings.Join(preference.Applied, ", "))
+	}
+}
+
+// mockResponse picks the response of an operation: the one for the preferred status code, else
+// the lowest declared 2xx status, else the lowest declared status, else the default response.
+// A preferred code falls back to its range (4XX) and to the default response
+func mockResponse(op *RouteOperation, preference mockPreference) (int, *Response, *Problem) {
+	if preference.Code != "" {
+		status, err := strconv.Atoi(preference.Code)
+		if err != nil || status < 100 || status > 599 {
+			return 0, nil, NewProblem(http.StatusBadRequest, fmt.Sprintf("Prefer code=%s is not a status code", preference.Code))
+		}
+
+		if response := op.Response(status); response != nil {
+			return status, response, nil
+		}
+
+		return 0, nil, NewProblem(http.StatusInternalServerError, fmt.Sprintf("operation %s declares no response for status %d", op.OperationID, status))
+	}
+
+	if len(op.Responses) == 0 {
+		return http.StatusNoContent, nil, nil
+	}
+
+	// Sorted keys put exact codes before the range with the same first digit and default last
+	keys := sortedKeys(op.Responses)
+	sort.SliceStable(keys, func(i, j int) bool {
+		return strings.HasPrefix(keys[i], "2") && !strings.HasPrefix(keys[j], "2")
+	})
+
+	status := http.StatusOK
+	if code, err := strconv.Atoi(keys[0]); err == nil {
+		status = code
+	} else if strings.HasSuffix(strings.ToUpper(keys[0]), "XX") {
+		if code, err := strconv.Atoi(keys[0][:1] + "00"); err == nil {
+			status = code
+		}
+	}
+
+	return status, op.Responses[keys[0]], nil
+}
+
+// negotiateContentType picks the declared content type that best matches the Accept header.
+// JSON is preferred when any content type is acceptable
+func negotiateContentType(content map[string]*ResponseContent, accept string) (string, bool) {
+	type acceptedRange struct {
+		pattern string
+		quality float64
+	}
+
+	var ranges []acceptedRange
+	for _, entry := range strings.Split(accept, ",") {
+		pattern := mediaType(entry)
+		if pattern == "" {
+			continue
+		}
+
+		quality := 1.0
+		for _, param := range strings.Split(entry, ";")[1:] {
+			if key, value, ok := strings.Cut(strings.TrimSpace(param), "="); ok && key == "q" {
+				if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+					quality = parsed
+				}
+			}
+		}
+		if quality > 0 {
+			ranges = append(ranges, acceptedRange{pattern: pattern, quality: quality})
+		}
+	}
+	if len(ranges) == 0 {
+		ranges = []acceptedRange{{pattern: "*/*", quality: 1}}
+	}
+	sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].quality > ranges[j].quality })
+
+	declared := sortedKeys(content)
+	for _, accepted := range ranges {
+		if accepted.pattern == "*/*" {
+			for _, contentType := range declared {
+				if isJSONMediaType(contentType) {
+					return contentType, true
+				}
+			}
+			return declared[0], true
+		}
+
+		for _, contentType := range declared {
+			if mediaTypeMatches(accepted.pattern, mediaType(contentType)) {
+				return contentType, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// mockBody returns the payload of a response content: the preferred named example, the example,
+// the first named example or finally a payload generated from the schema, which uses the schema
+// example when there is one
+func (m *MockServer) mockBody(content *ResponseContent, preference *mockPreference) (interface{}, *Problem) {
+	if content == nil {
+		return nil, nil
+	}
+
+	switch {
+	case preference.Example != "":
+		example, ok := content.Examples[preference.Example]
+		if !ok || example == nil {
+			return nil, NewProblem(http.StatusInternalServerError, fmt.Sprintf("the response has no example named %s, declared examples: %s", preference.Example, strings.Join(sortedKeys(content.Examples), ", ")))
+		}
+		preference.Applied = append(preference.Applied, "example="+preference.Example)
+		return example.Value, nil
+	case preference.Dynamic:
+		preference.Applied = append(preference.Applied, "dynamic=true")
+		generator := NewExampleGenerator(m.table.Schemas, m.Seed)
+		generator.UseExamples = false
+		return generator.Generate(content.Schema), nil
+	case content.example() != nil:
+		return content.example(), nil
+	}
+
+	return NewExampleGenerator(m.table.Schemas, m.Seed).Generate(content.Schema), nil
+}
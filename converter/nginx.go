@@ -1,18 +1,25 @@
 package converter
 
-import "strings"
+import (
+	"regexp"
+	"strings"
+)
 
 func (n *OpenAPIConverter) WriteNginxConfiguration() (string, error) {
-	// Validate required fields first
-	if err := n.ValidateDocument(); err != nil {
+	table, err := n.RouteTable()
+	if err != nil {
 		return "", err
-	} // TODO:: Excessive??
+	}
+
+	upstream, err := nginxUpstreamFor(table)
+	if err != nil {
+		return "", err
+	}
 
 	var locations []string
 
-	// Use KeysFromNewest() to iterate over the paths
-	for key, pathItem := range n.doc.Paths {
-		location, err := n.convertPath(key, pathItem)
+	for _, route := range table.Routes {
+		location, err := n.convertPath(table, route, upstream)
 		if err != nil {
 			return "", err
 		}
@@ -21,3 +28,30 @@ func (n *OpenAPIConverter) WriteNginxConfiguration() (string, error) {
 
 	return strings.Join(locations, "\n\n"), nil
 }
+
+type nginxRewrite struct {
+	Pattern     string
+	Replacement string
+}
+
+// nginxRewrites maps a request URI onto the upstream with the route table's path rewrite. That
+// rewrite only matches below the prefix, so the bare prefix gets a rule of its own
+func nginxRewrites(table *RouteTable) []nginxRewrite {
+	pattern, replacement, ok := table.PathRewrite()
+	if !ok {
+		return nil
+	}
+
+	rewrites := []nginxRewrite{{
+		Pattern:     nginxRegex(pattern),
+		Replacement: strings.ReplaceAll(replacement, `\1`, "$1"),
+	}}
+	if table.Prefix != "" {
+		rewrites = append(rewrites, nginxRewrite{
+			Pattern:     nginxRegex("^" + regexp.QuoteMeta(table.Prefix) + "$"),
+			Replacement: strings.TrimSuffix(table.Upstream.BasePath, "/") + "/",
+		})
+	}
+
+	return rewrites
+}
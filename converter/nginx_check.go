@@ -0,0 +1,372 @@
+package converter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// knownNginxDirectives lists the directives the generator emits plus the ones commonly added
+// through x-nginx directives. Anything else is most likely a typo
+var knownNginxDirectives = map[string]bool{
+	"add_header": true, "allow": true, "auth_basic": true, "auth_basic_user_file": true,
+	"auth_request": true, "auth_request_set": true, "backup": true, "break": true,
+	"client_body_buffer_size": true, "client_body_in_single_buffer": true, "client_max_body_size": true,
+	"default_type": true, "deny": true, "error_page": true, "expires": true, "gzip": true,
+	"hash": true, "if": true, "include": true, "internal": true, "ip_hash": true,
+	"js_content": true, "js_import": true, "js_path": true, "js_set": true,
+	"keepalive": true, "keepalive_requests": true, "keepalive_timeout": true, "least_conn": true,
+	"limit_except": true, "limit_req": true, "limit_req_status": true, "limit_req_zone": true,
+	"listen": true, "location": true, "map": true, "more_clear_headers": true,
+	"proxy_buffer_size": true, "proxy_buffering": true, "proxy_buffers": true, "proxy_busy_buffers_size": true,
+	"proxy_cache": true, "proxy_cache_bypass": true, "proxy_cache_key": true, "proxy_cache_valid": true,
+	"proxy_connect_timeout": true, "proxy_hide_header": true, "proxy_http_version": true,
+	"proxy_ignore_headers": true, "proxy_intercept_errors": true, "proxy_next_upstream": true,
+	"proxy_pass": true, "proxy_pass_request_body": true, "proxy_pass_request_headers": true,
+	"proxy_read_timeout": true, "proxy_redirect": true, "proxy_request_buffering": true,
+	"proxy_send_timeout": true, "proxy_set_body": true, "proxy_set_header": true,
+	"proxy_ssl_name": true, "proxy_ssl_server_name": true, "proxy_ssl_verify": true,
+	"random": true, "resolver": true, "return": true, "rewrite": true, "root": true,
+	"server": true, "server_name": true, "set": true, "upstream": true,
+}
+
+// blocks whose entries are key/value pairs rather than directives
+var nginxValueBlocks = map[string]bool{
+	"map":   true,
+	"types": true,
+}
+
+type nginxToken struct {
+	value  string
+	quoted bool
+	line   int
+}
+
+type nginxDirective struct {
+	name     string
+	args     []nginxToken
+	line     int
+	block    bool
+	children []*nginxDirective
+}
+
+// CheckNginxConfig parses a generated Nginx configuration and reports unbalanced braces,
+// unterminated directives, duplicate locations, prefix locations a regex location captures,
+// unknown directives and invalid regexes.
+// Regexes are compiled with Go's RE2 engine, so PCRE-only constructs such as lookarounds are
+// reported as invalid as well
+func CheckNginxConfig(name string, config string) error {
+	var problems []string
+	report := func(line int, format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf("%s:%d: %s", name, line, fmt.Sprintf(format, args...)))
+	}
+
+	tokens, err := tokenizeNginx(config)
+	if err != nil {
+		return fmt.Errorf("nginx check failed:\n%s: %s", name, err)
+	}
+
+	directives, err := parseNginx(tokens)
+	if err != nil {
+		return fmt.Errorf("nginx check failed:\n%s: %s", name, err)
+	}
+
+	checkNginxDirectives(directives, "", "", report)
+
+	if len(problems) > 0 {
+		return fmt.Errorf("nginx check failed:\n%s", strings.Join(problems, "\n"))
+	}
+
+	return nil
+}
+
+func tokenizeNginx(config string) ([]nginxToken, error) {
+	var tokens []nginxToken
+	var word strings.Builder
+	line, wordLine := 1, 1
+
+	flush := func() {
+		if word.Len() > 0 {
+			tokens = append(tokens, nginxToken{value: word.String(), line: wordLine})
+			word.Reset()
+		}
+	}
+
+	runes := []rune(config)
+	for i := 0; i < len(runes); i++ {
+		ch := runes[i]
+
+		switch {
+		case ch == '\n':
+			flush()
+			line++
+		case ch == ' ' || ch == '\t' || ch == '\r':
+			flush()
+		case ch == '#' && word.Len() == 0:
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+			i--
+		case ch == '"' || ch == '\'':
+			if word.Len() > 0 {
+				return nil, fmt.Errorf("line %d: unexpected quote inside '%s'", line, word.String())
+			}
+			start := line
+			var value strings.Builder
+			closed := false
+			for i++; i < len(runes); i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					next := runes[i+1]
+					if next != '"' && next != '\'' && next != '\\' {
+						value.WriteRune(runes[i])
+					}
+					value.WriteRune(next)
+					i++
+					continue
+				}
+				if runes[i] == '\n' {
+					line++
+				}
+				if runes[i] == ch {
+					closed = true
+					break
+				}
+				value.WriteRune(runes[i])
+			}
+			if !closed {
+				return nil, fmt.Errorf("line %d: unterminated quoted string", start)
+			}
+			tokens = append(tokens, nginxToken{value: value.String(), quoted: true, line: start})
+		case ch == '{' && strings.HasSuffix(word.String(), "$"):
+			// ${name} variable syntax, also used by envsubst placeholders
+			for ; i < len(runes) && runes[i] != '}'; i++ {
+				word.WriteRune(runes[i])
+			}
+			if i == len(runes) {
+				return nil, fmt.Errorf("line %d: unterminated variable '%s'", line, word.String())
+			}
+			word.WriteRune('}')
+		case ch == '{' || ch == '}' || ch == ';':
+			flush()
+			tokens = append(tokens, nginxToken{value: string(ch), line: line})
+		default:
+			if word.Len() == 0 {
+				wordLine = line
+			}
+			word.WriteRune(ch)
+		}
+	}
+	flush()
+
+	return tokens, nil
+}
+
+func parseNginx(tokens []nginxToken) ([]*nginxDirective, error) {
+	root := &nginxDirective{}
+	stack := []*nginxDirective{root}
+	var current *nginxDirective
+
+	for _, token := range tokens {
+		parent := stack[len(stack)-1]
+
+		if token.quoted {
+			if current == nil {
+				current = &nginxDirective{name: token.value, line: token.line}
+			} else {
+				current.args = append(current.args, token)
+			}
+			continue
+		}
+
+		switch token.value {
+		case ";":
+			if current == nil {
+				return nil, fmt.Errorf("line %d: unexpected ';'", token.line)
+			}
+			parent.children = append(parent.children, current)
+			current = nil
+		case "{":
+			if current == nil {
+				return nil, fmt.Errorf("line %d: unexpected '{' without a directive", token.line)
+			}
+			current.block = true
+			parent.children = append(parent.children, current)
+			stack = append(stack, current)
+			current = nil
+		case "}":
+			if current != nil {
+				return nil, fmt.Errorf("line %d: directive '%s' is missing a terminating ';'", current.line, current.name)
+			}
+			if len(stack) == 1 {
+				return nil, fmt.Errorf("line %d: unexpected '}'", token.line)
+			}
+			stack = stack[:len(stack)-1]
+		default:
+			if current == nil {
+				current = &nginxDirective{name: token.value, line: token.line}
+			} else {
+				current.args = append(current.args, token)
+			}
+		}
+	}
+
+	if current != nil {
+		return nil, fmt.Errorf("line %d: directive '%s' is missing a terminating ';'", current.line, current.name)
+	}
+
+	if len(stack) > 1 {
+		open := stack[len(stack)-1]
+		return nil, fmt.Errorf("line %d: block '%s' is not closed", open.line, open.name)
+	}
+
+	return root.children, nil
+}
+
+// checkNginxDirectives checks a block's directives. uriContext names the enclosing block when it is
+// one where proxy_pass must not have a URI part, and is empty otherwise
+func checkNginxDirectives(directives []*nginxDirective, parent string, uriContext string, report func(int, string, ...interface{})) {
+	locations := map[string]int{}
+	var prefixes, regexes []*nginxDirective
+
+	for _, directive := range directives {
+		if nginxValueBlocks[parent] {
+			if strings.HasPrefix(directive.name, "~") {
+				checkNginxRegex(directive.line, strings.TrimPrefix(strings.TrimPrefix(directive.name, "~"), "*"), report)
+			}
+			continue
+		}
+
+		if !knownNginxDirectives[directive.name] && !strings.HasSuffix(directive.name, "_by_lua_block") {
+			report(directive.line, "unknown directive '%s'", directive.name)
+		}
+
+		switch directive.name {
+		case "location":
+			if len(directive.args) == 0 {
+				report(directive.line, "location without a path")
+				break
+			}
+
+			key := joinNginxArgs(directive.args)
+			if first, ok := locations[key]; ok {
+				report(directive.line, "duplicate location '%s', first defined on line %d", key, first)
+			} else {
+				locations[key] = directive.line
+			}
+
+			switch {
+			case len(directive.args) == 2 && (directive.args[0].value == "~" || directive.args[0].value == "~*"):
+				checkNginxRegex(directive.line, directive.args[1].value, report)
+				regexes = append(regexes, directive)
+			case len(directive.args) == 1 && !strings.HasPrefix(directive.args[0].value, "@"):
+				prefixes = append(prefixes, directive)
+			}
+		case "rewrite":
+			if len(directive.args) < 2 {
+				report(directive.line, "rewrite needs a regex and a replacement")
+				break
+			}
+			checkNginxRegex(directive.line, directive.args[0].value, report)
+		case "if":
+			args := directive.args
+			if len(args) == 3 && strings.Contains(args[1].value, "~") {
+				checkNginxRegex(directive.line, strings.TrimSuffix(args[2].value, ")"), report)
+			}
+		}
+
+		if directive.name == "proxy_pass" && uriContext != "" && len(directive.args) > 0 && proxyPassHasURI(directive.args[0].value) {
+			report(directive.line, "proxy_pass cannot have a URI part in %s", uriContext)
+		}
+
+		if strings.HasSuffix(directive.name, "_by_lua_block") {
+			continue
+		}
+
+		if directive.block {
+			checkNginxDirectives(directive.children, directive.name, proxyURIContext(directive, uriContext), report)
+		}
+	}
+
+	checkCapturedPrefixes(prefixes, regexes, report)
+}
+
+// checkCapturedPrefixes reports prefix locations whose own path a regex location of the same block
+// matches. Nginx prefers the regex location, so the prefix location never sees that path; an
+// exact (=) or ^~ location keeps it
+func checkCapturedPrefixes(prefixes []*nginxDirective, regexes []*nginxDirective, report func(int, string, ...interface{})) {
+	for _, regex := range regexes {
+		pattern := regex.args[1].value
+		if regex.args[0].value == "~*" {
+			pattern = "(?i)" + pattern
+		}
+		compiled, err := regexp.Compile(pattern)
+		if err != nil {
+			continue
+		}
+
+		for _, prefix := range prefixes {
+			if path := prefix.args[0].value; compiled.MatchString(path) {
+				report(prefix.line, "prefix location '%s' is captured by regex location '%s' on line %d, use '= %s'", path, regex.args[1].value, regex.line, path)
+			}
+		}
+	}
+}
+
+// proxyURIContext returns the context a block opens for proxy_pass. Regex and named locations,
+// if blocks and limit_except blocks do not allow a URI, and nested blocks inherit that
+func proxyURIContext(directive *nginxDirective, uriContext string) string {
+	switch directive.name {
+	case "if":
+		return "an if block"
+	case "limit_except":
+		return "a limit_except block"
+	case "location":
+		if len(directive.args) == 0 {
+			break
+		}
+		if value := directive.args[0].value; value == "~" || value == "~*" {
+			return "a regex location"
+		} else if strings.HasPrefix(value, "@") {
+			return "a named location"
+		}
+	}
+
+	return uriContext
+}
+
+var nginxRuntimeVariable = regexp.MustCompile(`\$[A-Za-z_]`)
+
+// proxyPassHasURI reports whether a proxy_pass target has a path after the host. Targets with
+// runtime variables are skipped since Nginx allows a URI there; envsubst placeholders such as
+// ${REGION} are replaced before Nginx starts and do not count
+func proxyPassHasURI(target string) bool {
+	if nginxRuntimeVariable.MatchString(target) {
+		return false
+	}
+
+	_, rest, ok := strings.Cut(target, "://")
+	if !ok {
+		return false
+	}
+	if strings.HasPrefix(rest, "unix:") {
+		_, uri, _ := strings.Cut(strings.TrimPrefix(rest, "unix:"), ":")
+		return uri != ""
+	}
+
+	return strings.Contains(rest, "/")
+}
+
+func checkNginxRegex(line int, pattern string, report func(int, string, ...interface{})) {
+	if _, err := regexp.Compile(pattern); err != nil {
+		report(line, "invalid regex '%s': %s", pattern, err)
+	}
+}
+
+func joinNginxArgs(args []nginxToken) string {
+	values := make([]string, 0, len(args))
+	for _, arg := range args {
+		values = append(values, arg.value)
+	}
+
+	return strings.Join(values, " ")
+}
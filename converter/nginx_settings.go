@@ -0,0 +1,180 @@
+package converter
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultCacheControl = "private, no-cache, no-store, must-revalidate"
+
+type nginxHeader struct {
+	Name  string
+	Value string
+}
+
+func defaultNginxSettings() *NginxSettings {
+	buffering := true
+	return &NginxSettings{
+		ConnectTimeout:  "60s",
+		SendTimeout:     "60s",
+		ReadTimeout:     "60s",
+		Buffering:       &buffering,
+		BufferSize:      "16k",
+		Buffers:         "8 16k",
+		BusyBuffersSize: "32k",
+		CacheControl:    defaultCacheControl,
+		Headers:         map[string]string{},
+	}
+}
+
+// nginxSettings merges the x-nginx extensions of the document, the path item and its operation
+// on top of the defaults. Nginx cannot select a location by method, so every operation of a path
+// shares one location and an operation may only set x-nginx when it is alone on its path
+func (n *OpenAPIConverter) nginxSettings(path string, pathItem *PathItem) (*NginxSettings, error) {
+	settings := defaultNginxSettings()
+	settings.apply(n.doc.Nginx)
+	settings.apply(pathItem.Nginx)
+
+	operations := pathItem.SortedOperations()
+	for _, op := range operations {
+		if op.Nginx == nil {
+			continue
+		}
+		if len(operations) > 1 {
+			return nil, fmt.Errorf("file '%s': path '%s' %s operation: operation-level x-nginx is only supported on paths with a single method; x-nginx would apply to every method of the path, since they share one location. Define it at path level or give the operation its own path",
+				n.filePath, path, op.Method)
+		}
+		settings.apply(op.Nginx)
+	}
+
+	for i, directive := range settings.Directives {
+		directive = strings.TrimSpace(directive)
+		if !strings.HasSuffix(directive, ";") && !strings.HasSuffix(directive, "}") {
+			directive += ";"
+		}
+		settings.Directives[i] = directive
+	}
+
+	return settings, nil
+}
+
+// BodyDirectives lists the x-nginx directives tuning how the request body is read. js_content
+// reads the body in the validating location, so they are repeated there
+func (s *NginxSettings) BodyDirectives() []string {
+	var directives []string
+	for _, directive := range s.Directives {
+		if strings.HasPrefix(directive, "client_body_") {
+			directives = append(directives, directive)
+		}
+	}
+
+	return directives
+}
+
+// BufferingFlag renders the buffering switch as an Nginx on/off value
+func (s *NginxSettings) BufferingFlag() string {
+	if s.Buffering != nil && !*s.Buffering {
+		return "off"
+	}
+
+	return "on"
+}
+
+// NoCache reports whether the default no-cache policy is still in effect
+func (s *NginxSettings) NoCache() bool {
+	return s.CacheControl == defaultCacheControl
+}
+
+// SortedHeaders returns the extra response headers ordered by name
+func (s *NginxSettings) SortedHeaders() []nginxHeader {
+	headers := make([]nginxHeader, 0, len(s.Headers))
+	for name, value := range s.Headers {
+		headers = append(headers, nginxHeader{Name: name, Value: value})
+	}
+	sort.Slice(headers, func(i, j int) bool {
+		return headers[i].Name < headers[j].Name
+	})
+
+	return headers
+}
+
+// ResponseHeaders lists the caching and security headers the Nginx locations add, followed by
+// the x-nginx headers, for gateways that set them from a plain list
+func (s *NginxSettings) ResponseHeaders() []nginxHeader {
+	headers := []nginxHeader{{Name: "Cache-Control", Value: s.CacheControl}}
+	if s.NoCache() {
+		headers = append(headers, nginxHeader{Name: "Pragma", Value: "no-cache"})
+	}
+	headers = append(headers,
+		nginxHeader{Name: "X-Content-Type-Options", Value: "nosniff"},
+		nginxHeader{Name: "X-XSS-Protection", Value: "1; mode=block"},
+		nginxHeader{Name: "Strict-Transport-Security", Value: "max-age=31536000; includeSubDomains"},
+	)
+
+	return append(headers, s.SortedHeaders()...)
+}
+
+// apply overrides the settings with every value defined in other
+func (s *NginxSettings) apply(other *NginxSettings) {
+	if other == nil {
+		return
+	}
+
+	overrideString(&s.ConnectTimeout, other.ConnectTimeout)
+	overrideString(&s.SendTimeout, other.SendTimeout)
+	overrideString(&s.ReadTimeout, other.ReadTimeout)
+	overrideString(&s.ClientMaxBodySize, other.ClientMaxBodySize)
+	overrideString(&s.BufferSize, other.BufferSize)
+	overrideString(&s.Buffers, other.Buffers)
+	overrideString(&s.BusyBuffersSize, other.BusyBuffersSize)
+	overrideString(&s.CacheControl, other.CacheControl)
+	overrideString(&s.ProxyCache, other.ProxyCache)
+	overrideString(&s.ProxyCacheValid, other.ProxyCacheValid)
+
+	if other.Buffering != nil {
+		s.Buffering = other.Buffering
+	}
+
+	if s.Headers == nil {
+		s.Headers = map[string]string{}
+	}
+	for name, value := range other.Headers {
+		s.Headers[name] = value
+	}
+
+	s.Directives = append(s.Directives, other.Directives...)
+}
+
+func overrideString(dst *string, src string) {
+	if src != "" {
+		*dst = src
+	}
+}
+
+// parseNginxDuration reads an Nginx time value for the gateways that need it in another notation.
+// Values without a unit are seconds, as in Nginx
+func parseNginxDuration(value string) (time.Duration, error) {
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second, nil
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("time value '%s' must be a number followed by ms, s, m or h", value)
+	}
+
+	return duration, nil
+}
+
+// durationSeconds converts an Nginx time value such as 60s or 1m into whole or fractional seconds
+func durationSeconds(value string) (string, error) {
+	duration, err := parseNginxDuration(value)
+	if err != nil {
+		return "", err
+	}
+
+	return strconv.FormatFloat(duration.Seconds(), 'f', -1, 64) + "s", nil
+}
@@ -0,0 +1,265 @@
+package converter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/nimling/openapi-converter/utils"
+	"strings"
+)
+
+type njsRoute struct {
+	Pattern    string                   `json:"pattern,omitempty"`
+	Params     []string                 `json:"params,omitempty"`
+	Operations map[string]*njsOperation `json:"operations"`
+}
+
+type njsOperation struct {
+	Params []*njsParam `json:"params"`
+	Body   *njsBody    `json:"body,omitempty"`
+}
+
+type njsParam struct {
+	Name     string      `json:"name"`
+	In       string      `json:"in"`
+	Required bool        `json:"required,omitempty"`
+	Schema   interface{} `json:"schema,omitempty"`
+}
+
+type njsBody struct {
+	Required     bool                   `json:"required,omitempty"`
+	ContentTypes []string               `json:"contentTypes"`
+	Schemas      map[string]interface{} `json:"schemas,omitempty"`
+}
+
+// nginxValidation wires a location to its njs handler. Valid requests are redirected to the named
+// location holding the proxy configuration
+type nginxValidation struct {
+	Handler  string
+	Location string
+}
+
+// njsModuleName is the name the validation module of a spec is imported as
+func njsModuleName(spec string) string {
+	return spec + "_validation"
+}
+
+// hasValidation reports whether any operation of the route declares something the njs module checks
+func (r *Route) hasValidation() bool {
+	if len(r.Params) > 0 {
+		return true
+	}
+
+	for _, op := range r.Operations {
+		if len(op.Params) > 0 || op.Body != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validation registers the njs module import and returns the js_content wiring of a route, or nil
+// when njs validation is disabled or the route has nothing to check
+func (n *OpenAPIConverter) validation(table *RouteTable, route *Route) (*nginxValidation, error) {
+	if n.NjsImportPath == "" || !route.hasValidation() {
+		return nil, nil
+	}
+
+	module := njsModuleName(table.Spec)
+	err := n.httpContext().addImport(&njsImport{
+		Name:   module,
+		Path:   strings.TrimSuffix(n.NjsImportPath, "/") + "/" + n.njsFileName(),
+		Source: fmt.Sprintf("'%s'", n.filePath),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &nginxValidation{
+		Handler:  module + "." + route.Name,
+		Location: "@" + route.Name,
+	}, nil
+}
+
+func (n *OpenAPIConverter) njsFileName() string {
+	return n.specName() + ".validate.js"
+}
+
+// WriteNjsValidation renders the njs module validating requests of the spec before they are
+// proxied: required parameters and their types, the Content-Type of request bodies and the shape
+// of JSON bodies against the request body schema. Every route the module has checks for gets a
+// handler named after the route, which the locations call with js_content
+func (n *OpenAPIConverter) WriteNjsValidation() (string, error) {
+	table, err := n.RouteTable()
+	if err != nil {
+		return "", err
+	}
+
+	return renderNjsValidation(table)
+}
+
+func renderNjsValidation(table *RouteTable) (string, error) {
+	refs := map[string]bool{}
+	routes := map[string]*njsRoute{}
+	var handlers []string
+
+	for _, route := range table.Routes {
+		if !route.hasValidation() {
+			continue
+		}
+
+		validated := &njsRoute{Operations: map[string]*njsOperation{}}
+		if route.Match.Templated {
+			validated.Pattern = strings.ReplaceAll(route.Match.Regex, "[^/]+", "([^/]+)")
+			validated.Params = route.Match.Params
+		}
+
+		for _, op := range route.Operations {
+			operation := &njsOperation{Params: []*njsParam{}}
+
+			for _, param := range route.OperationParams(op) {
+				operation.Params = append(operation.Params, &njsParam{
+					Name:     param.Name,
+					In:       param.In,
+					Required: param.Required,
+					Schema:   validationSchema(param.Schema, refs),
+				})
+			}
+
+			if op.Body != nil {
+				operation.Body = &njsBody{
+					Required:     op.Body.Required,
+					ContentTypes: op.Body.ContentTypes,
+					Schemas:      map[string]interface{}{},
+				}
+				for contentType, schema := range op.Body.Schemas {
+					if isJSONMediaType(contentType) {
+						operation.Body.Schemas[contentType] = validationSchema(schema, refs)
+					}
+				}
+			}
+
+			validated.Operations[op.Method] = operation
+		}
+
+		routes[route.Name] = validated
+		handlers = append(handlers, route.Name)
+	}
+
+	// Component schemas may refer to each other, so the set grows while it is walked
+	schemas := map[string]interface{}{}
+	for len(schemas) < len(refs) {
+		for _, name := range sortedKeys(refs) {
+			if _, ok := schemas[name]; ok {
+				continue
+			}
+
+			schemas[name] = map[string]interface{}{}
+			if schema := table.Schemas[name]; schema != nil {
+				schemas[name] = validationSchema(schema, refs)
+			}
+		}
+	}
+
+	routesJSON, err := njsLiteral(routes)
+	if err != nil {
+		return "", fmt.Errorf("file '%s': failed to marshal njs routes: %w", table.FilePath, err)
+	}
+
+	schemasJSON, err := njsLiteral(schemas)
+	if err != nil {
+		return "", fmt.Errorf("file '%s': failed to marshal njs schemas: %w", table.FilePath, err)
+	}
+
+	data := struct {
+		FilePath string
+		Schemas  string
+		Routes   string
+		Handlers []string
+	}{
+		FilePath: table.FilePath,
+		Schemas:  schemasJSON,
+		Routes:   routesJSON,
+		Handlers: handlers,
+	}
+
+	return utils.ExecuteTemplate(utils.FormatRaw, "njs", njsValidationTemplate, data)
+}
+
+// validationSchema converts a schema into the plain object the njs validator walks. References to
+// component schemas are kept by name and collected in refs
+func validationSchema(schema *Schema, refs map[string]bool) interface{} {
+	if schema == nil {
+		return nil
+	}
+
+	if schema.Ref != nil {
+		name := strings.TrimPrefix(*schema.Ref, "#/components/schemas/")
+		refs[name] = true
+		return map[string]interface{}{"$ref": name}
+	}
+
+	result := map[string]interface{}{}
+	if schema.Type != nil {
+		result["type"] = *schema.Type
+	}
+	if schema.Nullable != nil && *schema.Nullable {
+		result["nullable"] = true
+	}
+
+	var required []string
+	for _, name := range schema.Required {
+		if name != nil {
+			required = append(required, *name)
+		}
+	}
+	if len(required) > 0 {
+		result["required"] = required
+	}
+
+	if len(schema.Properties) > 0 {
+		properties := map[string]interface{}{}
+		for name, property := range schema.Properties {
+			properties[name] = validationSchema(property, refs)
+		}
+		result["properties"] = properties
+	}
+
+	if schema.Items != nil {
+		result["items"] = validationSchema(schema.Items, refs)
+	}
+
+	for key, composed := range map[string][]*Schema{"allOf": schema.AllOf, "oneOf": schema.OneOf, "anyOf": schema.AnyOf} {
+		if len(composed) == 0 {
+			continue
+		}
+
+		schemas := make([]interface{}, 0, len(composed))
+		for _, item := range composed {
+			schemas = append(schemas, validationSchema(item, refs))
+		}
+		result[key] = schemas
+	}
+
+	return result
+}
+
+// isJSONMediaType reports whether bodies of a content type are parsed as JSON
+func isJSONMediaType(contentType string) bool {
+	mediaType := strings.ToLower(strings.TrimSpace(strings.SplitN(contentType, ";", 2)[0]))
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// njsLiteral marshals a value as JSON, which is also a valid JavaScript literal
+func njsLiteral(value interface{}) (string, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "    ")
+	if err := encoder.Encode(value); err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
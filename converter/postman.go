@@ -0,0 +1,330 @@
+package converter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/nimling/openapi-converter/postman"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// WritePostmanCollection renders a Postman Collection v2.1 of the spec. Operations are grouped in
+// a folder per tag, the first one when an operation has several, and untagged operations are
+// listed at the top level. Every request has its path variables, query parameters, headers and an
+// example body, and authenticates with the security scheme its requirement names. The server URL
+// is the baseUrl variable and the credentials are variables named after the security schemes
+func (n *OpenAPIConverter) WritePostmanCollection() (string, error) {
+	table, err := n.RouteTable()
+	if err != nil {
+		return "", err
+	}
+
+	collection := &postman.Collection{
+		Info: &postman.Info{
+			Name:        n.doc.Info.Title,
+			Description: n.doc.Info.Description,
+			Version:     n.doc.Info.Version,
+			Schema:      postman.SchemaURL,
+		},
+		Variable: []*postman.Variable{{Key: "baseUrl", Value: table.ServerURL, Type: "string"}},
+	}
+
+	variables := map[string]bool{"baseUrl": true}
+	folders := map[string]*postman.Item{}
+	var untagged []*postman.Item
+
+	generator := NewExampleGenerator(table.Schemas, 1)
+	for _, route := range table.Routes {
+		for _, op := range route.Operations {
+			item, credentials, err := postmanItem(table, route, op, generator)
+			if err != nil {
+				return "", fmt.Errorf("file '%s': %s %s: %w", table.FilePath, op.Method, route.Path, err)
+			}
+
+			for _, variable := range credentials {
+				if !variables[variable.Key] {
+					variables[variable.Key] = true
+					collection.Variable = append(collection.Variable, variable)
+				}
+			}
+
+			if len(op.Tags) == 0 {
+				untagged = append(untagged, item)
+				continue
+			}
+
+			folder, ok := folders[op.Tags[0]]
+			if !ok {
+				folder = &postman.Item{Name: op.Tags[0]}
+				folders[op.Tags[0]] = folder
+				collection.Item = append(collection.Item, folder)
+			}
+			folder.Item = append(folder.Item, item)
+		}
+	}
+	collection.Item = append(collection.Item, untagged...)
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(collection); err != nil {
+		return "", fmt.Errorf("file '%s': failed to marshal Postman collection: %w", table.FilePath, err)
+	}
+
+	return buf.String(), nil
+}
+
+// postmanItem builds the request of an operation together with the variables its credentials
+// refer to. Optional parameters without an example are listed disabled
+func postmanItem(table *RouteTable, route *Route, op *RouteOperation, generator *ExampleGenerator) (*postman.Item, []*postman.Variable, error) {
+	name := op.Summary
+	if name == "" {
+		name = op.OperationID
+	}
+	if name == "" {
+		name = op.Method + " " + route.Path
+	}
+
+	path := pathParameterPattern.ReplaceAllStringFunc(route.Path, func(param string) string {
+		return ":" + strings.Trim(param, "{}")
+	})
+	request := &postman.Request{
+		Method:      op.Method,
+		Description: op.Description,
+		Header:      []*postman.KeyValue{},
+		URL:         &postman.URL{Host: []string{"{{baseUrl}}"}},
+	}
+	for _, segment := range strings.Split(strings.TrimPrefix(path, "/"), "/") {
+		if segment != "" {
+			request.URL.Path = append(request.URL.Path, segment)
+		}
+	}
+
+	var cookies []string
+	for _, param := range route.OperationParams(op) {
+		value := param.Example
+		if value == nil {
+			value = generator.generate(param.Schema, param.Name)
+		}
+		disabled := !param.Required && param.Example == nil
+		description := schemaDoc(param.Schema)
+
+		switch param.In {
+		case "path":
+			request.URL.Variable = append(request.URL.Variable, &postman.KeyValue{Key: param.Name, Value: paramString(value), Description: description})
+		case "query":
+			values := []interface{}{value}
+			if items, ok := value.([]interface{}); ok && len(items) > 0 {
+				values = items
+			}
+			for _, item := range values {
+				request.URL.Query = append(request.URL.Query, &postman.KeyValue{Key: param.Name, Value: paramString(item), Description: description, Disabled: disabled})
+			}
+		case "header":
+			request.Header = append(request.Header, &postman.KeyValue{Key: param.Name, Value: paramString(value), Description: description, Disabled: disabled})
+		case "cookie":
+			if !disabled {
+				cookies = append(cookies, param.Name+"="+paramString(value))
+			}
+		}
+	}
+
+	credentials, err := postmanAuth(table, op, request, &cookies)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(cookies) > 0 {
+		request.Header = append(request.Header, &postman.KeyValue{Key: "Cookie", Value: strings.Join(cookies, "; ")})
+	}
+
+	if accept := responseContentTypes(op); len(accept) > 0 {
+		request.Header = append(request.Header, &postman.KeyValue{Key: "Accept", Value: strings.Join(accept, ", ")})
+	}
+
+	if op.Body != nil {
+		contentType := op.Body.ContentTypes[0]
+		for _, declared := range op.Body.ContentTypes {
+			if isJSONMediaType(declared) {
+				contentType = declared
+				break
+			}
+		}
+		request.Header = append(request.Header, &postman.KeyValue{Key: "Content-Type", Value: contentType})
+
+		value, ok := op.Body.Examples[contentType]
+		if !ok {
+			value = generator.Generate(op.Body.Schemas[contentType])
+		}
+		body, err := postmanBody(value, contentType)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to encode %s request body: %w", contentType, err)
+		}
+		request.Body = body
+	}
+
+	var query []string
+	for _, param := range request.URL.Query {
+		if !param.Disabled {
+			query = append(query, param.Key+"="+param.Value)
+		}
+	}
+	request.URL.Raw = "{{baseUrl}}" + path
+	if len(query) > 0 {
+		request.URL.Raw += "?" + strings.Join(query, "&")
+	}
+
+	item := &postman.Item{Name: name, Request: request, Response: []*postman.Response{}}
+	for _, code := range sortedKeys(op.Responses) {
+		response, err := postmanResponse(code, op.Responses[code])
+		if err != nil {
+			return nil, nil, err
+		}
+		if response != nil {
+			item.Response = append(item.Response, response)
+		}
+	}
+
+	return item, credentials, nil
+}
+
+// postmanAuth authenticates the request with the first alternative of the security requirements
+// of the operation. The first scheme Postman supports becomes the auth of the request; further API
+// keys are added as headers, query parameters or cookies
+func postmanAuth(table *RouteTable, op *RouteOperation, request *postman.Request, cookies *[]string) ([]*postman.Variable, error) {
+	if len(op.Security) == 0 || len(op.Security[0]) == 0 {
+		return nil, nil
+	}
+
+	var variables []*postman.Variable
+	for _, name := range sortedKeys(op.Security[0]) {
+		scheme := table.SecuritySchemes[name]
+		if scheme == nil {
+			return nil, fmt.Errorf("security scheme '%s' is not defined in components.securitySchemes", name)
+		}
+
+		variable := func(key string) string {
+			variables = append(variables, &postman.Variable{Key: key, Value: "", Type: "secret", Description: scheme.Description})
+			return "{{" + key + "}}"
+		}
+
+		switch {
+		case scheme.Type == "http" && strings.EqualFold(scheme.Scheme, "bearer") && request.Auth == nil:
+			request.Auth = &postman.Auth{Type: "bearer", Bearer: []*postman.AuthAttr{
+				{Key: "token", Value: variable(name), Type: "string"},
+			}}
+		case scheme.Type == "http" && strings.EqualFold(scheme.Scheme, "basic") && request.Auth == nil:
+			request.Auth = &postman.Auth{Type: "basic", Basic: []*postman.AuthAttr{
+				{Key: "username", Value: variable(name + "Username"), Type: "string"},
+				{Key: "password", Value: variable(name + "Password"), Type: "string"},
+			}}
+		case (scheme.Type == "oauth2" || scheme.Type == "openIdConnect") && request.Auth == nil:
+			request.Auth = &postman.Auth{Type: "oauth2", OAuth2: []*postman.AuthAttr{
+				{Key: "accessToken", Value: variable(name), Type: "string"},
+				{Key: "addTokenTo", Value: "header", Type: "string"},
+			}}
+		case scheme.Type == "apiKey" && scheme.In == "cookie":
+			*cookies = append(*cookies, scheme.Name+"="+variable(name))
+		case scheme.Type == "apiKey" && request.Auth == nil:
+			request.Auth = &postman.Auth{Type: "apikey", APIKey: []*postman.AuthAttr{
+				{Key: "key", Value: scheme.Name, Type: "string"},
+				{Key: "value", Value: variable(name), Type: "string"},
+				{Key: "in", Value: scheme.In, Type: "string"},
+			}}
+		case scheme.Type == "apiKey" && scheme.In == "query":
+			request.URL.Query = append(request.URL.Query, &postman.KeyValue{Key: scheme.Name, Value: variable(name)})
+		case scheme.Type == "apiKey":
+			request.Header = append(request.Header, &postman.KeyValue{Key: scheme.Name, Value: variable(name)})
+		}
+	}
+
+	return variables, nil
+}
+
+// postmanBody is a request body in the mode Postman edits it in: fields for url encoded forms, raw
+// otherwise. JSON is indented
+func postmanBody(value interface{}, contentType string) (*postman.Body, error) {
+	if fields, ok := value.(map[string]interface{}); ok && mediaType(contentType) == "application/x-www-form-urlencoded" {
+		body := &postman.Body{Mode: "urlencoded", URLEncoded: []*postman.KeyValue{}}
+		for _, name := range sortedKeys(fields) {
+			body.URLEncoded = append(body.URLEncoded, &postman.KeyValue{Key: name, Value: paramString(fields[name])})
+		}
+		return body, nil
+	}
+
+	raw, err := exampleBody(value, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	body := &postman.Body{Mode: "raw", Raw: raw}
+	if isJSONMediaType(contentType) {
+		body.Options = &postman.BodyOptions{Raw: &postman.RawOptions{Language: "json"}}
+	}
+
+	return body, nil
+}
+
+// postmanResponse is a saved response holding the example of a response, preferring a JSON
+// content type. Responses without an example are not saved
+func postmanResponse(code string, response *Response) (*postman.Response, error) {
+	if response == nil {
+		return nil, nil
+	}
+
+	contentTypes := sortedKeys(response.Content)
+	for i, contentType := range contentTypes {
+		if isJSONMediaType(contentType) {
+			contentTypes[0], contentTypes[i] = contentTypes[i], contentTypes[0]
+			break
+		}
+	}
+
+	for _, contentType := range contentTypes {
+		content := response.Content[contentType]
+		if content == nil || content.example() == nil {
+			continue
+		}
+
+		body, err := exampleBody(content.example(), contentType)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %s response example: %w", code, err)
+		}
+
+		saved := &postman.Response{
+			Name:   code,
+			Header: []*postman.KeyValue{{Key: "Content-Type", Value: contentType}},
+			Body:   body,
+		}
+		if response.Description != nil {
+			saved.Name = *response.Description
+		}
+		if status, err := strconv.Atoi(code); err == nil {
+			saved.Code = status
+			saved.Status = http.StatusText(status)
+		}
+
+		return saved, nil
+	}
+
+	return nil, nil
+}
+
+// exampleBody encodes an example body for a content type like requests send it, with JSON indented
+func exampleBody(value interface{}, contentType string) (string, error) {
+	data, err := requestBody(value, contentType)
+	if err != nil {
+		return "", err
+	}
+
+	if isJSONMediaType(contentType) {
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, data, "", "  "); err == nil {
+			return indented.String(), nil
+		}
+	}
+
+	return string(data), nil
+}
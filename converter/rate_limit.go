@@ -0,0 +1,184 @@
+package converter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var rateLimitRatePattern = regexp.MustCompile(`^[0-9]+r/[sm]$`)
+
+type nginxRateLimit struct {
+	Zone    string
+	Burst   int
+	NoDelay bool
+}
+
+// rateLimits resolves the x-rate-limit definitions applying to the location of a route and
+// registers their zones in the http context. The path or document limit counts every method.
+// Operation limits are added next to it and only count requests of their own method, through a
+// key mapped from $request_method that is empty, and so not counted, for the other methods
+func (n *OpenAPIConverter) rateLimits(table *RouteTable, route *Route) ([]*nginxRateLimit, error) {
+	var limits []*nginxRateLimit
+
+	if route.RateLimit != nil {
+		zone := n.specName()
+		if route.RateLimitScope == ScopePath {
+			zone = route.Name
+		}
+
+		limit, err := n.nginxRateLimit(table, route, nil, route.RateLimit, zone)
+		if err != nil {
+			return nil, err
+		}
+		limits = append(limits, limit)
+	}
+
+	for _, op := range route.Operations {
+		if op.RateLimit == nil {
+			continue
+		}
+
+		zone := route.Name + "_" + strings.ToLower(op.Method)
+		if op.OperationID != "" {
+			zone = invalidIdentifierChars.ReplaceAllString(op.OperationID, "_")
+		}
+
+		limit, err := n.nginxRateLimit(table, route, op, op.RateLimit, zone)
+		if err != nil {
+			return nil, err
+		}
+		limits = append(limits, limit)
+	}
+
+	return limits, nil
+}
+
+// nginxRateLimit registers the zone of one limit. op is nil for limits covering the whole location
+func (n *OpenAPIConverter) nginxRateLimit(table *RouteTable, route *Route, op *RouteOperation, limit *RateLimit, zone string) (*nginxRateLimit, error) {
+	path := route.Path
+	if limit.Zone != "" {
+		if invalidIdentifierChars.MatchString(limit.Zone) {
+			return nil, fmt.Errorf("file '%s': path '%s': x-rate-limit zone '%s' may only contain letters, digits and underscores", n.filePath, path, limit.Zone)
+		}
+		zone = limit.Zone
+	}
+
+	if !rateLimitRatePattern.MatchString(limit.Rate) {
+		return nil, fmt.Errorf("file '%s': path '%s': x-rate-limit rate '%s' must look like 10r/s or 100r/m", n.filePath, path, limit.Rate)
+	}
+
+	key, err := rateLimitKey(limit.Key)
+	if err != nil {
+		return nil, fmt.Errorf("file '%s': path '%s': %w", n.filePath, path, err)
+	}
+
+	source := fmt.Sprintf("'%s' path '%s'", n.filePath, path)
+	var keyMaps []*nginxMap
+	if limit.Key == "subject" {
+		subject := &nginxMap{
+			Source:   source,
+			Input:    rateLimitCredential(table, op),
+			Variable: "$rate_limit_subject_" + zone,
+			Entries:  []nginxMapEntry{{Key: `""`, Value: "$binary_remote_addr"}},
+		}
+		subject.Default = subject.Input
+		keyMaps = append(keyMaps, subject)
+		key = subject.Variable
+	}
+	if op != nil {
+		methodKey := &nginxMap{
+			Source:   source,
+			Input:    "$request_method",
+			Variable: "$rate_limit_key_" + zone,
+			Default:  `""`,
+			Entries:  []nginxMapEntry{{Key: op.Method, Value: key}},
+		}
+		keyMaps = append(keyMaps, methodKey)
+		key = methodKey.Variable
+	}
+
+	size := limit.Size
+	if size == "" {
+		size = "10m"
+	}
+
+	// The zone is registered before its key maps, so a zone name taken by another spec is reported
+	// as such rather than as a clash of the maps named after it
+	err = n.httpContext().addRateLimitZone(&rateLimitZone{
+		Name:   zone,
+		Key:    key,
+		Size:   size,
+		Rate:   limit.Rate,
+		Spec:   n.filePath,
+		Source: source,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, keyMap := range keyMaps {
+		if err := n.httpContext().addMap(keyMap); err != nil {
+			return nil, err
+		}
+	}
+
+	return &nginxRateLimit{
+		Zone:    zone,
+		Burst:   limit.Burst,
+		NoDelay: limit.NoDelay,
+	}, nil
+}
+
+// rateLimitCredential returns the variable holding the credential of an operation for the subject
+// key: the API key of its security requirement, or the Authorization header for other schemes.
+// Requests without the credential are counted by client address instead, since Nginx does not
+// count requests with an empty key
+func rateLimitCredential(table *RouteTable, op *RouteOperation) string {
+	if op == nil {
+		return "$http_authorization"
+	}
+
+	requirement, err := op.Requirement()
+	if err != nil {
+		return "$http_authorization"
+	}
+
+	for _, name := range sortedKeys(requirement) {
+		scheme := table.SecuritySchemes[name]
+		if scheme == nil || scheme.Type != "apiKey" {
+			continue
+		}
+
+		variable := strings.ToLower(strings.ReplaceAll(scheme.Name, "-", "_"))
+		switch scheme.In {
+		case "header":
+			return "$http_" + variable
+		case "query":
+			return "$arg_" + scheme.Name
+		case "cookie":
+			return "$cookie_" + scheme.Name
+		}
+	}
+
+	return "$http_authorization"
+}
+
+// rateLimitKey maps the x-rate-limit key to the Nginx variable requests are counted by
+func rateLimitKey(key string) (string, error) {
+	switch {
+	case key == "" || key == "ip":
+		return "$binary_remote_addr", nil
+	case key == "subject":
+		return "$http_authorization", nil
+	case strings.HasPrefix(key, "header:"):
+		header := strings.TrimSpace(strings.TrimPrefix(key, "header:"))
+		if header == "" {
+			return "", fmt.Errorf("x-rate-limit key '%s' is missing the header name", key)
+		}
+		return "$http_" + strings.ToLower(strings.ReplaceAll(header, "-", "_")), nil
+	case strings.HasPrefix(key, "$"):
+		return key, nil
+	}
+
+	return "", fmt.Errorf("x-rate-limit key '%s' must be ip, subject, header:<name> or an Nginx variable", key)
+}
@@ -400,7 +400,7 @@ func (n *OpenAPIConverter) resolveComponentRefs(components *Components) error {
 				filePath := resolveRefPath(n.filePath, *comp.Ref)
 				res, err := loadExternalRef[SecurityScheme](filePath)
 				if err != nil {
-					return fmt.Errorf("failed to load external ref: %s, error: %w", err)
+					return fmt.Errorf("failed to load external ref: %s, error: %w", filePath, err)
 				}
 
 				components.PutRegister("securitySchemes", filePath)
@@ -415,7 +415,7 @@ func (n *OpenAPIConverter) resolveComponentRefs(components *Components) error {
 				filePath := resolveRefPath(n.filePath, *comp.Ref)
 				res, err := loadExternalRef[Parameter](filePath)
 				if err != nil {
-					return fmt.Errorf("failed to load external ref: %s, error: %w", err)
+					return fmt.Errorf("failed to load external ref: %s, error: %w", filePath, err)
 				}
 
 				components.PutRegister("parameters", filePath)
@@ -431,7 +431,7 @@ func (n *OpenAPIConverter) resolveComponentRefs(components *Components) error {
 				relPath = resolveRefPath(n.filePath, *comp.Ref)
 				res, err := loadExternalRef[Schema](relPath)
 				if err != nil {
-					return fmt.Errorf("failed to load external ref: %s, error: %w", err)
+					return fmt.Errorf("failed to load external ref: %s, error: %w", relPath, err)
 				}
 
 				components.PutRegister("schemas", relPath)
@@ -0,0 +1,490 @@
+package converter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ExtensionScope tells at which level of the document an extension was defined
+type ExtensionScope string
+
+const (
+	ScopeDocument  ExtensionScope = "document"
+	ScopePath      ExtensionScope = "path"
+	ScopeOperation ExtensionScope = "operation"
+)
+
+// RouteTable is the gateway-neutral routing model of a spec. It is computed once from the
+// resolved document and every gateway output is rendered from it
+type RouteTable struct {
+	Spec            string                     `json:"spec"`
+	FilePath        string                     `json:"filePath"`
+	Prefix          string                     `json:"prefix,omitempty"`
+	ServerURL       string                     `json:"serverUrl"`
+	Upstream        *RouteUpstream             `json:"upstream"`
+	Settings        *NginxSettings             `json:"settings"`
+	CORS            *CORS                      `json:"cors,omitempty"`
+	RateLimit       *RateLimit                 `json:"rateLimit,omitempty"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+	Auth            *Auth                      `json:"auth,omitempty"`
+	Schemas         map[string]*Schema         `json:"-"`
+	Routes          []*Route                   `json:"routes"`
+
+	compileMatchers sync.Once
+	matchers        []routeMatcher
+}
+
+// RouteUpstream is the set of servers the routes are proxied to
+type RouteUpstream struct {
+	Name      string         `json:"name"`
+	Scheme    string         `json:"scheme"`
+	Host      string         `json:"host"`
+	BasePath  string         `json:"basePath,omitempty"`
+	Balancing string         `json:"balancing,omitempty"`
+	Keepalive int            `json:"keepalive,omitempty"`
+	Servers   []*RouteServer `json:"servers"`
+}
+
+// RouteServer is one upstream server
+type RouteServer struct {
+	URL         string `json:"url"`
+	Scheme      string `json:"scheme"`
+	Host        string `json:"host"`
+	Port        string `json:"port,omitempty"`
+	Weight      int    `json:"weight,omitempty"`
+	MaxFails    int    `json:"maxFails,omitempty"`
+	FailTimeout string `json:"failTimeout,omitempty"`
+	Backup      bool   `json:"backup,omitempty"`
+}
+
+// Route is one path of the spec with everything a gateway needs to expose it
+type Route struct {
+	Name           string            `json:"name"`
+	Path           string            `json:"path"`
+	Match          RouteMatch        `json:"match"`
+	Methods        []string          `json:"methods"`
+	Params         []*RouteParam     `json:"params,omitempty"`
+	Operations     []*RouteOperation `json:"operations"`
+	Settings       *NginxSettings    `json:"settings"`
+	CORS           *CORS             `json:"cors,omitempty"`
+	CORSScope      ExtensionScope    `json:"corsScope,omitempty"`
+	RateLimit      *RateLimit        `json:"rateLimit,omitempty"`
+	RateLimitScope ExtensionScope    `json:"rateLimitScope,omitempty"`
+}
+
+// RouteMatch describes which request paths a route matches. Paths match exactly, like the Nginx
+// exact and regex locations, with every parameter of a templated path a single segment
+type RouteMatch struct {
+	Templated bool     `json:"templated"`
+	Regex     string   `json:"regex"`
+	Params    []string `json:"params,omitempty"`
+}
+
+// RouteParam is a parameter declared for a path or an operation
+type RouteParam struct {
+	Name     string      `json:"name"`
+	In       string      `json:"in"`
+	Required bool        `json:"required,omitempty"`
+	Schema   *Schema     `json:"-"`
+	Example  interface{} `json:"-"`
+}
+
+// RouteBody is the request body an operation accepts, with the schema and the example of every
+// content type. The example is the example of the content or its first named example
+type RouteBody struct {
+	Required     bool                   `json:"required,omitempty"`
+	ContentTypes []string               `json:"contentTypes"`
+	Schemas      map[string]*Schema     `json:"-"`
+	Examples     map[string]interface{} `json:"-"`
+}
+
+// RouteOperation is one method of a route with its effective security requirements
+type RouteOperation struct {
+	Method      string                `json:"method"`
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Params      []*RouteParam         `json:"params,omitempty"`
+	Body        *RouteBody            `json:"body,omitempty"`
+	Security    []map[string][]string `json:"security,omitempty"`
+	RateLimit   *RateLimit            `json:"rateLimit,omitempty"`
+	Nginx       *NginxSettings        `json:"nginx,omitempty"`
+	Responses   map[string]*Response  `json:"-"`
+}
+
+// RouteTable validates the document and computes its routes in path order
+func (n *OpenAPIConverter) RouteTable() (*RouteTable, error) {
+	if err := n.ValidateDocument(); err != nil {
+		return nil, err
+	}
+
+	upstream, err := n.routeUpstream()
+	if err != nil {
+		return nil, err
+	}
+
+	settings := defaultNginxSettings()
+	settings.apply(n.doc.Nginx)
+
+	table := &RouteTable{
+		Spec:      n.specName(),
+		FilePath:  n.filePath,
+		Prefix:    n.strippedPrefix(),
+		ServerURL: strings.TrimSuffix(n.expandServerURL(n.doc.Servers[0]), "/"),
+		Upstream:  upstream,
+		Settings:  settings,
+		CORS:      n.doc.CORS,
+		RateLimit: n.doc.RateLimit,
+		Auth:      n.doc.Auth,
+	}
+
+	if n.doc.Components != nil {
+		table.SecuritySchemes = n.doc.Components.SecuritySchemes
+		table.Schemas = n.doc.Components.Schemas
+	}
+
+	for _, path := range n.sortedPaths() {
+		route, err := n.route(path, n.doc.Paths[path])
+		if err != nil {
+			return nil, err
+		}
+		table.Routes = append(table.Routes, route)
+	}
+
+	return table, nil
+}
+
+func (n *OpenAPIConverter) route(path string, pathItem *PathItem) (*Route, error) {
+	settings, err := n.nginxSettings(path, pathItem)
+	if err != nil {
+		return nil, err
+	}
+
+	route := &Route{
+		Name: n.specName() + "_" + pathSlug(path),
+		Path: path,
+		Match: RouteMatch{
+			Templated: isTemplatedPath(path),
+			Regex:     routeMatchRegex(path),
+			Params:    pathParameterNames(path),
+		},
+		Params:   n.routeParams(pathItem.Parameters),
+		Settings: settings,
+	}
+
+	for _, name := range route.Match.Params {
+		if findRouteParam(route.Params, name, "path") == nil {
+			route.Params = append(route.Params, &RouteParam{Name: name, In: "path", Required: true})
+		}
+	}
+
+	switch {
+	case pathItem.CORS != nil:
+		route.CORS, route.CORSScope = pathItem.CORS, ScopePath
+	case n.doc.CORS != nil:
+		route.CORS, route.CORSScope = n.doc.CORS, ScopeDocument
+	}
+	if route.CORS != nil && len(route.CORS.Origins) == 0 {
+		return nil, fmt.Errorf("file '%s': path '%s': x-cors must list at least one origin", n.filePath, path)
+	}
+
+	switch {
+	case pathItem.RateLimit != nil:
+		route.RateLimit, route.RateLimitScope = pathItem.RateLimit, ScopePath
+	case n.doc.RateLimit != nil:
+		route.RateLimit, route.RateLimitScope = n.doc.RateLimit, ScopeDocument
+	}
+
+	for _, op := range pathItem.SortedOperations() {
+		operation := &RouteOperation{
+			Method:    op.Method,
+			Params:    n.routeParams(op.Parameters),
+			RateLimit: op.RateLimit,
+			Nginx:     op.Nginx,
+			Responses: op.Responses,
+		}
+		if op.OperationID != nil {
+			operation.OperationID = *op.OperationID
+		}
+		if op.Summary != nil {
+			operation.Summary = *op.Summary
+		}
+		if op.Description != nil {
+			operation.Description = *op.Description
+		}
+		if op.Tags != nil {
+			operation.Tags = *op.Tags
+		}
+		if op.RequestBody != nil && len(op.RequestBody.Content) > 0 {
+			operation.Body = &RouteBody{
+				Required: op.RequestBody.Required != nil && *op.RequestBody.Required,
+				Schemas:  map[string]*Schema{},
+				Examples: map[string]interface{}{},
+			}
+			for _, contentType := range sortedKeys(op.RequestBody.Content) {
+				operation.Body.ContentTypes = append(operation.Body.ContentTypes, contentType)
+				content := op.RequestBody.Content[contentType]
+				if content == nil {
+					continue
+				}
+				if content.Schema != nil {
+					operation.Body.Schemas[contentType] = content.Schema
+				}
+				if example := content.example(); example != nil {
+					operation.Body.Examples[contentType] = example
+				}
+			}
+		}
+
+		if op.Security != nil {
+			operation.Security = op.Security
+		} else if n.doc.Security != nil {
+			operation.Security = *n.doc.Security
+		}
+
+		route.Methods = append(route.Methods, op.Method)
+		route.Operations = append(route.Operations, operation)
+	}
+
+	return route, nil
+}
+
+// routeParams lists the declared parameters, following references into components.parameters
+func (n *OpenAPIConverter) routeParams(parameters []*Parameter) []*RouteParam {
+	var params []*RouteParam
+	for _, parameter := range parameters {
+		if parameter == nil {
+			continue
+		}
+
+		if parameter.Ref != nil && n.doc.Components != nil {
+			name := (*parameter.Ref)[strings.LastIndex(*parameter.Ref, "/")+1:]
+			if resolved, ok := n.doc.Components.Parameters[name]; ok && resolved != nil {
+				parameter = resolved
+			}
+		}
+
+		if parameter.Name == "" {
+			continue
+		}
+
+		params = append(params, &RouteParam{
+			Name:     parameter.Name,
+			In:       parameter.In,
+			Required: parameter.Required || parameter.In == "path",
+			Schema:   parameter.Schema,
+			Example:  parameter.Example,
+		})
+	}
+
+	return params
+}
+
+// OperationParams lists the parameters of an operation together with the path item parameters it
+// does not override. An operation parameter overrides one with the same name and location
+func (r *Route) OperationParams(op *RouteOperation) []*RouteParam {
+	params := append([]*RouteParam{}, op.Params...)
+	for _, param := range r.Params {
+		if findRouteParam(op.Params, param.Name, param.In) == nil {
+			params = append(params, param)
+		}
+	}
+
+	return params
+}
+
+func findRouteParam(params []*RouteParam, name string, in string) *RouteParam {
+	for _, param := range params {
+		if param.Name == name && param.In == in {
+			return param
+		}
+	}
+
+	return nil
+}
+
+// Requirement returns the security requirement a gateway has to enforce for the operation.
+// Gateways check every scheme they are configured with, so only a single requirement can be
+// expressed; an empty alternative makes authentication optional and nothing is enforced
+func (op *RouteOperation) Requirement() (map[string][]string, error) {
+	for _, requirement := range op.Security {
+		if len(requirement) == 0 {
+			return nil, nil
+		}
+	}
+
+	switch len(op.Security) {
+	case 0:
+		return nil, nil
+	case 1:
+		return op.Security[0], nil
+	}
+
+	return nil, fmt.Errorf("alternative security requirements cannot be enforced by a gateway that checks every scheme it is configured with")
+}
+
+// Response returns the response declared for a status code, falling back to the range of the
+// status (4XX) and to the default response
+func (op *RouteOperation) Response(status int) *Response {
+	code := strconv.Itoa(status)
+	for _, key := range []string{code, code[:1] + "XX", code[:1] + "xx", "default"} {
+		if response, ok := op.Responses[key]; ok {
+			if response == nil {
+				return &Response{}
+			}
+			return response
+		}
+	}
+
+	return nil
+}
+
+// Summaries lists the operation summaries prefixed with their method
+func (r *Route) Summaries() []string {
+	var summaries []string
+	for _, op := range r.Operations {
+		if op.Summary != "" {
+			summaries = append(summaries, fmt.Sprintf("%s: %s", op.Method, op.Summary))
+		}
+	}
+
+	return summaries
+}
+
+// Descriptions lists the operation descriptions prefixed with their method
+func (r *Route) Descriptions() []string {
+	var descriptions []string
+	for _, op := range r.Operations {
+		if op.Description != "" {
+			descriptions = append(descriptions, fmt.Sprintf("%s: %s", op.Method, op.Description))
+		}
+	}
+
+	return descriptions
+}
+
+// EffectiveCORS returns the x-cors definition of the route with the defaults filled in: the
+// route methods plus OPTIONS and the default request headers
+func (r *Route) EffectiveCORS() *CORS {
+	if r.CORS == nil {
+		return nil
+	}
+
+	resolved := *r.CORS
+	if len(resolved.Methods) == 0 {
+		resolved.Methods = r.Methods
+	}
+	if !containsString(resolved.Methods, "OPTIONS") {
+		resolved.Methods = append(append([]string{}, resolved.Methods...), "OPTIONS")
+	}
+	if len(resolved.Headers) == 0 {
+		resolved.Headers = defaultCORSHeaders
+	}
+
+	return &resolved
+}
+
+// AllowedMethods are the route methods plus OPTIONS when preflight requests have to pass
+func (r *Route) AllowedMethods() []string {
+	if r.CORS == nil {
+		return r.Methods
+	}
+
+	return append(append([]string{}, r.Methods...), "OPTIONS")
+}
+
+// OrderedRoutes puts literal paths before templated ones, matching the location Nginx would
+// select, where an exact location wins over regex locations, for gateways that use the first
+// matching route. /users/me is therefore tried before /users/{id}
+func (t *RouteTable) OrderedRoutes() []*Route {
+	ordered := append([]*Route{}, t.Routes...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return !ordered[i].Match.Templated && ordered[j].Match.Templated
+	})
+
+	return ordered
+}
+
+// PathRewrite returns the regex rewrite gateways without Nginx's proxy_pass semantics need: the
+// stripped prefix is removed and the base path of the upstream servers is put in front.
+// ok is false when the path is forwarded unchanged
+func (t *RouteTable) PathRewrite() (pattern string, replacement string, ok bool) {
+	basePath := strings.TrimSuffix(t.Upstream.BasePath, "/")
+	if t.Prefix == "" && basePath == "" {
+		return "", "", false
+	}
+
+	return "^" + regexp.QuoteMeta(t.Prefix) + "/(.*)", basePath + `/\1`, true
+}
+
+// SecurityScheme looks up a scheme referenced by a security requirement
+func (t *RouteTable) SecurityScheme(name string) (*SecurityScheme, error) {
+	scheme, ok := t.SecuritySchemes[name]
+	if !ok || scheme == nil {
+		return nil, fmt.Errorf("security scheme '%s' is not defined in components.securitySchemes", name)
+	}
+
+	return scheme, nil
+}
+
+// routePrefix is the common prefix stripped before requests are proxied
+func (n *OpenAPIConverter) routePrefix() string {
+	return strings.TrimSuffix(n.CommonPrefix, "/")
+}
+
+// strippedPrefix is the prefix the Nginx rewrite actually removes. The rewrite anchors the prefix
+// at the start of the URI, so a prefix without a leading slash never matches and nothing is stripped
+func (n *OpenAPIConverter) strippedPrefix() string {
+	prefix := n.routePrefix()
+	if !strings.HasPrefix(prefix, "/") {
+		return ""
+	}
+
+	return prefix
+}
+
+// pathSlug turns a path into an identifier usable in zone, variable and router names
+func pathSlug(path string) string {
+	return strings.Trim(invalidIdentifierChars.ReplaceAllString(path, "_"), "_")
+}
+
+// pathParameterNames lists the template parameters of a path in order
+func pathParameterNames(path string) []string {
+	var names []string
+	for _, match := range pathParameterPattern.FindAllString(path, -1) {
+		names = append(names, match[1:len(match)-1])
+	}
+
+	return names
+}
+
+// routeMatchRegex returns a regex matching the same requests as the Nginx location of a path:
+// an anchored match of the literal path or of the template with one segment per parameter
+func routeMatchRegex(path string) string {
+	return pathTemplateRegex(path)
+}
+
+// WriteRouteTable renders the route table as indented JSON for inspection
+func (n *OpenAPIConverter) WriteRouteTable() (string, error) {
+	table, err := n.RouteTable()
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(table); err != nil {
+		return "", fmt.Errorf("file '%s': failed to marshal route table: %w", n.filePath, err)
+	}
+
+	return buf.String(), nil
+}
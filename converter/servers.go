@@ -0,0 +1,163 @@
+package converter
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var serverVariablePattern = regexp.MustCompile(`\{([^{}]+)\}`)
+
+type serverURL struct {
+	Scheme string
+	Host   string
+	Port   string
+	Path   string
+}
+
+// PortOrDefault returns the explicit port or the default port of the scheme
+func (u serverURL) PortOrDefault() string {
+	if u.Port != "" {
+		return u.Port
+	}
+
+	if u.Scheme == "https" {
+		return "443"
+	}
+
+	return "80"
+}
+
+// Address returns host:port, defaulting the port from the scheme
+func (u serverURL) Address() string {
+	return net.JoinHostPort(u.Host, u.PortOrDefault())
+}
+
+// Origin returns scheme://host with the port only when it was given explicitly
+func (u serverURL) Origin() string {
+	if u.Port == "" {
+		return u.Scheme + "://" + u.Host
+	}
+
+	return u.Scheme + "://" + net.JoinHostPort(u.Host, u.Port)
+}
+
+// SetServerVariables overrides the default of every server variable with a matching name.
+// Values are checked against the variable's enum; names no server defines are ignored so the
+// same overrides can be passed to a batch of specs
+func (n *OpenAPIConverter) SetServerVariables(values map[string]string) error {
+	for name, value := range values {
+		for _, server := range n.doc.Servers {
+			variable, ok := server.Variables[name]
+			if !ok || variable == nil {
+				continue
+			}
+
+			if len(variable.Enum) > 0 && !containsString(variable.Enum, value) {
+				return fmt.Errorf("file '%s': value '%s' for server variable '%s' must be one of: %s",
+					n.filePath, value, name, strings.Join(variable.Enum, ", "))
+			}
+
+			variable.Default = value
+		}
+	}
+
+	return nil
+}
+
+func (n *OpenAPIConverter) validateServerVariables() error {
+	for i, server := range n.doc.Servers {
+		for _, match := range serverVariablePattern.FindAllStringSubmatch(server.URL, -1) {
+			variable, ok := server.Variables[match[1]]
+			if !ok || variable == nil {
+				return fmt.Errorf("file '%s': servers[%d].url uses undefined variable '%s'", n.filePath, i, match[1])
+			}
+		}
+
+		for name, variable := range server.Variables {
+			if variable == nil {
+				return fmt.Errorf("file '%s': servers[%d].variables.%s must define a default", n.filePath, i, name)
+			}
+
+			if len(variable.Enum) > 0 && !containsString(variable.Enum, variable.Default) {
+				return fmt.Errorf("file '%s': default '%s' of servers[%d].variables.%s is not part of its enum",
+					n.filePath, variable.Default, i, name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// expandServerURL substitutes the server variables in the URL with their defaults, or with
+// envsubst placeholders such as ${REGION} when ServerVariablePlaceholders is enabled
+func (n *OpenAPIConverter) expandServerURL(server *Server) string {
+	return serverVariablePattern.ReplaceAllStringFunc(server.URL, func(match string) string {
+		name := match[1 : len(match)-1]
+		if n.ServerVariablePlaceholders {
+			return "${" + serverVariableEnvName(name) + "}"
+		}
+
+		if variable, ok := server.Variables[name]; ok && variable != nil {
+			return variable.Default
+		}
+
+		return match
+	})
+}
+
+// serverVariableEnvName converts a variable name such as basePath into BASE_PATH
+func serverVariableEnvName(name string) string {
+	var builder strings.Builder
+	for i, r := range name {
+		switch {
+		case unicode.IsUpper(r) && i > 0:
+			builder.WriteRune('_')
+			builder.WriteRune(r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			builder.WriteRune(unicode.ToUpper(r))
+		default:
+			builder.WriteRune('_')
+		}
+	}
+
+	return builder.String()
+}
+
+// parseServerURL splits an absolute server URL without validating the host, since expanded
+// URLs may still contain envsubst placeholders
+func parseServerURL(raw string) (serverURL, error) {
+	scheme, rest, ok := strings.Cut(raw, "://")
+	if !ok || (scheme != "http" && scheme != "https") {
+		return serverURL{}, fmt.Errorf("server '%s' is not an absolute http(s) URL", raw)
+	}
+
+	authority, path, _ := strings.Cut(rest, "/")
+	result := serverURL{Scheme: scheme, Host: authority}
+	if path != "" {
+		result.Path = "/" + strings.TrimSuffix(path, "/")
+	}
+
+	if host, port, err := net.SplitHostPort(authority); err == nil {
+		result.Host = host
+		result.Port = port
+	}
+
+	if result.Host == "" {
+		return serverURL{}, fmt.Errorf("server '%s' has no host", raw)
+	}
+
+	return result, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
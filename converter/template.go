@@ -1,41 +1,53 @@
 package converter
 
-const locationTemplate = `{{range .Summaries}}# Summary: {{.}}
-{{end}}{{range .Descriptions}}# Description: {{.}}{{end}}
-location {{.Path}} {
+const locationTemplate = `{{range .Summaries}}# Summary: {{comment .}}
+{{end}}{{range .Descriptions}}# Description: {{comment .}}
+{{end}}location {{.Location}} {
 {{if gt (len .Methods) 0}}    limit_except {{.AllowMethods}} {
         deny all;
     }
-{{end}}{{if gt (len .GlobalClaims) 0}}    # Global claims required for all methods
-    set_by_lua_block $missing_claims {
-        local claims = ngx.var.user_claims or ""
-        local missing = 0{{range .GlobalClaims}}
-        if not string.match(claims, "{{.}}") then
-            missing = 1
-        end{{end}}
-        return missing
-    }
-    if ($missing_claims) {
-        return 403 "Missing required claims";
-    }
-{{end}}{{range $method, $claims := .MethodClaims}}    # Claims specific to {{$method}}
-    set_by_lua_block $method_missing_claims {
-        local claims = ngx.var.user_claims or ""
-        if ngx.var.request_method ~= "{{$method}}" then
-            return 0
-        end{{range $claims}}
-        if not string.match(claims, "{{.}}") then
-            return 1
-        end{{end}}
-        return 0
-    }
-    if ($method_missing_claims) {
-        return 403 "Missing required claims for {{$method}}";
+{{end}}{{with .RateLimits}}
+    # Rate limiting
+{{range .}}    limit_req zone={{.Zone}}{{if gt .Burst 0}} burst={{.Burst}}{{end}}{{if .NoDelay}} nodelay{{end}};
+{{end}}    limit_req_status 429;
+{{end}}{{with .CORS}}
+    # CORS preflight answered at the gateway
+    if ($request_method = OPTIONS) {
+        add_header Access-Control-Allow-Origin {{.OriginVariable}} always;
+        add_header Access-Control-Allow-Methods {{quote .Methods}} always;
+        add_header Access-Control-Allow-Headers {{quote .Headers}} always;
+{{if .Credentials}}        add_header Access-Control-Allow-Credentials "true" always;
+{{end}}{{if gt .MaxAge 0}}        add_header Access-Control-Max-Age {{.MaxAge}} always;
+{{end}}        add_header Vary Origin always;
+        return 204;
     }
-{{end}}
-    rewrite ^{{.Prefix}}/(.*) /$1 break;
-    proxy_pass {{.ServerURL}};
+{{end}}{{with .Auth}}
+    # Authentication from the security requirements
+{{range .Rejected}}    # rejected, cannot be enforced: {{comment .}}
+{{end}}{{if .RejectVariable}}    if ({{.RejectVariable}} = 1) {
+        return 401;
+    }
+{{else if .Rejected}}    return 401;
+{{end}}{{range .APIKeys}}    if ({{.Variable}} = 0) {
+        return 401;
+    }
+{{end}}{{with .Basic}}    auth_basic {{.Realm}};
+    auth_basic_user_file {{.UserFile}};
+{{end}}{{with .Bearer}}    auth_request {{.Location}};
+{{end}}{{end}}{{with .Validation}}
+    # Request validation with njs, valid requests continue in {{.Location}}
+{{if $.Settings.ClientMaxBodySize}}    client_max_body_size {{$.Settings.ClientMaxBodySize}};
+{{end}}{{range $.Settings.BodyDirectives}}    {{.}}
+{{end}}    js_content {{.Handler}};
+}
 
+location {{.Location}} {
+{{- end}}
+{{range .Rewrites}}    rewrite {{.Pattern}} {{.Replacement}} break;
+{{end}}    proxy_pass {{.ProxyPass}};
+{{if .SSLName}}    proxy_ssl_server_name on;
+    proxy_ssl_name {{.SSLName}};
+{{end}}
     # Basic proxy headers
     proxy_set_header Host $host;
     proxy_set_header X-Real-IP $remote_addr;
@@ -49,16 +61,23 @@ location {{.Path}} {
     proxy_set_header X-Client-IP $http_x_client_ip;
 
     # Timeouts
-    proxy_connect_timeout 60s;
-    proxy_send_timeout 60s;
-    proxy_read_timeout 60s;
-
+    proxy_connect_timeout {{.Settings.ConnectTimeout}};
+    proxy_send_timeout {{.Settings.SendTimeout}};
+    proxy_read_timeout {{.Settings.ReadTimeout}};
+{{if .Settings.ClientMaxBodySize}}
+    # Request body
+    client_max_body_size {{.Settings.ClientMaxBodySize}};
+{{end}}
     # Buffer settings
-    proxy_buffering on;
-    proxy_buffer_size 16k;
-    proxy_buffers 8 16k;
-    proxy_busy_buffers_size 32k;
-
+    proxy_buffering {{.Settings.BufferingFlag}};
+    proxy_buffer_size {{.Settings.BufferSize}};
+    proxy_buffers {{.Settings.Buffers}};
+    proxy_busy_buffers_size {{.Settings.BusyBuffersSize}};
+{{if .Settings.ProxyCache}}
+    # Response caching
+    proxy_cache {{.Settings.ProxyCache}};{{if .Settings.ProxyCacheValid}}
+    proxy_cache_valid {{.Settings.ProxyCacheValid}};{{end}}
+{{end}}
     # HTTP/1.1 support
     proxy_http_version 1.1;
     proxy_set_header Connection "";
@@ -68,23 +87,149 @@ location {{.Path}} {
     proxy_next_upstream error timeout http_500 http_502 http_503 http_504;
 
     # Security headers
-    add_header Cache-Control "private, no-cache, no-store, must-revalidate";
-    add_header Pragma no-cache;
-    add_header X-Content-Type-Options nosniff;
+    add_header Cache-Control {{quote .Settings.CacheControl}};
+{{if .Settings.NoCache}}    add_header Pragma no-cache;
+{{end}}    add_header X-Content-Type-Options nosniff;
     add_header X-XSS-Protection "1; mode=block";
     add_header Strict-Transport-Security "max-age=31536000; includeSubDomains" always;
-
+{{if .Settings.Headers}}
+    # Headers from x-nginx
+{{range .Settings.SortedHeaders}}    add_header {{.Name}} {{quote .Value}};
+{{end}}{{end}}
     # Remove Server header
     proxy_hide_header Server;
     proxy_hide_header X-Powered-By;
 
-    # CORS headers (if needed)
-    # add_header Access-Control-Allow-Origin "*";
-    # add_header Access-Control-Allow-Methods "GET, POST, PUT, DELETE, OPTIONS";
-    # add_header Access-Control-Allow-Headers "Authorization, Content-Type";
-    # add_header Access-Control-Allow-Credentials "true";
+{{with .CORS}}
+    # CORS headers
+    add_header Access-Control-Allow-Origin {{.OriginVariable}} always;
+{{if .Credentials}}    add_header Access-Control-Allow-Credentials "true" always;
+{{end}}{{if .ExposeHeaders}}    add_header Access-Control-Expose-Headers {{quote .ExposeHeaders}} always;
+{{end}}    add_header Vary Origin always;
+{{end}}{{if .Settings.Directives}}
+    # Directives from x-nginx
+{{range .Settings.Directives}}    {{.}}
+{{end}}{{end}}}`
+
+const authLocationTemplate = `# Token introspection for {{comment .Path}}
+location = {{.Location}} {
+    internal;
+{{if .Methods}}    if ($request_method !~ ^({{.Methods}})$) {
+        return 204;
+    }
+{{end}}    proxy_pass {{.IntrospectionURL}};
+{{if .TLS}}    proxy_ssl_server_name on;
+{{end}}    proxy_pass_request_body off;
+    proxy_set_header Content-Length "";
+    proxy_set_header Authorization $http_authorization;
+    proxy_set_header X-Original-URI $request_uri;
+    proxy_set_header X-Original-Method $request_method;
+{{if .Scopes}}    proxy_set_header X-Required-Scopes {{.Scopes}};
+{{end}}}`
+
+const upstreamTemplate = `upstream {{.Name}} {
+{{if .Balancing}}    {{.Balancing}};
+{{end}}{{range .Servers}}    server {{.Address}}{{if .Parameters}} {{.Parameters}}{{end}};
+{{end}}{{if gt .Keepalive 0}}    keepalive {{.Keepalive}};
+{{end}}}`
+
+const httpContextTemplate = `{{if .Imports}}# Request validation modules
+{{range .Imports}}js_import {{.Name}} from {{.Path}};
+{{end}}
+{{end}}{{if .RateLimitZones}}# Rate limiting zones
+{{range .RateLimitZones}}limit_req_zone {{.Key}} zone={{.Name}}:{{.Size}} rate={{.Rate}};
+{{end}}{{end}}{{range .Maps}}
+map {{.Input}} {{.Variable}} {
+    default {{.Default}};
+{{if .Include}}    include {{.Include}};
+{{end}}{{range .Entries}}    {{.Key}} {{.Value}};
+{{end}}}
+{{end}}`
+
+const gatewayServerTemplate = `# Generated gateway combining {{len .Specs}} specification(s)
+{{if .HTTPContext}}
+{{.HTTPContext}}{{end}}{{range .Specs}}
+# Upstream for {{.FilePath}}
+{{.Upstream}}
+{{end}}
+server {
+    listen 80;
+    server_name _;
+{{range .Specs}}
+    # Locations for {{.FilePath}}
+{{.IndentedLocations}}
+{{end}}}
+`
+
+const gatewayIndexTemplate = `# Generated gateway combining {{len .Specs}} specification(s)
+server {
+    listen 80;
+    server_name _;
+{{range .Specs}}
+    # Locations for {{.FilePath}}
+    include {{if $.IncludePath}}{{$.IncludePath}}/{{end}}{{.Name}}.conf;
+{{end}}}
+`
+
+const caddyTemplate = `# Generated from {{.FilePath}}, import inside a site block
+{{if .Prefixed}}
+handle_path {{.Prefix}}/* {
+{{range .Prefixed}}
+{{.}}
+{{end}}}
+{{end}}{{range .Routes}}
+{{.}}
+{{end}}`
+
+const caddyRouteTemplate = `@{{.Name}} {
+	{{.Matcher}}{{if .Methods}}
+	method {{.Methods}}{{end}}
+}
+handle @{{.Name}} {
+{{if .BasePath}}	rewrite * {{.BasePath}}{uri}
+{{end}}{{if and .MaxRequestBody (ne .MaxRequestBody "0")}}	request_body {
+		max_size {{.MaxRequestBody}}
+	}
+{{end}}	header {
+{{range .Headers}}		{{.Name}} {{quote .Value}}
+{{end}}	}
+	reverse_proxy {{.Upstreams}} {
+{{if .LbPolicy}}		lb_policy {{.LbPolicy}}
+{{end}}		header_down -Server
+		header_down -X-Powered-By
+		transport http {
+			dial_timeout {{.DialTimeout}}
+			read_timeout {{.ReadTimeout}}
+			write_timeout {{.WriteTimeout}}
+{{if .TLSServerName}}			tls_server_name {{.TLSServerName}}
+{{end}}		}
+	}
 }`
 
+const haproxyTemplate = `# Generated from {{.FilePath}}
+frontend {{.Name}}
+    mode http
+    bind "${GATEWAY_BIND}"
+    option forwardfor
+{{range .Routes}}
+    # {{comment .Path}}
+    acl {{.Name}}_path path_reg {{.Regex}}{{if .Methods}}
+    acl {{.Name}}_method method {{.Methods}}
+    use_backend {{.Name}} if {{.Name}}_path {{.Name}}_method{{else}}
+    use_backend {{.Name}} if {{.Name}}_path{{end}}
+{{end}}{{range .Backends}}
+backend {{.Name}}
+    mode http
+{{if .Balance}}    balance {{.Balance}}
+{{end}}    timeout connect {{.ConnectTimeout}}
+    timeout server {{.ServerTimeout}}
+{{if .Rewrite}}    http-request replace-path {{.Rewrite}}
+{{end}}{{range .Headers}}    http-response set-header {{.Name}} {{.Value}}
+{{end}}    http-response del-header Server
+    http-response del-header X-Powered-By
+{{range .Servers}}    server {{.Name}} {{.Address}}{{if .Parameters}} {{.Parameters}}{{end}}
+{{end}}{{end}}`
+
 const oaSpecTemplate = `---
 aside: false
 outline: false
@@ -118,12 +263,14 @@ export default {
     },
 }`
 
-const oaTagsTemplate = `---
+const oaTagsFrontmatter = `---
 aside: false
 outline: false
-title: {{ .Title }}
+title: {{ yaml .Title }}
 ---
+`
 
+const oaTagsTemplate = `
 <script setup lang="ts">
 import { useRoute, useData } from 'vitepress';
 import spec from './{{ .FilePrefix }}spec.json';
@@ -136,13 +283,781 @@ const tag = route.data.params.tag
 
 <OASpec :spec="spec" :tags="[tag]" :isDark="isDark" hide-info hide-servers hide-paths-summary />`
 
-const oaIntroductionTemplate = `---
+const oaIntroductionFrontmatter = `---
 layout: doc
-title: {{ .Title }}
+title: {{ yaml .Title }}
 ---
+`
 
+const oaIntroductionTemplate = `
 <script setup lang="ts">
 import spec from './{{ .FilePrefix }}spec.json';
 </script>
 
 <OAIntroduction :spec="spec" />`
+
+const njsValidationTemplate = `// Generated from {{.FilePath}}
+// Validates requests before they are proxied. Invalid requests are answered with an
+// application/problem+json response, valid ones continue in the named location of their route
+
+var schemas = {{.Schemas}};
+
+var routes = {{.Routes}};
+
+function problem(r, status, title, errors) {
+    r.headersOut['Content-Type'] = 'application/problem+json';
+    r.return(status, JSON.stringify({
+        type: 'about:blank',
+        title: title,
+        status: status,
+        detail: errors.join('; '),
+        errors: errors
+    }));
+}
+
+function resolve(schema) {
+    var seen = 0;
+    while (schema && schema.$ref !== undefined && seen++ < 32) {
+        schema = schemas[schema.$ref];
+    }
+    return schema;
+}
+
+function typeMatches(type, value) {
+    switch (type) {
+    case 'object':
+        return typeof value === 'object' && value !== null && !Array.isArray(value);
+    case 'array':
+        return Array.isArray(value);
+    case 'string':
+        return typeof value === 'string';
+    case 'integer':
+        return typeof value === 'number' && Math.floor(value) === value;
+    case 'number':
+        return typeof value === 'number';
+    case 'boolean':
+        return typeof value === 'boolean';
+    case 'null':
+        return value === null;
+    }
+    return true;
+}
+
+function matches(schema, value, path) {
+    var errors = [];
+    checkValue(schema, value, path, errors);
+    return errors.length === 0;
+}
+
+function checkValue(schema, value, path, errors) {
+    schema = resolve(schema);
+    if (!schema) {
+        return;
+    }
+
+    if (value === null && (schema.nullable || schema.type === 'null')) {
+        return;
+    }
+
+    if (schema.allOf) {
+        schema.allOf.forEach(function (item) {
+            checkValue(item, value, path, errors);
+        });
+    }
+    if (schema.oneOf && schema.oneOf.filter(function (item) { return matches(item, value, path); }).length !== 1) {
+        errors.push(path + ' must match exactly one of the allowed schemas');
+    }
+    if (schema.anyOf && !schema.anyOf.some(function (item) { return matches(item, value, path); })) {
+        errors.push(path + ' must match at least one of the allowed schemas');
+    }
+
+    if (schema.type && !typeMatches(schema.type, value)) {
+        errors.push(path + ' must be of type ' + schema.type);
+        return;
+    }
+
+    if (typeMatches('object', value)) {
+        (schema.required || []).forEach(function (name) {
+            if (value[name] === undefined) {
+                errors.push(path + '.' + name + ' is required');
+            }
+        });
+        Object.keys(schema.properties || {}).forEach(function (name) {
+            if (value[name] !== undefined) {
+                checkValue(schema.properties[name], value[name], path + '.' + name, errors);
+            }
+        });
+    }
+
+    if (schema.items && Array.isArray(value)) {
+        value.forEach(function (item, i) {
+            checkValue(schema.items, item, path + '[' + i + ']', errors);
+        });
+    }
+}
+
+function checkParam(param, value, errors) {
+    var label = param.in + ' parameter ' + param.name;
+    if (value === undefined) {
+        if (param.required) {
+            errors.push(label + ' is required');
+        }
+        return;
+    }
+
+    var schema = resolve(param.schema);
+    if (!schema) {
+        return;
+    }
+
+    var parsed = value;
+    switch (schema.type) {
+    case 'integer':
+    case 'number':
+        parsed = Number(value);
+        if (String(value).trim() === '' || isNaN(parsed)) {
+            errors.push(label + ' must be of type ' + schema.type);
+            return;
+        }
+        break;
+    case 'boolean':
+        if (value !== 'true' && value !== 'false') {
+            errors.push(label + ' must be true or false');
+            return;
+        }
+        parsed = value === 'true';
+        break;
+    case 'array':
+        parsed = Array.isArray(value) ? value : String(value).split(',');
+        if (schema.items) {
+            parsed.forEach(function (item) {
+                checkParam({in: param.in, name: param.name, schema: schema.items}, item, errors);
+            });
+        }
+        return;
+    case 'object':
+        return;
+    }
+
+    checkValue(schema, parsed, label, errors);
+}
+
+function cookie(r, name) {
+    var cookies = (r.headersIn['Cookie'] || '').split(';');
+    for (var i = 0; i < cookies.length; i++) {
+        var pair = cookies[i].trim().split('=');
+        if (pair[0] === name) {
+            return pair.slice(1).join('=');
+        }
+    }
+    return undefined;
+}
+
+function mediaType(value) {
+    return (value || '').split(';')[0].trim().toLowerCase();
+}
+
+function accepts(contentTypes, type) {
+    return contentTypes.some(function (accepted) {
+        accepted = mediaType(accepted);
+        return accepted === type || accepted === '*/*' ||
+            (accepted.endsWith('/*') && type.startsWith(accepted.slice(0, -1)));
+    });
+}
+
+function validate(r, name) {
+    var route = routes[name];
+    var operation = route.operations[r.method];
+    if (!operation) {
+        r.internalRedirect('@' + name);
+        return;
+    }
+
+    var errors = [];
+    var match = route.pattern ? new RegExp(route.pattern).exec(r.uri) : null;
+
+    operation.params.forEach(function (param) {
+        var value;
+        switch (param.in) {
+        case 'path':
+            value = match ? match[route.params.indexOf(param.name) + 1] : undefined;
+            break;
+        case 'query':
+            value = r.args[param.name];
+            break;
+        case 'header':
+            value = r.headersIn[param.name];
+            break;
+        case 'cookie':
+            value = cookie(r, param.name);
+            break;
+        }
+        checkParam(param, value, errors);
+    });
+
+    var body = operation.body;
+    if (body) {
+        var type = mediaType(r.headersIn['Content-Type']);
+        var length = r.headersIn['Content-Length'];
+        var present = (length !== undefined && length !== '0') || r.headersIn['Transfer-Encoding'] !== undefined;
+
+        if (!present) {
+            if (body.required) {
+                errors.push('request body is required');
+            }
+        } else if (!accepts(body.contentTypes, type)) {
+            problem(r, 415, 'Unsupported Media Type', ['content type ' + (type || 'none') + ' is not one of ' + body.contentTypes.join(', ')]);
+            return;
+        } else if (body.schemas && body.schemas[type] && r.requestText !== undefined) {
+            // Bodies buffered to a temporary file are not available to njs and pass unchecked
+            try {
+                checkValue(body.schemas[type], JSON.parse(r.requestText), 'body', errors);
+            } catch (e) {
+                errors.push('body is not valid JSON');
+            }
+        }
+    }
+
+    if (errors.length > 0) {
+        problem(r, 400, 'Bad Request', errors);
+        return;
+    }
+
+    r.internalRedirect('@' + name);
+}
+
+export default {
+{{range .Handlers}}    {{.}}: function (r) {
+        validate(r, '{{.}}');
+    },
+{{end}}};
+`
+
+const goClientTemplate = `// Code generated by openapi-converter from {{.FilePath}}. DO NOT EDIT.
+
+// Package {{.Package}} is a client of the {{comment .Title}} {{comment .Version}}
+package {{.Package}}
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// DefaultServerURL is the URL of the first server of the spec
+const DefaultServerURL = {{.ServerURL}}
+
+// RequestEditorFn modifies a request before it is sent, e.g. to add credentials
+type RequestEditorFn func(ctx context.Context, req *http.Request) error
+
+// Client calls the operations of the {{comment .Title}}
+type Client struct {
+	// BaseURL is the server URL the paths of the operations are appended to
+	BaseURL string
+	// HTTPClient sends the requests, http.DefaultClient when nil
+	HTTPClient *http.Client
+	// RequestEditors are applied to every request in order
+	RequestEditors []RequestEditorFn
+}
+
+// ClientOption configures a Client
+type ClientOption func(*Client)
+
+// WithHTTPClient sends the requests with client
+func WithHTTPClient(client *http.Client) ClientOption {
+	return func(c *Client) {
+		c.HTTPClient = client
+	}
+}
+
+// WithRequestEditor applies fn to every request before it is sent
+func WithRequestEditor(fn RequestEditorFn) ClientOption {
+	return func(c *Client) {
+		c.RequestEditors = append(c.RequestEditors, fn)
+	}
+}
+
+// NewClient creates a client of the server at baseURL, DefaultServerURL when empty
+func NewClient(baseURL string, options ...ClientOption) *Client {
+	if baseURL == "" {
+		baseURL = DefaultServerURL
+	}
+
+	c := &Client{BaseURL: baseURL}
+	for _, option := range options {
+		option(c)
+	}
+
+	return c
+}
+
+// APIError is returned for responses with a status outside 2xx
+type APIError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s %s: unexpected status %d: %s", e.Method, e.URL, e.StatusCode, bytes.TrimSpace(e.Body))
+}
+{{template "types" .Types}}{{template "params" .Operations}}{{range .Operations}}
+// {{.Name}} calls {{.Method}} {{.Path}}{{if .Summary}}: {{comment .Summary}}{{end}}{{if .Description}}
+//
+// {{comment .Description}}{{end}}
+func (c *Client) {{.Name}}(ctx context.Context{{.Signature}}) {{if .Result}}({{.Result}}, error){{else}}error{{end}} {
+	path := {{.PathExpression}}
+{{if .HasQuery}}	query := url.Values{}
+{{end}}{{if .HasHeader}}	header := http.Header{}
+{{end}}{{if .Params}}	if params != nil {
+{{range .ParamFields}}{{.Encode}}
+{{end}}	}
+{{end}}
+{{if .Result}}	var result {{.ResultType}}
+	if err := c.do({{.Call}}); err != nil {
+		return {{.ResultZero}}, err
+	}
+
+	return {{if ne .Result .ResultType}}&{{end}}result, nil
+{{else}}	return c.do({{.Call}})
+{{end}}}
+{{end}}
+// do sends a request and decodes the JSON body of a successful response into result. Bodies are
+// encoded as JSON unless they are a reader
+func (c *Client) do(ctx context.Context, method string, path string, query url.Values, header http.Header, contentType string, body interface{}, result interface{}) error {
+	var reader io.Reader
+	switch body := body.(type) {
+	case nil:
+	case io.Reader:
+		reader = body
+	default:
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode %s %s request body: %w", method, path, err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	if len(query) > 0 {
+		req.URL.RawQuery = query.Encode()
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	if reader != nil && contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if result != nil {
+		req.Header.Set("Accept", "application/json")
+	}
+
+	for _, edit := range c.RequestEditors {
+		if err := edit(ctx, req); err != nil {
+			return err
+		}
+	}
+
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read %s %s response: %w", method, path, err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &APIError{Method: method, URL: req.URL.String(), StatusCode: resp.StatusCode, Header: resp.Header, Body: data}
+	}
+
+	if result == nil || len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+
+	if err := json.Unmarshal(data, result); err != nil {
+		return fmt.Errorf("failed to decode %s %s response: %w", method, path, err)
+	}
+
+	return nil
+}
+
+// formatParam formats a parameter value for a path, query or header
+func formatParam(value interface{}) string {
+	if t, ok := value.(time.Time); ok {
+		return t.Format(time.RFC3339)
+	}
+
+	return fmt.Sprint(value)
+}
+`
+
+// goTypesTemplate declares the generated types, shared by the Go outputs
+const goTypesTemplate = `{{define "types"}}{{range .}}{{$decl := .}}
+// {{.Name}} is generated from {{.Source}}{{if .Doc}}. {{comment .Doc}}{{end}}
+{{if eq .Type "struct"}}type {{.Name}} struct {
+{{range .Fields}}{{if .Doc}}	// {{comment .Doc}}
+{{end}}	{{.Name}} {{.Type}} {{.Tag}}
+{{end}}}
+{{else}}type {{.Name}} {{if .Alias}}= {{end}}{{.Type}}
+{{if .Values}}
+const (
+{{range .Values}}	{{.Name}} {{$decl.Name}} = {{.Value}}
+{{end}})
+{{end}}{{end}}{{end}}{{end}}{{define "params"}}{{range .}}{{if .Params}}
+// {{.Params}} holds the query, header and cookie parameters of {{.Name}}
+type {{.Params}} struct {
+{{range .ParamFields}}	// {{.Name}} is the {{.In}} parameter {{.Param}}{{if .Doc}}. {{comment .Doc}}{{end}}
+	{{.Name}} {{.Type}}
+{{end}}}
+{{end}}{{end}}{{end}}`
+
+const goServerTemplate = `// Code generated by openapi-converter from {{.FilePath}}. DO NOT EDIT.
+
+// Package {{.Package}} is a server of the {{comment .Title}} {{comment .Version}}
+package {{.Package}}
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BasePath is the path gateways proxy the operations to
+const BasePath = {{.BasePath}}
+
+// Server is implemented by the service. Every method receives the parameters and the body of a
+// request and returns one of the responses the spec declares for the operation
+type Server interface {
+{{range .Operations}}	// {{.Name}} handles {{.Method}} {{.Path}}{{if .Summary}}: {{comment .Summary}}{{end}}
+	{{.Name}}(ctx context.Context, request {{.Request}}) ({{.Response}}, error)
+{{end}}}
+{{template "types" .Types}}{{range .Operations}}{{$op := .}}
+{{if or .Fields .ServerBody}}// {{.Request}} holds the {{if and .Fields .ServerBody}}parameters and the body{{else if .Fields}}parameters{{else}}body{{end}} of a {{.Name}} request{{else}}// {{.Request}} is a {{.Name}} request, which has no parameters or body{{end}}
+type {{.Request}} struct {
+{{range .Fields}}	// {{.Name}} is the {{.In}} parameter {{.Param}}{{if .Doc}}. {{comment .Doc}}{{end}}
+	{{.Name}} {{.Type}}
+{{end}}{{if .ServerBody}}	Body {{.ServerBody}}
+{{end}}}
+
+// {{.Response}} is one of {{.ResponseNames}}
+type {{.Response}} interface {
+	write{{.Response}}(w http.ResponseWriter) error
+}
+{{range .Responses}}
+// {{.Name}} is the {{.Code}} response of {{$op.Name}}{{if .Description}}: {{comment .Description}}{{end}}
+type {{.Name}} struct {
+{{if not .Status}}	StatusCode int
+{{end}}	Header http.Header
+{{if .Body}}	Body {{.Body}}
+{{end}}}
+
+func (r {{.Name}}) write{{$op.Response}}(w http.ResponseWriter) error {
+	return writeResponse(w, r.Header, {{if .Status}}{{.Status}}{{else}}r.StatusCode{{end}}, {{.ContentType}}, {{if .Body}}r.Body{{else}}nil{{end}})
+}
+{{end}}{{end}}
+// HandlerOptions tunes the handlers of a Server
+type HandlerOptions struct {
+	// BasePath prefixes the path patterns, typically BasePath when requests keep the path of the
+	// server URL
+	BasePath string
+	// ErrorHandler writes the response for requests whose parameters or body cannot be read, which
+	// are reported as *RequestError, and for errors returned by the Server. By default a 400 or 500
+	// application/problem+json response is written
+	ErrorHandler func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+// Handler returns a ServeMux routing the operations of the spec to server
+func Handler(server Server, options HandlerOptions) http.Handler {
+	mux := http.NewServeMux()
+	RegisterHandlers(mux, server, options)
+
+	return mux
+}
+
+// RegisterHandlers registers a handler per operation on mux, with Go 1.22 method and path patterns
+func RegisterHandlers(mux *http.ServeMux, server Server, options HandlerOptions) {
+	h := &handlers{server: server, errorHandler: options.ErrorHandler}
+	if h.errorHandler == nil {
+		h.errorHandler = writeError
+	}
+
+	basePath := strings.TrimSuffix(options.BasePath, "/")
+{{range .Operations}}	mux.HandleFunc("{{.Method}} "+basePath+{{.Pattern}}, h.{{.Handler}})
+{{end}}}
+
+type handlers struct {
+	server       Server
+	errorHandler func(w http.ResponseWriter, r *http.Request, err error)
+}
+{{range .Operations}}
+func (h *handlers) {{.Handler}}(w http.ResponseWriter, r *http.Request) {
+	var request {{.Request}}
+{{range .Fields}}	if err := bindParam(r, {{printf "%q" .In}}, {{printf "%q" .Param}}, {{printf "%q" .Wildcard}}, {{.Required}}, &request.{{.Name}}); err != nil {
+		h.errorHandler(w, r, err)
+		return
+	}
+{{end}}{{if .BodyReader}}	request.Body = r.Body
+{{else if .Body}}	if err := decodeBody(r, {{.BodyRequired}}, &request.Body); err != nil {
+		h.errorHandler(w, r, err)
+		return
+	}
+{{end}}
+	response, err := h.server.{{.Name}}(r.Context(), request)
+	if err == nil && response == nil {
+		err = errors.New("{{.Name}} returned no response")
+	}
+	if err != nil {
+		h.errorHandler(w, r, err)
+		return
+	}
+
+	if err := response.write{{.Response}}(w); err != nil {
+		h.errorHandler(w, r, err)
+	}
+}
+{{end}}
+// RequestError reports a parameter or a body that cannot be read from a request
+type RequestError struct {
+	In   string
+	Name string
+	Err  error
+}
+
+func (e *RequestError) Error() string {
+	if e.In == "body" {
+		return "request body " + e.Err.Error()
+	}
+
+	return fmt.Sprintf("%s parameter %s %s", e.In, e.Name, e.Err)
+}
+
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+// bindParam reads a parameter of a request into target, a pointer to the field of the parameter
+func bindParam(r *http.Request, in string, name string, wildcard string, required bool, target interface{}) error {
+	var values []string
+	switch in {
+	case "path":
+		values = []string{r.PathValue(wildcard)}
+	case "query":
+		values = r.URL.Query()[name]
+	case "header":
+		values = r.Header.Values(name)
+	case "cookie":
+		if cookie, err := r.Cookie(name); err == nil {
+			values = []string{cookie.Value}
+		}
+	}
+
+	if len(values) == 0 || (len(values) == 1 && values[0] == "") {
+		if required {
+			return &RequestError{In: in, Name: name, Err: errors.New("is required")}
+		}
+		return nil
+	}
+
+	if err := setParam(reflect.ValueOf(target).Elem(), values); err != nil {
+		return &RequestError{In: in, Name: name, Err: err}
+	}
+
+	return nil
+}
+
+// setParam parses parameter values into a value of the type of the parameter field. Arrays are
+// sent as repeated parameters or comma separated, objects as JSON
+func setParam(v reflect.Value, values []string) error {
+	switch {
+	case v.Kind() == reflect.Pointer:
+		value := reflect.New(v.Type().Elem())
+		if err := setParam(value.Elem(), values); err != nil {
+			return err
+		}
+		v.Set(value)
+		return nil
+	case v.Type() == reflect.TypeOf(time.Time{}):
+		t, err := time.Parse(time.RFC3339, values[0])
+		if err != nil {
+			return errors.New("must be a date-time")
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8:
+		v.SetBytes([]byte(values[0]))
+		return nil
+	case v.Kind() == reflect.Slice:
+		if len(values) == 1 {
+			values = strings.Split(values[0], ",")
+		}
+		slice := reflect.MakeSlice(v.Type(), len(values), len(values))
+		for i, value := range values {
+			if err := setParam(slice.Index(i), []string{value}); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+		return nil
+	}
+
+	value := values[0]
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		parsed, err := strconv.ParseInt(strings.TrimSpace(value), 10, v.Type().Bits())
+		if err != nil {
+			return errors.New("must be of type integer")
+		}
+		v.SetInt(parsed)
+	case reflect.Float32, reflect.Float64:
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(value), v.Type().Bits())
+		if err != nil {
+			return errors.New("must be of type number")
+		}
+		v.SetFloat(parsed)
+	case reflect.Bool:
+		if value != "true" && value != "false" {
+			return errors.New("must be true or false")
+		}
+		v.SetBool(value == "true")
+	default:
+		if err := json.Unmarshal([]byte(value), v.Addr().Interface()); err != nil {
+			return errors.New("is not valid JSON")
+		}
+	}
+
+	return nil
+}
+
+// decodeBody decodes the JSON body of a request into target
+func decodeBody(r *http.Request, required bool, target interface{}) error {
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		return &RequestError{In: "body", Err: fmt.Errorf("cannot be read: %w", err)}
+	}
+
+	if len(bytes.TrimSpace(data)) == 0 {
+		if required {
+			return &RequestError{In: "body", Err: errors.New("is required")}
+		}
+		return nil
+	}
+
+	if err := json.Unmarshal(data, target); err != nil {
+		return &RequestError{In: "body", Err: fmt.Errorf("is not valid JSON: %w", err)}
+	}
+
+	return nil
+}
+
+// writeResponse writes a response. Bodies are encoded as JSON unless they are a reader
+func writeResponse(w http.ResponseWriter, header http.Header, status int, contentType string, body interface{}) error {
+	var data []byte
+	var reader io.Reader
+	switch body := body.(type) {
+	case nil:
+	case io.Reader:
+		reader = body
+	default:
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode response body: %w", err)
+		}
+		data = encoded
+	}
+
+	for name, values := range header {
+		w.Header()[name] = values
+	}
+	if body != nil && contentType != "" && w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", contentType)
+	}
+	w.WriteHeader(status)
+
+	if reader != nil {
+		_, err := io.Copy(w, reader)
+		return err
+	}
+	_, err := w.Write(data)
+
+	return err
+}
+
+// writeError answers with an application/problem+json response: 400 for a *RequestError and 500
+// for errors of the Server, whose details are not exposed
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	problem := map[string]interface{}{"type": "about:blank", "status": http.StatusInternalServerError}
+
+	var requestError *RequestError
+	if errors.As(err, &requestError) {
+		problem["status"] = http.StatusBadRequest
+		problem["detail"] = requestError.Error()
+	}
+	problem["title"] = http.StatusText(problem["status"].(int))
+
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(problem["status"].(int))
+	_ = json.NewEncoder(w).Encode(problem)
+}
+`
+
+const typeScriptTemplate = `// Generated from {{.FilePath}}
+{{range .Declarations}}
+{{.Doc}}{{if .Interface}}export interface {{.Name}} {{.Type}}
+{{else}}export type {{.Name}} = {{.Type}};
+{{end}}{{end}}
+/** The operations of the spec keyed by operationId */
+export interface Operations {
+{{range .Operations}}{{.Doc}}  {{.Key}}: {
+    path: {{.Path}};
+    method: {{.Method}};
+    params: {{.Params}};
+    requestBody: {{.RequestBody}};
+    responses: {{.Responses}};
+  };
+{{end}}}
+
+export type OperationId = keyof Operations;
+`
+
+const httpFileTemplate = `# {{.Title}}{{if .Tag}}: {{.Tag}}{{end}}
+# Generated from {{.FilePath}}
+
+@baseUrl = {{.BaseURL}}
+{{range .Variables}}@{{.}} =
+{{end}}{{range .Requests}}
+### {{.Name}}
+{{range .Doc}}# {{.}}
+{{end}}{{if .ID}}# @name {{.ID}}
+{{end}}{{.Method}} {{.URL}}
+{{range .Headers}}{{.}}
+{{end}}{{if .Body}}
+{{.Body}}
+{{end}}{{end}}`
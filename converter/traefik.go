@@ -0,0 +1,128 @@
+package converter
+
+import (
+	"fmt"
+	"github.com/nimling/openapi-converter/traefik"
+	"gopkg.in/yaml.v3"
+	"strings"
+)
+
+// WriteTraefikConfiguration renders the spec as Traefik file-provider dynamic configuration.
+// Every path becomes a router matching the same requests as its Nginx location, all routers share
+// one load-balanced service built from the upstream servers, and middlewares take over the prefix
+// rewrite and the response headers. Backup servers have no load balancer equivalent and are left out
+func (n *OpenAPIConverter) WriteTraefikConfiguration() (string, error) {
+	table, err := n.RouteTable()
+	if err != nil {
+		return "", err
+	}
+
+	return renderTraefik(table)
+}
+
+func renderTraefik(table *RouteTable) (string, error) {
+	service := &traefik.Service{LoadBalancer: &traefik.LoadBalancer{}}
+	for _, server := range table.Upstream.Servers {
+		if server.Backup {
+			continue
+		}
+
+		service.LoadBalancer.Servers = append(service.LoadBalancer.Servers, &traefik.Server{
+			URL:    server.Origin(),
+			Weight: server.Weight,
+		})
+	}
+
+	config := &traefik.Config{HTTP: &traefik.HTTPConfig{
+		Routers:     map[string]*traefik.Router{},
+		Services:    map[string]*traefik.Service{table.Upstream.Name: service},
+		Middlewares: map[string]*traefik.Middleware{},
+	}}
+
+	var rewrites []string
+	if table.Prefix != "" {
+		config.HTTP.Middlewares[table.Spec+"_strip_prefix"] = &traefik.Middleware{StripPrefix: &traefik.StripPrefix{Prefixes: []string{table.Prefix}}}
+		rewrites = append(rewrites, table.Spec+"_strip_prefix")
+	}
+	if basePath := strings.TrimSuffix(table.Upstream.BasePath, "/"); basePath != "" {
+		config.HTTP.Middlewares[table.Spec+"_base_path"] = &traefik.Middleware{AddPrefix: &traefik.AddPrefix{Prefix: basePath}}
+		rewrites = append(rewrites, table.Spec+"_base_path")
+	}
+
+	for _, route := range table.Routes {
+		config.HTTP.Middlewares[route.Name+"_headers"] = &traefik.Middleware{Headers: traefikHeaders(route.Settings, route.EffectiveCORS())}
+
+		config.HTTP.Routers[route.Name] = &traefik.Router{
+			Rule:        traefikRule(route),
+			Service:     table.Upstream.Name,
+			Middlewares: append(append([]string{}, rewrites...), route.Name+"_headers"),
+		}
+	}
+
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return "", fmt.Errorf("file '%s': failed to marshal Traefik configuration: %w", table.FilePath, err)
+	}
+
+	return string(data), nil
+}
+
+// traefikRule combines the path regex with one Method matcher per operation
+func traefikRule(route *Route) string {
+	rule := fmt.Sprintf("PathRegexp(`%s`)", route.Match.Regex)
+	methods := route.AllowedMethods()
+	if len(methods) == 0 {
+		return rule
+	}
+
+	matchers := make([]string, 0, len(methods))
+	for _, method := range methods {
+		matchers = append(matchers, fmt.Sprintf("Method(`%s`)", method))
+	}
+
+	return fmt.Sprintf("%s && (%s)", rule, strings.Join(matchers, " || "))
+}
+
+// traefikHeaders mirrors the security, caching and CORS headers of the Nginx locations
+func traefikHeaders(settings *NginxSettings, cors *CORS) *traefik.Headers {
+	headers := &traefik.Headers{
+		CustomResponseHeaders: map[string]string{
+			"Cache-Control": settings.CacheControl,
+			// An empty value removes the header, like proxy_hide_header
+			"Server":       "",
+			"X-Powered-By": "",
+		},
+		ContentTypeNosniff:   true,
+		BrowserXSSFilter:     true,
+		STSSeconds:           31536000,
+		STSIncludeSubdomains: true,
+	}
+
+	if settings.NoCache() {
+		headers.CustomResponseHeaders["Pragma"] = "no-cache"
+	}
+	for name, value := range settings.Headers {
+		headers.CustomResponseHeaders[name] = value
+	}
+
+	if cors != nil {
+		for _, origin := range cors.Origins {
+			switch {
+			case strings.HasPrefix(origin, "~*"):
+				headers.AccessControlAllowOriginListRegex = append(headers.AccessControlAllowOriginListRegex, "(?i)"+strings.TrimPrefix(origin, "~*"))
+			case strings.HasPrefix(origin, "~"):
+				headers.AccessControlAllowOriginListRegex = append(headers.AccessControlAllowOriginListRegex, strings.TrimPrefix(origin, "~"))
+			default:
+				headers.AccessControlAllowOriginList = append(headers.AccessControlAllowOriginList, origin)
+			}
+		}
+		headers.AccessControlAllowMethods = cors.Methods
+		headers.AccessControlAllowHeaders = cors.Headers
+		headers.AccessControlExposeHeaders = cors.ExposeHeaders
+		headers.AccessControlAllowCredentials = cors.Credentials
+		headers.AccessControlMaxAge = cors.MaxAge
+		headers.AddVaryHeader = true
+	}
+
+	return headers
+}
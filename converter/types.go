@@ -17,6 +17,11 @@ type OpenAPIDoc struct {
 	Components     *Components          `yaml:"components,omitempty"`
 	Paths          map[string]*PathItem `yaml:"paths,omitempty"`
 	Security       *SecurityRequirement `yaml:"security,omitempty"`
+	Upstream       *Upstream            `yaml:"x-upstream,omitempty"`
+	Nginx          *NginxSettings       `yaml:"x-nginx,omitempty"`
+	RateLimit      *RateLimit           `yaml:"x-rate-limit,omitempty"`
+	CORS           *CORS                `yaml:"x-cors,omitempty"`
+	Auth           *Auth                `yaml:"x-auth,omitempty"`
 }
 
 type SecurityRequirement []map[string][]string
@@ -41,8 +46,79 @@ type License struct {
 }
 
 type Server struct {
+	URL         string                     `yaml:"url"`
+	Description string                     `yaml:"description"`
+	Variables   map[string]*ServerVariable `yaml:"variables,omitempty"`
+}
+
+type ServerVariable struct {
+	Enum        []string `yaml:"enum,omitempty"`
+	Default     string   `yaml:"default"`
+	Description string   `yaml:"description,omitempty"`
+}
+
+// Upstream is the x-upstream extension describing the Nginx upstream the spec is proxied to
+type Upstream struct {
+	Name      string            `yaml:"name,omitempty"`
+	Balancing string            `yaml:"balancing,omitempty"`
+	Keepalive int               `yaml:"keepalive,omitempty"`
+	Servers   []*UpstreamServer `yaml:"servers,omitempty"`
+}
+
+type UpstreamServer struct {
 	URL         string `yaml:"url"`
-	Description string `yaml:"description"`
+	Weight      int    `yaml:"weight,omitempty"`
+	MaxFails    int    `yaml:"maxFails,omitempty"`
+	FailTimeout string `yaml:"failTimeout,omitempty"`
+	Backup      bool   `yaml:"backup,omitempty"`
+}
+
+// NginxSettings is the x-nginx extension tuning the generated locations.
+// It can be set on the document, a path item or an operation, and the most specific value wins
+type NginxSettings struct {
+	ConnectTimeout    string            `yaml:"connectTimeout,omitempty" json:"connectTimeout,omitempty"`
+	SendTimeout       string            `yaml:"sendTimeout,omitempty" json:"sendTimeout,omitempty"`
+	ReadTimeout       string            `yaml:"readTimeout,omitempty" json:"readTimeout,omitempty"`
+	ClientMaxBodySize string            `yaml:"clientMaxBodySize,omitempty" json:"clientMaxBodySize,omitempty"`
+	Buffering         *bool             `yaml:"buffering,omitempty" json:"buffering,omitempty"`
+	BufferSize        string            `yaml:"bufferSize,omitempty" json:"bufferSize,omitempty"`
+	Buffers           string            `yaml:"buffers,omitempty" json:"buffers,omitempty"`
+	BusyBuffersSize   string            `yaml:"busyBuffersSize,omitempty" json:"busyBuffersSize,omitempty"`
+	CacheControl      string            `yaml:"cacheControl,omitempty" json:"cacheControl,omitempty"`
+	ProxyCache        string            `yaml:"proxyCache,omitempty" json:"proxyCache,omitempty"`
+	ProxyCacheValid   string            `yaml:"proxyCacheValid,omitempty" json:"proxyCacheValid,omitempty"`
+	Headers           map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"`
+	Directives        []string          `yaml:"directives,omitempty" json:"directives,omitempty"`
+}
+
+// RateLimit is the x-rate-limit extension enforced with Nginx limit_req.
+// The most specific definition of the document, a path item or an operation applies
+type RateLimit struct {
+	Zone    string `yaml:"zone,omitempty" json:"zone,omitempty"`
+	Rate    string `yaml:"rate" json:"rate,omitempty"`
+	Burst   int    `yaml:"burst,omitempty" json:"burst,omitempty"`
+	NoDelay bool   `yaml:"nodelay,omitempty" json:"nodelay,omitempty"`
+	Key     string `yaml:"key,omitempty" json:"key,omitempty"`
+	Size    string `yaml:"size,omitempty" json:"size,omitempty"`
+}
+
+// CORS is the x-cors extension answering browser preflights at the gateway.
+// A path item definition replaces the document definition
+type CORS struct {
+	Origins       []string `yaml:"origins" json:"origins,omitempty"`
+	Methods       []string `yaml:"methods,omitempty" json:"methods,omitempty"`
+	Headers       []string `yaml:"headers,omitempty" json:"headers,omitempty"`
+	ExposeHeaders []string `yaml:"exposeHeaders,omitempty" json:"exposeHeaders,omitempty"`
+	Credentials   bool     `yaml:"credentials,omitempty" json:"credentials,omitempty"`
+	MaxAge        int      `yaml:"maxAge,omitempty" json:"maxAge,omitempty"`
+}
+
+// Auth is the x-auth extension telling the Nginx output where credentials are checked. API keys
+// and Basic auth users are read from files below CredentialsPath, bearer tokens are sent to the
+// IntrospectionURL with auth_request
+type Auth struct {
+	IntrospectionURL string `yaml:"introspectionUrl,omitempty" json:"introspectionUrl,omitempty"`
+	CredentialsPath  string `yaml:"credentialsPath,omitempty" json:"credentialsPath,omitempty"`
 }
 
 type Component struct {
@@ -60,13 +136,13 @@ type Components struct {
 }
 
 type SecurityScheme struct {
-	Ref          *string `yaml:"$ref,omitempty"`
-	Type         string  `yaml:"type,omitempty"`
-	Scheme       string  `yaml:"scheme,omitempty"`
-	BearerFormat string  `yaml:"bearerFormat,omitempty"`
-	In           string  `yaml:"in,omitempty"`
-	Name         string  `yaml:"name,omitempty"`
-	Description  string  `yaml:"description"`
+	Ref          *string `yaml:"$ref,omitempty" json:"$ref,omitempty"`
+	Type         string  `yaml:"type,omitempty" json:"type,omitempty"`
+	Scheme       string  `yaml:"scheme,omitempty" json:"scheme,omitempty"`
+	BearerFormat string  `yaml:"bearerFormat,omitempty" json:"bearerFormat,omitempty"`
+	In           string  `yaml:"in,omitempty" json:"in,omitempty"`
+	Name         string  `yaml:"name,omitempty" json:"name,omitempty"`
+	Description  string  `yaml:"description" json:"description,omitempty"`
 }
 
 type Parameter struct {
@@ -80,13 +156,16 @@ type Parameter struct {
 }
 
 type PathItem struct {
-	Parameters  []*Parameter `yaml:"parameters,omitempty"`
-	Get         *Operation   `yaml:"get,omitempty"`
-	Post        *Operation   `yaml:"post,omitempty"`
-	Put         *Operation   `yaml:"put,omitempty"`
-	Delete      *Operation   `yaml:"delete,omitempty"`
-	Summary     *string      `yaml:"summary,omitempty"`
-	Description *string      `yaml:"description,omitempty"`
+	Parameters  []*Parameter   `yaml:"parameters,omitempty"`
+	Get         *Operation     `yaml:"get,omitempty"`
+	Post        *Operation     `yaml:"post,omitempty"`
+	Put         *Operation     `yaml:"put,omitempty"`
+	Delete      *Operation     `yaml:"delete,omitempty"`
+	Summary     *string        `yaml:"summary,omitempty"`
+	Description *string        `yaml:"description,omitempty"`
+	Nginx       *NginxSettings `yaml:"x-nginx,omitempty"`
+	RateLimit   *RateLimit     `yaml:"x-rate-limit,omitempty"`
+	CORS        *CORS          `yaml:"x-cors,omitempty"`
 }
 
 type Schema struct {
@@ -99,6 +178,14 @@ type Schema struct {
 	Example     interface{}        `yaml:"example,omitempty"`
 	Nullable    *bool              `yaml:"nullable,omitempty"`
 	Items       *Schema            `yaml:"items,omitempty"`
+	Enum        []interface{}      `yaml:"enum,omitempty"`
+	Default     interface{}        `yaml:"default,omitempty"`
+	Minimum     *float64           `yaml:"minimum,omitempty"`
+	Maximum     *float64           `yaml:"maximum,omitempty"`
+	MinLength   *int               `yaml:"minLength,omitempty"`
+	MaxLength   *int               `yaml:"maxLength,omitempty"`
+	MinItems    *int               `yaml:"minItems,omitempty"`
+	MaxItems    *int               `yaml:"maxItems,omitempty"`
 
 	AllOf []*Schema `yaml:"allOf,omitempty"`
 	OneOf []*Schema `yaml:"oneOf,omitempty"`
@@ -115,6 +202,8 @@ type Operation struct {
 	Responses   map[string]*Response  `yaml:"responses,omitempty"`
 	RequestBody *RequestBody          `yaml:"requestBody,omitempty"`
 	Tags        *[]string             `yaml:"tags,omitempty"`
+	Nginx       *NginxSettings        `yaml:"x-nginx,omitempty"`
+	RateLimit   *RateLimit            `yaml:"x-rate-limit,omitempty"`
 	Method      string                `yaml:"-"`
 }
 
@@ -127,10 +216,25 @@ type RequestBody struct {
 type Response struct {
 	Ref         *string                     `yaml:"$ref,omitempty"`
 	Description *string                     `yaml:"description,omitempty"`
+	Headers     map[string]*Header          `yaml:"headers,omitempty"`
 	Content     map[string]*ResponseContent `yaml:"content,omitempty"`
 }
 
+type Header struct {
+	Description string      `yaml:"description,omitempty"`
+	Required    bool        `yaml:"required,omitempty"`
+	Schema      *Schema     `yaml:"schema,omitempty"`
+	Example     interface{} `yaml:"example,omitempty"`
+}
+
 type ResponseContent struct {
-	Schema  *Schema     `yaml:"schema,omitempty"`
-	Example interface{} `yaml:"example,omitempty"`
+	Schema   *Schema             `yaml:"schema,omitempty"`
+	Example  interface{}         `yaml:"example,omitempty"`
+	Examples map[string]*Example `yaml:"examples,omitempty"`
+}
+
+type Example struct {
+	Summary     string      `yaml:"summary,omitempty"`
+	Description string      `yaml:"description,omitempty"`
+	Value       interface{} `yaml:"value,omitempty"`
 }
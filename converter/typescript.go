@@ -0,0 +1,351 @@
+package converter
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/nimling/openapi-converter/utils"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var tsIdentifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// tsDeclaration is an exported type of the TypeScript output
+type tsDeclaration struct {
+	Name      string
+	Doc       string
+	Interface bool
+	Type      string
+}
+
+// tsOperation is an entry of the operations map, keyed by operationId
+type tsOperation struct {
+	Key         string
+	Doc         string
+	Path        string
+	Method      string
+	Params      string
+	RequestBody string
+	Responses   string
+}
+
+// WriteTypeScript renders TypeScript declarations of the spec: a type for every component schema
+// and an Operations map describing the path, method, parameters, request body and responses of
+// every operation. oneOf and anyOf become unions, allOf intersections and enums literal unions;
+// properties that are not required are optional
+func (n *OpenAPIConverter) WriteTypeScript() (string, error) {
+	table, err := n.RouteTable()
+	if err != nil {
+		return "", err
+	}
+
+	var declarations []*tsDeclaration
+	for _, name := range sortedKeys(table.Schemas) {
+		schema := table.Schemas[name]
+
+		declaration := &tsDeclaration{Name: tsTypeName(name), Doc: tsDoc(schemaDoc(schema), "")}
+		if schema != nil && schema.Ref == nil && len(schema.AllOf)+len(schema.OneOf)+len(schema.AnyOf) == 0 && len(schema.Enum) == 0 &&
+			schemaType(schema) == "object" && len(schema.Properties) > 0 && !(schema.Nullable != nil && *schema.Nullable) {
+			declaration.Interface = true
+		}
+		declaration.Type = tsType(schema, "")
+		declarations = append(declarations, declaration)
+	}
+
+	var operations []*tsOperation
+	for _, route := range table.Routes {
+		for _, op := range route.Operations {
+			key := op.OperationID
+			if key == "" {
+				key = strings.ToLower(op.Method) + " " + route.Path
+			}
+
+			doc := op.Summary
+			if op.Description != "" {
+				doc = joinDoc(op.Summary, op.Description)
+			}
+
+			operations = append(operations, &tsOperation{
+				Key:         tsPropertyName(key),
+				Doc:         tsDoc(strings.Join(strings.Fields(doc), " "), "  "),
+				Path:        strconv.Quote(route.Path),
+				Method:      strconv.Quote(op.Method),
+				Params:      tsParams(route.OperationParams(op), "    "),
+				RequestBody: tsRequestBody(op.Body),
+				Responses:   tsResponses(op.Responses, "    "),
+			})
+		}
+	}
+
+	data := struct {
+		FilePath     string
+		Declarations []*tsDeclaration
+		Operations   []*tsOperation
+	}{
+		FilePath:     table.FilePath,
+		Declarations: declarations,
+		Operations:   operations,
+	}
+
+	return utils.ExecuteTemplate(utils.FormatRaw, "typescript", typeScriptTemplate, data)
+}
+
+// tsType renders the TypeScript type of a schema. indent is the indentation of the line the type
+// starts on, used for the members of inline object types
+func tsType(schema *Schema, indent string) string {
+	if schema == nil {
+		return "unknown"
+	}
+
+	expression := tsBaseType(schema, indent)
+	if schema.Nullable != nil && *schema.Nullable && expression != "unknown" {
+		expression = tsWrap(expression) + " | null"
+	}
+
+	return expression
+}
+
+func tsBaseType(schema *Schema, indent string) string {
+	if schema.Ref != nil {
+		return tsTypeName(strings.TrimPrefix(*schema.Ref, "#/components/schemas/"))
+	}
+
+	if len(schema.AllOf) > 0 {
+		members := make([]string, 0, len(schema.AllOf)+1)
+		for _, member := range schema.AllOf {
+			members = append(members, tsWrap(tsType(member, indent)))
+		}
+		if len(schema.Properties) > 0 {
+			members = append(members, tsObject(schema, indent))
+		}
+		return strings.Join(members, " & ")
+	}
+
+	alternatives := schema.OneOf
+	if len(alternatives) == 0 {
+		alternatives = schema.AnyOf
+	}
+	if len(alternatives) > 0 {
+		members := make([]string, 0, len(alternatives))
+		for _, alternative := range alternatives {
+			members = append(members, tsWrap(tsType(alternative, indent)))
+		}
+		return strings.Join(members, " | ")
+	}
+
+	if len(schema.Enum) > 0 {
+		members := make([]string, 0, len(schema.Enum))
+		for _, value := range schema.Enum {
+			literal, err := json.Marshal(value)
+			if err != nil {
+				continue
+			}
+			members = append(members, string(literal))
+		}
+		return strings.Join(members, " | ")
+	}
+
+	switch schemaType(schema) {
+	case "object":
+		if len(schema.Properties) == 0 {
+			return "Record<string, unknown>"
+		}
+		return tsObject(schema, indent)
+	case "array":
+		return tsWrap(tsType(schema.Items, indent)) + "[]"
+	case "string":
+		return "string"
+	case "integer", "number":
+		return "number"
+	case "boolean":
+		return "boolean"
+	}
+
+	return "unknown"
+}
+
+// tsObject renders the properties of an object schema as an object type literal
+func tsObject(schema *Schema, indent string) string {
+	required := map[string]bool{}
+	for _, name := range schema.Required {
+		if name != nil {
+			required[*name] = true
+		}
+	}
+
+	var builder strings.Builder
+	builder.WriteString("{\n")
+	for _, name := range sortedKeys(schema.Properties) {
+		property := schema.Properties[name]
+
+		optional := "?"
+		if required[name] {
+			optional = ""
+		}
+
+		builder.WriteString(tsDoc(schemaDoc(property), indent+"  "))
+		fmt.Fprintf(&builder, "%s  %s%s: %s;\n", indent, tsPropertyName(name), optional, tsType(property, indent+"  "))
+	}
+	builder.WriteString(indent + "}")
+
+	return builder.String()
+}
+
+// tsParams renders the parameters of an operation grouped by location. A group is optional when
+// none of its parameters is required
+func tsParams(params []*RouteParam, indent string) string {
+	if len(params) == 0 {
+		return "{}"
+	}
+
+	var builder strings.Builder
+	builder.WriteString("{\n")
+	for _, in := range []string{"path", "query", "header", "cookie"} {
+		var group []*RouteParam
+		groupRequired := false
+		for _, param := range params {
+			if param.In == in {
+				group = append(group, param)
+				groupRequired = groupRequired || param.Required
+			}
+		}
+		if len(group) == 0 {
+			continue
+		}
+
+		optional := "?"
+		if groupRequired {
+			optional = ""
+		}
+
+		fmt.Fprintf(&builder, "%s  %s%s: {\n", indent, in, optional)
+		for _, param := range group {
+			paramOptional := "?"
+			if param.Required {
+				paramOptional = ""
+			}
+			fmt.Fprintf(&builder, "%s    %s%s: %s;\n", indent, tsPropertyName(param.Name), paramOptional, tsType(param.Schema, indent+"    "))
+		}
+		fmt.Fprintf(&builder, "%s  };\n", indent)
+	}
+	builder.WriteString(indent + "}")
+
+	return builder.String()
+}
+
+// tsRequestBody is the type of the request body of an operation, preferring a JSON content type.
+// Operations without a body use never
+func tsRequestBody(body *RouteBody) string {
+	if body == nil {
+		return "never"
+	}
+
+	contentType := body.ContentTypes[0]
+	for _, declared := range body.ContentTypes {
+		if isJSONMediaType(declared) {
+			contentType = declared
+			break
+		}
+	}
+
+	bodyType := tsContentType(contentType, body.Schemas[contentType], "    ")
+	if !body.Required {
+		bodyType = tsWrap(bodyType) + " | undefined"
+	}
+
+	return bodyType
+}
+
+// tsResponses renders the body type of every declared response keyed by status. Responses without
+// content have an undefined body
+func tsResponses(responses map[string]*Response, indent string) string {
+	if len(responses) == 0 {
+		return "{}"
+	}
+
+	var builder strings.Builder
+	builder.WriteString("{\n")
+	for _, code := range sortedKeys(responses) {
+		response := responses[code]
+
+		bodyType := "undefined"
+		if response != nil && len(response.Content) > 0 {
+			contentTypes := sortedKeys(response.Content)
+			contentType := contentTypes[0]
+			for _, declared := range contentTypes {
+				if isJSONMediaType(declared) {
+					contentType = declared
+					break
+				}
+			}
+
+			var schema *Schema
+			if content := response.Content[contentType]; content != nil {
+				schema = content.Schema
+			}
+			bodyType = tsContentType(contentType, schema, indent+"  ")
+		}
+
+		if response != nil && response.Description != nil {
+			builder.WriteString(tsDoc(strings.Join(strings.Fields(*response.Description), " "), indent+"  "))
+		}
+		fmt.Fprintf(&builder, "%s  %s: %s;\n", indent, tsPropertyName(code), bodyType)
+	}
+	builder.WriteString(indent + "}")
+
+	return builder.String()
+}
+
+// tsContentType is the type of a body: the schema type for JSON, string for text content types
+// without a schema and unknown otherwise
+func tsContentType(contentType string, schema *Schema, indent string) string {
+	if schema != nil {
+		return tsType(schema, indent)
+	}
+	if strings.HasPrefix(mediaType(contentType), "text/") {
+		return "string"
+	}
+
+	return "unknown"
+}
+
+// tsWrap parenthesizes unions and intersections used inside another type
+func tsWrap(expression string) string {
+	if strings.Contains(expression, " | ") || strings.Contains(expression, " & ") {
+		return "(" + expression + ")"
+	}
+
+	return expression
+}
+
+// tsTypeName converts a component schema name into a TypeScript identifier
+func tsTypeName(name string) string {
+	identifier := invalidIdentifierChars.ReplaceAllString(name, "_")
+	if identifier == "" || (identifier[0] >= '0' && identifier[0] <= '9') {
+		identifier = "_" + identifier
+	}
+
+	return identifier
+}
+
+// tsPropertyName quotes property names that are not identifiers or numbers
+func tsPropertyName(name string) string {
+	if tsIdentifier.MatchString(name) {
+		return name
+	}
+	if _, err := strconv.Atoi(name); err == nil && !strings.HasPrefix(name, "0") {
+		return name
+	}
+
+	return strconv.Quote(name)
+}
+
+// tsDoc renders a JSDoc comment line, or nothing for an empty description
+func tsDoc(doc string, indent string) string {
+	if doc == "" {
+		return ""
+	}
+
+	return indent + "/** " + strings.ReplaceAll(doc, "*/", "*\\/") + " */\n"
+}
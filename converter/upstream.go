@@ -0,0 +1,187 @@
+package converter
+
+import (
+	"fmt"
+	"github.com/nimling/openapi-converter/utils"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var upstreamBalancing = map[string]string{
+	"":            "",
+	"round_robin": "",
+	"least_conn":  "least_conn",
+	"ip_hash":     "ip_hash",
+	"random":      "random",
+}
+
+var invalidIdentifierChars = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+
+type nginxUpstream struct {
+	Name      string
+	Scheme    string
+	Host      string
+	BasePath  string
+	Balancing string
+	Keepalive int
+	Servers   []nginxUpstreamServer
+}
+
+type nginxUpstreamServer struct {
+	Address    string
+	Parameters string
+}
+
+// ProxyPass is the proxy_pass target pointing at the named upstream. It carries no URI because
+// regex locations do not allow one, the base path is added by the location rewrites instead
+func (u *nginxUpstream) ProxyPass() string {
+	return u.Scheme + "://" + u.Name
+}
+
+// WriteNginxUpstream renders the upstream block the generated locations proxy to.
+// Servers are taken from the x-upstream extension when present, otherwise from all servers entries
+func (n *OpenAPIConverter) WriteNginxUpstream() (string, error) {
+	table, err := n.RouteTable()
+	if err != nil {
+		return "", err
+	}
+
+	upstream, err := nginxUpstreamFor(table)
+	if err != nil {
+		return "", err
+	}
+
+	return utils.ExecuteTemplate(utils.FormatRaw, "upstream", upstreamTemplate, upstream)
+}
+
+func (n *OpenAPIConverter) upstreamName() string {
+	if n.doc.Upstream != nil && n.doc.Upstream.Name != "" {
+		return n.doc.Upstream.Name
+	}
+
+	return n.specName() + "_upstream"
+}
+
+// specName is the prefixed spec file name reduced to characters valid in Nginx identifiers
+func (n *OpenAPIConverter) specName() string {
+	base := strings.TrimSuffix(filepath.Base(n.filePath), filepath.Ext(n.filePath))
+	return invalidIdentifierChars.ReplaceAllString(n.FilePrefix+base, "_")
+}
+
+// upstreamServers returns the x-upstream servers, or all servers entries with their variables expanded
+func (n *OpenAPIConverter) upstreamServers() []*UpstreamServer {
+	if n.doc.Upstream != nil && len(n.doc.Upstream.Servers) > 0 {
+		return n.doc.Upstream.Servers
+	}
+
+	var servers []*UpstreamServer
+	for _, server := range n.doc.Servers {
+		servers = append(servers, &UpstreamServer{URL: n.expandServerURL(server)})
+	}
+
+	return servers
+}
+
+// serverURL splits the server URL for gateways that address host and port separately
+func (s *RouteServer) serverURL() serverURL {
+	return serverURL{Scheme: s.Scheme, Host: s.Host, Port: s.Port}
+}
+
+// Address returns host:port, defaulting the port from the scheme
+func (s *RouteServer) Address() string {
+	return s.serverURL().Address()
+}
+
+// PortOrDefault returns the explicit port or the default port of the scheme
+func (s *RouteServer) PortOrDefault() string {
+	return s.serverURL().PortOrDefault()
+}
+
+// Origin returns scheme://host with the port only when it was given explicitly
+func (s *RouteServer) Origin() string {
+	return s.serverURL().Origin()
+}
+
+// routeUpstream collects the upstream servers, which must share one scheme and base path since
+// every route is proxied to the same target path
+func (n *OpenAPIConverter) routeUpstream() (*RouteUpstream, error) {
+	servers := n.upstreamServers()
+	result := &RouteUpstream{Name: n.upstreamName()}
+
+	if n.doc.Upstream != nil {
+		if _, ok := upstreamBalancing[n.doc.Upstream.Balancing]; !ok {
+			return nil, fmt.Errorf("file '%s': unsupported x-upstream balancing '%s'", n.filePath, n.doc.Upstream.Balancing)
+		}
+
+		result.Balancing = n.doc.Upstream.Balancing
+		result.Keepalive = n.doc.Upstream.Keepalive
+	}
+
+	for i, server := range servers {
+		parsed, err := parseServerURL(server.URL)
+		if err != nil {
+			return nil, fmt.Errorf("file '%s': upstream %w", n.filePath, err)
+		}
+
+		if i == 0 {
+			result.Scheme = parsed.Scheme
+			result.Host = parsed.Host
+			result.BasePath = parsed.Path
+		} else if parsed.Scheme != result.Scheme || parsed.Path != result.BasePath {
+			return nil, fmt.Errorf("file '%s': upstream server '%s' must use the same scheme and base path as '%s'", n.filePath, server.URL, servers[0].URL)
+		}
+
+		result.Servers = append(result.Servers, &RouteServer{
+			URL:         server.URL,
+			Scheme:      parsed.Scheme,
+			Host:        parsed.Host,
+			Port:        parsed.Port,
+			Weight:      server.Weight,
+			MaxFails:    server.MaxFails,
+			FailTimeout: server.FailTimeout,
+			Backup:      server.Backup,
+		})
+	}
+
+	return result, nil
+}
+
+// nginxUpstreamFor renders the upstream servers as Nginx server parameters
+func nginxUpstreamFor(table *RouteTable) (*nginxUpstream, error) {
+	upstream := table.Upstream
+	result := &nginxUpstream{
+		Name:      upstream.Name,
+		Scheme:    upstream.Scheme,
+		Host:      upstream.Host,
+		BasePath:  upstream.BasePath,
+		Balancing: upstreamBalancing[upstream.Balancing],
+		Keepalive: upstream.Keepalive,
+	}
+
+	for _, server := range upstream.Servers {
+		var params []string
+		if server.Weight > 0 {
+			params = append(params, fmt.Sprintf("weight=%d", server.Weight))
+		}
+		if server.MaxFails > 0 {
+			params = append(params, fmt.Sprintf("max_fails=%d", server.MaxFails))
+		}
+		if server.FailTimeout != "" {
+			params = append(params, "fail_timeout="+server.FailTimeout)
+		}
+		if server.Backup {
+			if result.Balancing != "" && result.Balancing != "least_conn" {
+				return nil, fmt.Errorf("file '%s': backup server '%s' cannot be combined with %s balancing", table.FilePath, server.URL, result.Balancing)
+			}
+			params = append(params, "backup")
+		}
+
+		result.Servers = append(result.Servers, nginxUpstreamServer{
+			Address:    server.Address(),
+			Parameters: strings.Join(params, " "),
+		})
+	}
+
+	return result, nil
+}
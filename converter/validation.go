@@ -0,0 +1,479 @@
+package converter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxRefDepth bounds how many references are followed in a row, so cyclic component schemas
+// cannot loop forever
+const maxRefDepth = 32
+
+// Problem is an RFC 7807 problem detail, the body of every error answered by the generated
+// validators and the mock server
+type Problem struct {
+	Type   string   `json:"type"`
+	Title  string   `json:"title"`
+	Status int      `json:"status"`
+	Detail string   `json:"detail,omitempty"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+// NewProblem creates a problem for a status code with the individual errors joined as detail
+func NewProblem(status int, errors ...string) *Problem {
+	return &Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: strings.Join(errors, "; "),
+		Errors: errors,
+	}
+}
+
+func (p *Problem) Error() string {
+	if p.Detail == "" {
+		return fmt.Sprintf("%d %s", p.Status, p.Title)
+	}
+
+	return fmt.Sprintf("%d %s: %s", p.Status, p.Title, p.Detail)
+}
+
+// Write sends the problem as an application/problem+json response
+func (p *Problem) Write(w http.ResponseWriter) {
+	body, _ := json.Marshal(p)
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(p.Status)
+	_, _ = w.Write(body)
+}
+
+// RequestMatch is the route a request path selected. Operation is nil when the route has no
+// operation for the request method
+type RequestMatch struct {
+	Route      *Route
+	Operation  *RouteOperation
+	PathParams map[string]string
+}
+
+type routeMatcher struct {
+	route   *Route
+	pattern *regexp.Regexp
+}
+
+// MatchRequest finds the route a gateway would select for a request: routes are tried in the
+// order of OrderedRoutes with their Match regex, and the path parameters are read from the
+// segments of templated paths. It returns nil when no route matches
+func (t *RouteTable) MatchRequest(method string, path string) *RequestMatch {
+	t.compileMatchers.Do(func() {
+		for _, route := range t.OrderedRoutes() {
+			pattern := route.Match.Regex
+			if route.Match.Templated {
+				pattern = strings.ReplaceAll(pattern, "[^/]+", "([^/]+)")
+			}
+			t.matchers = append(t.matchers, routeMatcher{route: route, pattern: regexp.MustCompile(pattern)})
+		}
+	})
+
+	for _, matcher := range t.matchers {
+		values := matcher.pattern.FindStringSubmatch(path)
+		if values == nil {
+			continue
+		}
+
+		match := &RequestMatch{Route: matcher.route, PathParams: map[string]string{}}
+		for i, name := range matcher.route.Match.Params {
+			if i+1 < len(values) {
+				match.PathParams[name] = values[i+1]
+			}
+		}
+		for _, op := range matcher.route.Operations {
+			if op.Method == method {
+				match.Operation = op
+			}
+		}
+
+		return match
+	}
+
+	return nil
+}
+
+// ValidateRequest checks a request against the operation it matched: required parameters and
+// their types, the Content-Type of the body and JSON bodies against the request body schema. The
+// body is read and replaced, so handlers after the validation can still read it
+func (t *RouteTable) ValidateRequest(r *http.Request, match *RequestMatch) *Problem {
+	var errors []string
+
+	for _, param := range match.Route.OperationParams(match.Operation) {
+		var values []string
+		switch param.In {
+		case "path":
+			if value, ok := match.PathParams[param.Name]; ok {
+				values = []string{value}
+			}
+		case "query":
+			values = r.URL.Query()[param.Name]
+		case "header":
+			values = r.Header.Values(param.Name)
+		case "cookie":
+			if cookie, err := r.Cookie(param.Name); err == nil {
+				values = []string{cookie.Value}
+			}
+		}
+		errors = append(errors, t.validateParam(param, values)...)
+	}
+
+	body := match.Operation.Body
+	if body != nil {
+		var data []byte
+		if r.Body != nil {
+			var err error
+			if data, err = io.ReadAll(r.Body); err != nil {
+				return NewProblem(http.StatusBadRequest, "failed to read request body: "+err.Error())
+			}
+			r.Body = io.NopCloser(bytes.NewReader(data))
+		}
+
+		contentType := mediaType(r.Header.Get("Content-Type"))
+		switch {
+		case len(data) == 0:
+			if body.Required {
+				errors = append(errors, "request body is required")
+			}
+		case !acceptsMediaType(body.ContentTypes, contentType):
+			if contentType == "" {
+				contentType = "none"
+			}
+			return NewProblem(http.StatusUnsupportedMediaType, fmt.Sprintf("content type %s is not one of %s", contentType, strings.Join(body.ContentTypes, ", ")))
+		case isJSONMediaType(contentType):
+			if schema := bodySchema(body.Schemas, contentType); schema != nil {
+				var value interface{}
+				if err := json.Unmarshal(data, &value); err != nil {
+					errors = append(errors, "body is not valid JSON")
+				} else {
+					errors = append(errors, t.ValidateValue(schema, value, "body")...)
+				}
+			}
+		}
+	}
+
+	if len(errors) > 0 {
+		return NewProblem(http.StatusBadRequest, errors...)
+	}
+
+	return nil
+}
+
+// validateParam checks the raw values of a parameter, converting them to the schema type first
+func (t *RouteTable) validateParam(param *RouteParam, values []string) []string {
+	return t.validateValues(param.In+" parameter "+param.Name, param.Schema, param.Required, values)
+}
+
+// validateValues checks the raw values of a parameter or header, converting them to the schema
+// type first
+func (t *RouteTable) validateValues(label string, schema *Schema, required bool, values []string) []string {
+	if len(values) == 0 {
+		if required {
+			return []string{label + " is required"}
+		}
+		return nil
+	}
+
+	// Objects serialized into parameters are not checked
+	schema = t.resolveSchema(schema)
+	if schema == nil || schema.Type == nil || *schema.Type == "object" {
+		return nil
+	}
+
+	if *schema.Type == "array" {
+		// Arrays arrive as repeated values or comma separated
+		var items []string
+		for _, value := range values {
+			items = append(items, strings.Split(value, ",")...)
+		}
+
+		var errors []string
+		if schema.Items != nil {
+			for _, item := range items {
+				errors = append(errors, t.validateValues(label, schema.Items, false, []string{item})...)
+			}
+		}
+		return errors
+	}
+
+	value, err := parseParamValue(*schema.Type, values[0])
+	if err != nil {
+		return []string{label + " " + err.Error()}
+	}
+
+	return t.ValidateValue(schema, value, label)
+}
+
+// parseParamValue converts a parameter value to the JSON value of its schema type
+func parseParamValue(schemaType string, value string) (interface{}, error) {
+	switch schemaType {
+	case "integer", "number":
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return nil, fmt.Errorf("must be of type %s", schemaType)
+		}
+		return parsed, nil
+	case "boolean":
+		if value != "true" && value != "false" {
+			return nil, fmt.Errorf("must be true or false")
+		}
+		return value == "true", nil
+	}
+
+	return value, nil
+}
+
+// ValidateResponse checks a response of an operation: its status has to be declared, the
+// required headers of the declared response present and of their schema type, and the body of a
+// declared content type matching the schema when it is JSON
+func (t *RouteTable) ValidateResponse(op *RouteOperation, status int, header http.Header, body []byte) []string {
+	response := op.Response(status)
+	if response == nil {
+		return []string{fmt.Sprintf("status %d is not declared, expected one of %s", status, strings.Join(sortedKeys(op.Responses), ", "))}
+	}
+
+	var errors []string
+	for _, name := range sortedKeys(response.Headers) {
+		if declared := response.Headers[name]; declared != nil && !strings.EqualFold(name, "Content-Type") {
+			errors = append(errors, t.validateValues("response header "+name, declared.Schema, declared.Required, header.Values(name))...)
+		}
+	}
+
+	if len(response.Content) == 0 {
+		return errors
+	}
+
+	contentTypes := sortedKeys(response.Content)
+	contentType := mediaType(header.Get("Content-Type"))
+	switch {
+	case len(body) == 0:
+		errors = append(errors, fmt.Sprintf("response body is empty, expected %s", strings.Join(contentTypes, ", ")))
+	case !acceptsMediaType(contentTypes, contentType):
+		if contentType == "" {
+			contentType = "none"
+		}
+		errors = append(errors, fmt.Sprintf("response content type %s is not one of %s", contentType, strings.Join(contentTypes, ", ")))
+	case isJSONMediaType(contentType):
+		schemas := map[string]*Schema{}
+		for declared, content := range response.Content {
+			if content != nil && content.Schema != nil {
+				schemas[declared] = content.Schema
+			}
+		}
+
+		if schema := bodySchema(schemas, contentType); schema != nil {
+			var value interface{}
+			if err := json.Unmarshal(body, &value); err != nil {
+				errors = append(errors, "response body is not valid JSON")
+			} else {
+				errors = append(errors, t.ValidateValue(schema, value, "response")...)
+			}
+		}
+	}
+
+	return errors
+}
+
+// ValidateValue checks a decoded JSON value against a schema and returns one message per
+// violation, prefixed with the path of the offending value
+func (t *RouteTable) ValidateValue(schema *Schema, value interface{}, path string) []string {
+	var errors []string
+	t.checkValue(schema, value, path, &errors)
+	return errors
+}
+
+func (t *RouteTable) checkValue(schema *Schema, value interface{}, path string, errors *[]string) {
+	schema = t.resolveSchema(schema)
+	if schema == nil {
+		return
+	}
+
+	if value == nil && ((schema.Nullable != nil && *schema.Nullable) || (schema.Type != nil && *schema.Type == "null")) {
+		return
+	}
+
+	for _, item := range schema.AllOf {
+		t.checkValue(item, value, path, errors)
+	}
+	if len(schema.OneOf) > 0 {
+		matched := 0
+		for _, item := range schema.OneOf {
+			if len(t.ValidateValue(item, value, path)) == 0 {
+				matched++
+			}
+		}
+		if matched != 1 {
+			*errors = append(*errors, path+" must match exactly one of the allowed schemas")
+		}
+	}
+	if len(schema.AnyOf) > 0 {
+		matched := false
+		for _, item := range schema.AnyOf {
+			matched = matched || len(t.ValidateValue(item, value, path)) == 0
+		}
+		if !matched {
+			*errors = append(*errors, path+" must match at least one of the allowed schemas")
+		}
+	}
+
+	if schema.Type != nil && !typeMatches(*schema.Type, value) {
+		*errors = append(*errors, path+" must be of type "+*schema.Type)
+		return
+	}
+
+	if len(schema.Enum) > 0 && !enumContains(schema.Enum, value) {
+		allowed, _ := json.Marshal(schema.Enum)
+		*errors = append(*errors, fmt.Sprintf("%s must be one of %s", path, allowed))
+	}
+
+	switch value := value.(type) {
+	case float64:
+		if schema.Minimum != nil && value < *schema.Minimum {
+			*errors = append(*errors, fmt.Sprintf("%s must be at least %v", path, *schema.Minimum))
+		}
+		if schema.Maximum != nil && value > *schema.Maximum {
+			*errors = append(*errors, fmt.Sprintf("%s must be at most %v", path, *schema.Maximum))
+		}
+	case string:
+		length := utf8.RuneCountInString(value)
+		if schema.MinLength != nil && length < *schema.MinLength {
+			*errors = append(*errors, fmt.Sprintf("%s must be at least %d characters long", path, *schema.MinLength))
+		}
+		if schema.MaxLength != nil && length > *schema.MaxLength {
+			*errors = append(*errors, fmt.Sprintf("%s must be at most %d characters long", path, *schema.MaxLength))
+		}
+	case map[string]interface{}:
+		for _, name := range schema.Required {
+			if name == nil {
+				continue
+			}
+			if _, ok := value[*name]; !ok {
+				*errors = append(*errors, path+"."+*name+" is required")
+			}
+		}
+		for _, name := range sortedKeys(schema.Properties) {
+			if property, ok := value[name]; ok {
+				t.checkValue(schema.Properties[name], property, path+"."+name, errors)
+			}
+		}
+	case []interface{}:
+		if schema.MinItems != nil && len(value) < *schema.MinItems {
+			*errors = append(*errors, fmt.Sprintf("%s must have at least %d items", path, *schema.MinItems))
+		}
+		if schema.MaxItems != nil && len(value) > *schema.MaxItems {
+			*errors = append(*errors, fmt.Sprintf("%s must have at most %d items", path, *schema.MaxItems))
+		}
+		if schema.Items != nil {
+			for i, item := range value {
+				t.checkValue(schema.Items, item, fmt.Sprintf("%s[%d]", path, i), errors)
+			}
+		}
+	}
+}
+
+// resolveSchema follows references to component schemas
+func (t *RouteTable) resolveSchema(schema *Schema) *Schema {
+	for depth := 0; schema != nil && schema.Ref != nil && depth < maxRefDepth; depth++ {
+		schema = t.Schemas[strings.TrimPrefix(*schema.Ref, "#/components/schemas/")]
+	}
+
+	return schema
+}
+
+// enumContains reports whether a decoded JSON value is one of the enum values. Values are compared
+// by their JSON encoding, since YAML decodes numbers as integers and JSON as floats
+func enumContains(enum []interface{}, value interface{}) bool {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return false
+	}
+
+	for _, allowed := range enum {
+		if candidate, err := json.Marshal(allowed); err == nil && bytes.Equal(candidate, encoded) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// typeMatches reports whether a decoded JSON value is of a schema type. Unknown types match
+// any value
+func typeMatches(schemaType string, value interface{}) bool {
+	switch schemaType {
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "integer":
+		number, ok := value.(float64)
+		return ok && math.Floor(number) == number
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "null":
+		return value == nil
+	}
+
+	return true
+}
+
+// mediaType returns the lower-cased media type of a Content-Type or Accept entry without its
+// parameters
+func mediaType(value string) string {
+	return strings.ToLower(strings.TrimSpace(strings.SplitN(value, ";", 2)[0]))
+}
+
+// acceptsMediaType reports whether a media type is one of the declared content types, which may
+// use wildcards such as image/* or */*
+func acceptsMediaType(contentTypes []string, value string) bool {
+	for _, accepted := range contentTypes {
+		if mediaTypeMatches(mediaType(accepted), value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func mediaTypeMatches(pattern string, value string) bool {
+	return pattern == value || pattern == "*/*" ||
+		(strings.HasSuffix(pattern, "/*") && strings.HasPrefix(value, strings.TrimSuffix(pattern, "*")))
+}
+
+// bodySchema returns the schema declared for a content type, looking at the exact content type
+// first and at wildcard content types afterwards
+func bodySchema(schemas map[string]*Schema, contentType string) *Schema {
+	for _, declared := range sortedKeys(schemas) {
+		if mediaType(declared) == contentType {
+			return schemas[declared]
+		}
+	}
+	for _, declared := range sortedKeys(schemas) {
+		if mediaTypeMatches(mediaType(declared), contentType) {
+			return schemas[declared]
+		}
+	}
+
+	return nil
+}
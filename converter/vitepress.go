@@ -57,7 +57,7 @@ func (n *OpenAPIConverter) WriteMarkdown(specPath string, outputPath string) err
 		FilePrefix:   n.FilePrefix,
 	}
 
-	fileContent, err := utils.ExecuteTemplate("tags", oaTagsTemplate, data)
+	fileContent, err := renderMarkdownPage("tags", oaTagsFrontmatter, oaTagsTemplate, data)
 	if err != nil {
 		return fmt.Errorf("failed to execute markdown template: %w", err)
 	}
@@ -66,7 +66,7 @@ func (n *OpenAPIConverter) WriteMarkdown(specPath string, outputPath string) err
 		return fmt.Errorf("failed to write [tag].md]: %w", err)
 	}
 
-	fileContent, err = utils.ExecuteTemplate("paths", oaPathsTemplate, data)
+	fileContent, err = utils.ExecuteTemplate(utils.FormatRaw, "paths", oaPathsTemplate, data)
 	if err != nil {
 		return fmt.Errorf("failed to execute markdown template: %w", err)
 	}
@@ -76,7 +76,7 @@ func (n *OpenAPIConverter) WriteMarkdown(specPath string, outputPath string) err
 	}
 
 	if n.WriteIntroduction {
-		fileContent, err = utils.ExecuteTemplate("introduction", oaIntroductionTemplate, data)
+		fileContent, err = renderMarkdownPage("introduction", oaIntroductionFrontmatter, oaIntroductionTemplate, data)
 		if err != nil {
 			return fmt.Errorf("failed to execute markdown template: %w", err)
 		}
@@ -89,6 +89,22 @@ func (n *OpenAPIConverter) WriteMarkdown(specPath string, outputPath string) err
 	return nil
 }
 
+// renderMarkdownPage renders the YAML frontmatter verbatim, with its values quoted, and escapes the
+// Markdown body, which embeds Vue/HTML components
+func renderMarkdownPage(name string, frontmatter string, body string, data interface{}) (string, error) {
+	header, err := utils.ExecuteTemplate(utils.FormatRaw, name+"-frontmatter", frontmatter, data)
+	if err != nil {
+		return "", err
+	}
+
+	content, err := utils.ExecuteTemplate(utils.FormatHTML, name, body, data)
+	if err != nil {
+		return "", err
+	}
+
+	return header + content, nil
+}
+
 func (n *OpenAPIConverter) writeOpenAPISpec(outputPath string) error {
 
 	err := os.MkdirAll(path.Dir(outputPath), 0755)
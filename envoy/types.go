@@ -0,0 +1,145 @@
+package envoy
+
+// Config holds the route configuration and the clusters its routes point at
+type Config struct {
+	RouteConfig *RouteConfiguration `yaml:"route_config" json:"route_config"`
+	Clusters    []*Cluster          `yaml:"clusters" json:"clusters"`
+}
+
+// RouteConfiguration is an HTTP connection manager route table
+type RouteConfiguration struct {
+	Name         string         `yaml:"name" json:"name"`
+	VirtualHosts []*VirtualHost `yaml:"virtual_hosts" json:"virtual_hosts"`
+}
+
+// VirtualHost groups the routes served for a set of domains
+type VirtualHost struct {
+	Name    string   `yaml:"name" json:"name"`
+	Domains []string `yaml:"domains" json:"domains"`
+	Routes  []*Route `yaml:"routes" json:"routes"`
+}
+
+// Route matches a request and forwards it to a cluster
+type Route struct {
+	Name                    string         `yaml:"name,omitempty" json:"name,omitempty"`
+	Match                   *RouteMatch    `yaml:"match" json:"match"`
+	Route                   *RouteAction   `yaml:"route" json:"route"`
+	ResponseHeadersToAdd    []*HeaderValue `yaml:"response_headers_to_add,omitempty" json:"response_headers_to_add,omitempty"`
+	ResponseHeadersToRemove []string       `yaml:"response_headers_to_remove,omitempty" json:"response_headers_to_remove,omitempty"`
+}
+
+// RouteMatch selects requests by path prefix or regex and by headers such as :method
+type RouteMatch struct {
+	Prefix    string           `yaml:"prefix,omitempty" json:"prefix,omitempty"`
+	Path      string           `yaml:"path,omitempty" json:"path,omitempty"`
+	SafeRegex *RegexMatcher    `yaml:"safe_regex,omitempty" json:"safe_regex,omitempty"`
+	Headers   []*HeaderMatcher `yaml:"headers,omitempty" json:"headers,omitempty"`
+}
+
+// RegexMatcher is an RE2 regex
+type RegexMatcher struct {
+	Regex string `yaml:"regex" json:"regex"`
+}
+
+// HeaderMatcher matches a request header against an exact value
+type HeaderMatcher struct {
+	Name        string       `yaml:"name" json:"name"`
+	StringMatch *StringMatch `yaml:"string_match" json:"string_match"`
+}
+
+// StringMatch compares a value exactly
+type StringMatch struct {
+	Exact string `yaml:"exact" json:"exact"`
+}
+
+// RouteAction forwards the request to a cluster, optionally rewriting the path
+type RouteAction struct {
+	Cluster      string                   `yaml:"cluster" json:"cluster"`
+	RegexRewrite *RegexMatchAndSubstitute `yaml:"regex_rewrite,omitempty" json:"regex_rewrite,omitempty"`
+	Timeout      string                   `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+	HashPolicy   []*HashPolicy            `yaml:"hash_policy,omitempty" json:"hash_policy,omitempty"`
+}
+
+// RegexMatchAndSubstitute rewrites the matched part of the path
+type RegexMatchAndSubstitute struct {
+	Pattern      *RegexMatcher `yaml:"pattern" json:"pattern"`
+	Substitution string        `yaml:"substitution" json:"substitution"`
+}
+
+// HashPolicy selects the value consistent hashing load balancers hash on
+type HashPolicy struct {
+	ConnectionProperties *ConnectionProperties `yaml:"connection_properties,omitempty" json:"connection_properties,omitempty"`
+}
+
+// ConnectionProperties hashes on properties of the downstream connection
+type ConnectionProperties struct {
+	SourceIP bool `yaml:"source_ip" json:"source_ip"`
+}
+
+// HeaderValue is a header added to a response
+type HeaderValue struct {
+	Header       *Header `yaml:"header" json:"header"`
+	AppendAction string  `yaml:"append_action,omitempty" json:"append_action,omitempty"`
+}
+
+// Header is a header name and value
+type Header struct {
+	Key   string `yaml:"key" json:"key"`
+	Value string `yaml:"value" json:"value"`
+}
+
+// Cluster is a group of upstream endpoints
+type Cluster struct {
+	Name            string                 `yaml:"name" json:"name"`
+	Type            string                 `yaml:"type" json:"type"`
+	ConnectTimeout  string                 `yaml:"connect_timeout" json:"connect_timeout"`
+	LbPolicy        string                 `yaml:"lb_policy" json:"lb_policy"`
+	LoadAssignment  *ClusterLoadAssignment `yaml:"load_assignment" json:"load_assignment"`
+	TransportSocket *TransportSocket       `yaml:"transport_socket,omitempty" json:"transport_socket,omitempty"`
+}
+
+// ClusterLoadAssignment lists the endpoints of a cluster grouped by priority
+type ClusterLoadAssignment struct {
+	ClusterName string                 `yaml:"cluster_name" json:"cluster_name"`
+	Endpoints   []*LocalityLbEndpoints `yaml:"endpoints" json:"endpoints"`
+}
+
+// LocalityLbEndpoints is one priority level of endpoints
+type LocalityLbEndpoints struct {
+	Priority    int           `yaml:"priority,omitempty" json:"priority,omitempty"`
+	LbEndpoints []*LbEndpoint `yaml:"lb_endpoints" json:"lb_endpoints"`
+}
+
+// LbEndpoint is a single upstream host
+type LbEndpoint struct {
+	Endpoint            *Endpoint `yaml:"endpoint" json:"endpoint"`
+	LoadBalancingWeight int       `yaml:"load_balancing_weight,omitempty" json:"load_balancing_weight,omitempty"`
+}
+
+// Endpoint holds the address of an upstream host
+type Endpoint struct {
+	Address *Address `yaml:"address" json:"address"`
+}
+
+// Address wraps a socket address
+type Address struct {
+	SocketAddress *SocketAddress `yaml:"socket_address" json:"socket_address"`
+}
+
+// SocketAddress is a host and port
+type SocketAddress struct {
+	Address   string `yaml:"address" json:"address"`
+	PortValue int    `yaml:"port_value" json:"port_value"`
+}
+
+// TransportSocket configures TLS towards the upstream
+type TransportSocket struct {
+	Name        string              `yaml:"name" json:"name"`
+	TypedConfig *UpstreamTLSContext `yaml:"typed_config" json:"typed_config"`
+}
+
+// UpstreamTLSContext is the typed config of the TLS transport socket
+type UpstreamTLSContext struct {
+	Type string `yaml:"@type" json:"@type"`
+	SNI  string `yaml:"sni,omitempty" json:"sni,omitempty"`
+}
@@ -0,0 +1,137 @@
+package internal
+
+import (
+	"fmt"
+	"github.com/nimling/openapi-converter/converter"
+	"github.com/spf13/cobra"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ContractOptions holds the settings of a contract test run
+type ContractOptions struct {
+	BaseURL    string
+	JUnitPath  string
+	Headers    []string
+	Operations []string
+	Seed       int64
+	Timeout    time.Duration
+}
+
+var contractOptions ContractOptions
+
+func NewContractCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "test [file]",
+		Short: "Run contract tests of a specification against a running service",
+		Long: `Call every operation of a specification on a running service and check the responses.
+
+For every operation a request is built from:
+- The examples of required parameters, or values generated from their schemas
+- The example of the request body, or a body generated from its schema
+
+Every response is checked against the spec:
+- The status code has to be declared by the operation (exact, range such as 4XX, or default)
+- Required response headers have to be present and match their schema
+- The body has to use a declared content type and JSON bodies have to match the schema
+
+Examples:
+  # Test a local service
+  openapi-converter test api.yml --base-url http://localhost:7071
+
+  # Send credentials and write a JUnit report for CI
+  openapi-converter test api.yml --base-url http://localhost:7071 \
+    --header "Authorization: Bearer $TOKEN" --junit reports/contract.xml`,
+		Args: cobra.ExactArgs(1),
+		RunE: runContractCommand,
+	}
+
+	cmd.Flags().StringVar(&contractOptions.BaseURL, "base-url", "", "URL of the service the paths are appended to")
+	cmd.Flags().StringVar(&contractOptions.JUnitPath, "junit", "", "Write a JUnit XML report to this file")
+	cmd.Flags().StringArrayVarP(&contractOptions.Headers, "header", "H", nil, "Header sent with every request, e.g. \"Authorization: Bearer token\" (repeatable)")
+	cmd.Flags().StringSliceVar(&contractOptions.Operations, "operation", nil, "Only test the operation with this operationId (repeatable)")
+	cmd.Flags().Int64Var(&contractOptions.Seed, "seed", 1, "Seed of the generated parameters and bodies")
+	cmd.Flags().DurationVar(&contractOptions.Timeout, "timeout", 30*time.Second, "Timeout of every request")
+	_ = cmd.MarkFlagRequired("base-url")
+
+	return cmd
+}
+
+// RunContractTests calls the operations of a spec on the service at opts.BaseURL, prints one line
+// per operation to out and writes the JUnit report. It fails when any operation failed
+func RunContractTests(filePath string, opts ContractOptions, out io.Writer) error {
+	conv, err := converter.NewOpenApiConverter(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to load OpenAPI specification: %w", err)
+	}
+
+	runner, err := conv.ContractRunner(opts.BaseURL)
+	if err != nil {
+		return fmt.Errorf("failed to create contract runner: %w", err)
+	}
+
+	for _, header := range opts.Headers {
+		name, value, ok := strings.Cut(header, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			return fmt.Errorf("invalid header '%s', expected 'Name: value'", header)
+		}
+		runner.Headers.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+	runner.Operations = opts.Operations
+	runner.Seed = opts.Seed
+	if opts.Timeout > 0 {
+		runner.Client.Timeout = opts.Timeout
+	}
+
+	started := time.Now()
+	results := runner.Run()
+
+	failed := 0
+	for _, result := range results {
+		switch {
+		case result.Error != "":
+			failed++
+			fmt.Fprintf(out, "✗ %s: %s\n", result.Name(), result.Error)
+		case len(result.Failures) > 0:
+			failed++
+			fmt.Fprintf(out, "✗ %s: %d\n", result.Name(), result.Status)
+			for _, failure := range result.Failures {
+				fmt.Fprintf(out, "    %s\n", failure)
+			}
+		default:
+			fmt.Fprintf(out, "✓ %s: %d\n", result.Name(), result.Status)
+		}
+	}
+
+	if opts.JUnitPath != "" {
+		suite := strings.TrimSuffix(filepath.Base(filePath), filepath.Ext(filePath))
+		report, err := converter.WriteJUnitReport(suite, results, started)
+		if err != nil {
+			return err
+		}
+
+		if err := os.MkdirAll(filepath.Dir(opts.JUnitPath), 0755); err != nil {
+			return fmt.Errorf("failed to create report directory: %w", err)
+		}
+		if err := os.WriteFile(opts.JUnitPath, []byte(report), 0644); err != nil {
+			return fmt.Errorf("failed to write JUnit report: %w", err)
+		}
+		fmt.Fprintf(out, "✓ Generated JUnit report: %s\n", opts.JUnitPath)
+	}
+
+	if len(results) == 0 {
+		return fmt.Errorf("no operation of %s was tested", filePath)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d operations failed the contract", failed, len(results))
+	}
+
+	return nil
+}
+
+func runContractCommand(cmd *cobra.Command, args []string) error {
+	return RunContractTests(args[0], contractOptions, cmd.OutOrStdout())
+}
@@ -9,16 +9,42 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var (
-	outputDir            string
-	docsDir              string
-	indexPath            string
-	filePrefix           string
-	commonPrefix         string
-	writeIntroduction    bool
-	mergeResponsesInline bool
+// ConvertOptions holds the settings applied to every specification processed by a convert run
+type ConvertOptions struct {
+	OutputPath            string
+	DocsPath              string
+	IndexFilePath         string
+	FilePrefix            string
+	CommonPrefix          string
+	WriteIntroduction     bool
+	MergeResponses        bool
+	FillExamples          bool
+	ExampleSeed           int64
+	ServerVariables       map[string]string
+	ServerVarPlaceholders bool
+	Aggregate             string
+	IncludePath           string
+	TraefikPath           string
+	EnvoyPath             string
+	KongPath              string
+	CaddyPath             string
+	HAProxyPath           string
+	NjsPath               string
+	NjsImportPath         string
+	TypeScriptPath        string
+	PostmanPath           string
+	HTTPFilePath          string
+}
+
+const (
+	// AggregateServer writes every spec into one gateway server block
+	AggregateServer = "server"
+	// AggregateInclude writes a gateway server block including the per-spec location files
+	AggregateInclude = "include"
 )
 
+var convertOptions ConvertOptions
+
 func NewConvertCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "convert [files...]",
@@ -27,6 +53,14 @@ func NewConvertCommand() *cobra.Command {
 
 The convert command processes YAML/JSON API specifications and generates:
 - Nginx location configurations for API gateway routing
+- Traefik dynamic configuration for the file provider
+- Envoy route configuration and clusters
+- Kong declarative configuration for decK
+- Caddyfile fragments and HAProxy configuration
+- njs modules validating requests in the Nginx locations
+- TypeScript declarations of the schemas and operations
+- Postman collections with a request per operation
+- .http request files for editor REST clients
 - VitePress markdown documentation with interactive API references
 - Structured index files for documentation navigation
 
@@ -43,38 +77,151 @@ Examples:
 		RunE: runConvertCommand,
 	}
 	
-	cmd.Flags().StringVarP(&outputDir, "output", "o", "", "Output directory for Nginx configuration files")
-	cmd.Flags().StringVarP(&docsDir, "docs", "d", "", "Output directory for VitePress API documentation")
-	cmd.Flags().StringVarP(&indexPath, "index", "i", "", "Path to generate/update VitePress index.md with features")
-	cmd.Flags().StringVar(&filePrefix, "file-prefix", "", "Prefix for generated file names")
-	cmd.Flags().StringVar(&commonPrefix, "common-prefix", "", "URL path prefix for VitePress documentation links")
-	cmd.Flags().BoolVar(&writeIntroduction, "write-introduction", false, "Generate introduction page for API documentation")
-	cmd.Flags().BoolVar(&mergeResponsesInline, "merge-responses-inline", false, "Merge allOf response definitions into single inline objects")
+	cmd.Flags().StringVarP(&convertOptions.OutputPath, "output", "o", "", "Output directory for Nginx configuration files")
+	cmd.Flags().StringVarP(&convertOptions.DocsPath, "docs", "d", "", "Output directory for VitePress API documentation")
+	cmd.Flags().StringVarP(&convertOptions.IndexFilePath, "index", "i", "", "Path to generate/update VitePress index.md with features")
+	cmd.Flags().StringVar(&convertOptions.FilePrefix, "file-prefix", "", "Prefix for generated file names")
+	cmd.Flags().StringVar(&convertOptions.CommonPrefix, "common-prefix", "", "URL path prefix for VitePress documentation links")
+	cmd.Flags().BoolVar(&convertOptions.WriteIntroduction, "write-introduction", false, "Generate introduction page for API documentation")
+	cmd.Flags().BoolVar(&convertOptions.MergeResponses, "merge-responses-inline", false, "Merge allOf response definitions into single inline objects")
+	cmd.Flags().BoolVar(&convertOptions.FillExamples, "fill-examples", false, "Generate examples for request bodies, responses and parameters without one in the VitePress spec.json")
+	cmd.Flags().Int64Var(&convertOptions.ExampleSeed, "example-seed", 1, "Seed of the generated examples; the same seed produces the same examples")
+	cmd.Flags().StringToStringVar(&convertOptions.ServerVariables, "server-var", nil, "Override a server variable default, e.g. --server-var region=eu (repeatable)")
+	cmd.Flags().StringVar(&convertOptions.Aggregate, "aggregate", "", "Combine all specs into one gateway config: 'server' (single server block) or 'include' (include index)")
+	cmd.Flags().StringVar(&convertOptions.IncludePath, "include-path", "", "Directory the include index refers to for rendered <spec>.conf files")
+	cmd.Flags().StringVar(&convertOptions.TraefikPath, "traefik", "", "Output directory for Traefik file-provider configuration")
+	cmd.Flags().StringVar(&convertOptions.EnvoyPath, "envoy", "", "Output directory for Envoy route configuration and clusters")
+	cmd.Flags().StringVar(&convertOptions.KongPath, "kong", "", "Output directory for Kong decK declarative configuration")
+	cmd.Flags().StringVar(&convertOptions.CaddyPath, "caddy", "", "Output directory for Caddyfile fragments")
+	cmd.Flags().StringVar(&convertOptions.HAProxyPath, "haproxy", "", "Output directory for HAProxy frontend and backend configuration")
+	cmd.Flags().StringVar(&convertOptions.NjsPath, "njs", "", "Output directory for njs request validation modules; Nginx locations are wired to them")
+	cmd.Flags().StringVar(&convertOptions.NjsImportPath, "njs-path", "/etc/nginx/njs", "Directory Nginx loads the njs validation modules from")
+	cmd.Flags().StringVar(&convertOptions.TypeScriptPath, "typescript", "", "Output directory for TypeScript .d.ts declarations of the schemas and operations")
+	cmd.Flags().StringVar(&convertOptions.PostmanPath, "postman", "", "Output directory for Postman Collection v2.1 files")
+	cmd.Flags().StringVar(&convertOptions.HTTPFilePath, "http", "", "Output directory for .http request files, one per tag, for the VS Code REST Client and JetBrains HTTP Client")
+	cmd.Flags().BoolVar(&convertOptions.ServerVarPlaceholders, "server-var-placeholders", false, "Emit server variables as envsubst placeholders such as ${REGION} in Nginx output")
 	
 	return cmd
 }
 
+// convertRun carries the state shared by all files of one convert invocation
+type convertRun struct {
+	opts        ConvertOptions
+	httpContext *converter.HTTPContext
+	aggregate   *converter.GatewayAggregate
+}
+
 func RunConvert(args []string, outputPath, docsPath, indexFilePath, filePrefixStr, commonPrefixStr string, writeIntro, mergeResponses bool) error {
-	if outputPath != "" {
-		if err := os.MkdirAll(outputPath, 0755); err != nil {
+	return RunConvertWithOptions(args, ConvertOptions{
+		OutputPath:        outputPath,
+		DocsPath:          docsPath,
+		IndexFilePath:     indexFilePath,
+		FilePrefix:        filePrefixStr,
+		CommonPrefix:      commonPrefixStr,
+		WriteIntroduction: writeIntro,
+		MergeResponses:    mergeResponses,
+	})
+}
+
+// RunConvertWithOptions converts the specs matched by args with every setting of the convert command
+func RunConvertWithOptions(args []string, opts ConvertOptions) error {
+	if opts.OutputPath != "" {
+		if err := os.MkdirAll(opts.OutputPath, 0755); err != nil {
+			return fmt.Errorf("failed to create output directory: %w", err)
+		}
+	}
+	
+	for _, dir := range []string{opts.TraefikPath, opts.EnvoyPath, opts.KongPath, opts.CaddyPath, opts.HAProxyPath, opts.NjsPath, opts.TypeScriptPath, opts.PostmanPath, opts.HTTPFilePath} {
+		if dir == "" {
+			continue
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
 			return fmt.Errorf("failed to create output directory: %w", err)
 		}
 	}
 	
+	run := &convertRun{
+		opts:        opts,
+		httpContext: converter.NewHTTPContext(),
+	}
+	
+	switch opts.Aggregate {
+	case "":
+	case AggregateServer, AggregateInclude:
+		if opts.OutputPath == "" {
+			return fmt.Errorf("--aggregate requires an output directory (-o)")
+		}
+		run.aggregate = converter.NewGatewayAggregate(run.httpContext)
+	default:
+		return fmt.Errorf("unknown aggregate mode '%s', expected %s or %s", opts.Aggregate, AggregateServer, AggregateInclude)
+	}
+	
 	for _, path := range args {
-		if err := processPath(path, outputPath, docsPath, indexFilePath, filePrefixStr, commonPrefixStr, writeIntro, mergeResponses); err != nil {
+		if err := run.processPath(path); err != nil {
 			return err
 		}
 	}
 	
+	if run.aggregate != nil {
+		return run.writeAggregate()
+	}
+	
+	return run.writeHTTPContext()
+}
+
+func (r *convertRun) writeHTTPContext() error {
+	if r.opts.OutputPath == "" || r.httpContext.IsEmpty() {
+		return nil
+	}
+	
+	config, err := r.httpContext.Render()
+	if err != nil {
+		return fmt.Errorf("failed to generate Nginx http context: %w", err)
+	}
+	
+	return writeNginxFile(filepath.Join(r.opts.OutputPath, r.opts.FilePrefix+"http.conf.template"), config, "http context")
+}
+
+func (r *convertRun) writeAggregate() error {
+	if conflicts := r.aggregate.Conflicts(); len(conflicts) > 0 {
+		return fmt.Errorf("route conflicts between specifications:\n%s", strings.Join(conflicts, "\n"))
+	}
+	
+	var config string
+	var err error
+	if r.opts.Aggregate == AggregateInclude {
+		config, err = r.aggregate.WriteIncludeIndex(r.opts.IncludePath)
+		if err == nil {
+			err = r.writeHTTPContext()
+		}
+	} else {
+		config, err = r.aggregate.WriteServer()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to generate Nginx gateway: %w", err)
+	}
+	
+	return writeNginxFile(filepath.Join(r.opts.OutputPath, r.opts.FilePrefix+"gateway.conf.template"), config, "gateway")
+}
+
+func writeNginxFile(outputFile string, config string, kind string) error {
+	if err := converter.CheckNginxConfig(outputFile, config); err != nil {
+		return err
+	}
+	
+	if err := os.WriteFile(outputFile, []byte(config), 0644); err != nil {
+		return fmt.Errorf("failed to write Nginx %s: %w", kind, err)
+	}
+	
+	fmt.Printf("✓ Generated Nginx %s: %s\n", kind, outputFile)
 	return nil
 }
 
 func runConvertCommand(cmd *cobra.Command, args []string) error {
-	return RunConvert(args, outputDir, docsDir, indexPath, filePrefix, commonPrefix, writeIntroduction, mergeResponsesInline)
+	return RunConvertWithOptions(args, convertOptions)
 }
 
-func processPath(pattern string, outputPath, docsPath, indexFilePath, filePrefixStr, commonPrefixStr string, writeIntro, mergeResponses bool) error {
+func (r *convertRun) processPath(pattern string) error {
 	matches, err := filepath.Glob(pattern)
 	if err != nil {
 		return err
@@ -96,7 +243,7 @@ func processPath(pattern string, outputPath, docsPath, indexFilePath, filePrefix
 					return err
 				}
 				if !info.IsDir() && (strings.HasSuffix(info.Name(), ".yml") || strings.HasSuffix(info.Name(), ".yaml")) {
-					return processFile(path, outputPath, docsPath, indexFilePath, filePrefixStr, commonPrefixStr, writeIntro, mergeResponses)
+					return r.processFile(path)
 				}
 				return nil
 			})
@@ -104,7 +251,7 @@ func processPath(pattern string, outputPath, docsPath, indexFilePath, filePrefix
 				return err
 			}
 		} else if strings.HasSuffix(path, ".yml") || strings.HasSuffix(path, ".yaml") {
-			err = processFile(path, outputPath, docsPath, indexFilePath, filePrefixStr, commonPrefixStr, writeIntro, mergeResponses)
+			err = r.processFile(path)
 			if err != nil {
 				return err
 			}
@@ -114,21 +261,31 @@ func processPath(pattern string, outputPath, docsPath, indexFilePath, filePrefix
 	return nil
 }
 
-func processFile(filePath string, outputPath, docsPath, indexFilePath, filePrefixStr, commonPrefixStr string, writeIntro, mergeResponses bool) error {
+func (r *convertRun) processFile(filePath string) error {
+	opts := r.opts
 	conv, err := converter.NewOpenApiConverter(filePath)
 	if err != nil {
 		return fmt.Errorf("failed to load OpenAPI specification: %w", err)
 	}
 	
-	conv.FilePrefix = filePrefixStr
-	conv.WriteIntroduction = writeIntro
-	conv.CommonPrefix = commonPrefixStr
+	conv.FilePrefix = opts.FilePrefix
+	conv.WriteIntroduction = opts.WriteIntroduction
+	conv.CommonPrefix = opts.CommonPrefix
+	conv.ServerVariablePlaceholders = opts.ServerVarPlaceholders
+	conv.HTTPContext = r.httpContext
+	if opts.NjsPath != "" {
+		conv.NjsImportPath = opts.NjsImportPath
+	}
+	
+	if err = conv.SetServerVariables(opts.ServerVariables); err != nil {
+		return fmt.Errorf("server variable error: %s", err)
+	}
 	
 	if err = conv.ValidateDocument(); err != nil {
 		return fmt.Errorf("validation error: %s", err)
 	}
 	
-	if mergeResponses {
+	if opts.MergeResponses {
 		err = conv.MergeResponsesInline()
 		if err != nil {
 			return fmt.Errorf("merge error: %s", err)
@@ -136,34 +293,107 @@ func processFile(filePath string, outputPath, docsPath, indexFilePath, filePrefi
 		fmt.Printf("✓ Merged response definitions for %s\n", filePath)
 	}
 	
-	if outputPath != "" {
+	// Filled examples are only meant for spec.json, so they go into a copy used for the docs
+	docs := conv
+	if opts.FillExamples && len(opts.DocsPath) > 0 {
+		docs, err = conv.Clone()
+		if err != nil {
+			return err
+		}
+		docs.FillExamples(opts.ExampleSeed)
+		fmt.Printf("✓ Filled missing examples for %s\n", filePath)
+	}
+	
+	if r.aggregate != nil {
+		if err := r.aggregate.Add(conv); err != nil {
+			return fmt.Errorf("failed to generate Nginx config: %w", err)
+		}
+	}
+	
+	baseName := filepath.Base(filePath[:len(filePath)-len(filepath.Ext(filePath))])
+	
+	if opts.OutputPath != "" && opts.Aggregate != AggregateServer {
 		config, err := conv.WriteNginxConfiguration()
 		if err != nil {
 			return fmt.Errorf("failed to generate Nginx config: %w", err)
 		}
 		
-		outputFile := filepath.Join(outputPath, filepath.Base(filePath[:len(filePath)-len(filepath.Ext(filePath))])+".conf.template")
-		if err := os.WriteFile(outputFile, []byte(config), 0644); err != nil {
-			return fmt.Errorf("failed to write Nginx config: %w", err)
+		if err := writeNginxFile(filepath.Join(opts.OutputPath, baseName+".conf.template"), config, "config"); err != nil {
+			return err
+		}
+		
+		upstream, err := conv.WriteNginxUpstream()
+		if err != nil {
+			return fmt.Errorf("failed to generate Nginx upstream: %w", err)
 		}
 		
-		fmt.Printf("✓ Generated Nginx config: %s\n", outputFile)
+		if err := writeNginxFile(filepath.Join(opts.OutputPath, baseName+".upstream.conf.template"), upstream, "upstream"); err != nil {
+			return err
+		}
+	}
+	
+	outputs := []struct {
+		dir    string
+		suffix string
+		kind   string
+		write  func() (string, error)
+	}{
+		{opts.TraefikPath, ".traefik.yml", "Traefik config", conv.WriteTraefikConfiguration},
+		{opts.EnvoyPath, ".envoy.yml", "Envoy config", conv.WriteEnvoyConfiguration},
+		{opts.KongPath, ".kong.yml", "Kong config", conv.WriteKongConfiguration},
+		{opts.CaddyPath, ".caddy", "Caddy config", conv.WriteCaddyConfiguration},
+		{opts.HAProxyPath, ".haproxy.cfg", "HAProxy config", conv.WriteHAProxyConfiguration},
+		{opts.NjsPath, ".validate.js", "njs validation config", conv.WriteNjsValidation},
+		{opts.TypeScriptPath, ".d.ts", "TypeScript declarations", conv.WriteTypeScript},
+		{opts.PostmanPath, ".postman_collection.json", "Postman collection", conv.WritePostmanCollection},
+	}
+	
+	for _, output := range outputs {
+		if output.dir == "" {
+			continue
+		}
+		
+		content, err := output.write()
+		if err != nil {
+			return fmt.Errorf("failed to generate %s: %w", output.kind, err)
+		}
+		
+		outputFile := filepath.Join(output.dir, baseName+output.suffix)
+		if err := os.WriteFile(outputFile, []byte(content), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", output.kind, err)
+		}
+		fmt.Printf("✓ Generated %s: %s\n", output.kind, outputFile)
+	}
+	
+	if opts.HTTPFilePath != "" {
+		files, err := conv.WriteHTTPFiles()
+		if err != nil {
+			return fmt.Errorf("failed to generate .http files: %w", err)
+		}
+		
+		for _, suffix := range sortedFileNames(files) {
+			outputFile := filepath.Join(opts.HTTPFilePath, baseName+suffix)
+			if err := os.WriteFile(outputFile, []byte(files[suffix]), 0644); err != nil {
+				return fmt.Errorf("failed to write .http file: %w", err)
+			}
+			fmt.Printf("✓ Generated .http file: %s\n", outputFile)
+		}
 	}
 	
-	if len(docsPath) > 0 {
-		err = conv.WriteVitePressDocs(docsPath)
+	if len(opts.DocsPath) > 0 {
+		err = docs.WriteVitePressDocs(opts.DocsPath)
 		if err != nil {
 			return fmt.Errorf("failed to write VitePress docs: %w", err)
 		}
-		fmt.Printf("✓ Generated VitePress docs in %s\n", docsPath)
+		fmt.Printf("✓ Generated VitePress docs in %s\n", opts.DocsPath)
 	}
 	
-	if indexFilePath != "" {
-		err = conv.WriteVitePressFeatures(indexFilePath)
+	if opts.IndexFilePath != "" {
+		err = conv.WriteVitePressFeatures(opts.IndexFilePath)
 		if err != nil {
 			return fmt.Errorf("failed to write index: %w", err)
 		}
-		fmt.Printf("✓ Updated index features in %s\n", indexFilePath)
+		fmt.Printf("✓ Updated index features in %s\n", opts.IndexFilePath)
 	}
 	
 	return nil
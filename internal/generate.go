@@ -0,0 +1,145 @@
+package internal
+
+import (
+	"fmt"
+	"github.com/nimling/openapi-converter/converter"
+	"github.com/spf13/cobra"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// GenerateOptions holds the settings code is generated with
+type GenerateOptions struct {
+	OutputPath string
+	Package    string
+}
+
+var generateOptions GenerateOptions
+
+func NewGenerateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "generate",
+		Short: "Generate code from a specification",
+		Long: `Generate code that stays in sync with a specification.
+
+Available generators:
+- go-client: a typed Go client with a method per operation
+- go-server: a Go server interface wired onto a net/http ServeMux`,
+	}
+
+	cmd.PersistentFlags().StringVarP(&generateOptions.OutputPath, "output", "o", "", "Write the code to a file instead of stdout")
+	cmd.PersistentFlags().StringVar(&generateOptions.Package, "package", "", "Name of the generated Go package (default derived from the file name)")
+
+	cmd.AddCommand(newGoClientCommand())
+	cmd.AddCommand(newGoServerCommand())
+
+	return cmd
+}
+
+func newGoClientCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "go-client [file]",
+		Short: "Generate a typed Go client of a specification",
+		Long: `Generate a gofmt'd Go client of a specification.
+
+The client contains:
+- A type for every component schema; allOf schemas are merged into one struct, oneOf and anyOf
+  values are kept as json.RawMessage, optional and nullable properties are pointers
+- A method per operationId taking a context, the path parameters, the request body and a struct
+  holding the query, header and cookie parameters
+- The decoded body of the successful response as result, and an *APIError for other statuses
+
+The http.Client is pluggable with WithHTTPClient, and WithRequestEditor adds credentials or
+other headers to every request.
+
+Examples:
+  # Print the client
+  openapi-converter generate go-client api.yml
+
+  # Write the client into a package of your module
+  openapi-converter generate go-client api.yml --package catalog -o internal/catalog/client.go`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunGenerateGoClient(args[0], generateOptions, cmd.OutOrStdout())
+		},
+	}
+}
+
+func newGoServerCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "go-server [file]",
+		Short: "Generate a Go server interface of a specification",
+		Long: `Generate gofmt'd Go server code of a specification.
+
+The code contains:
+- The types of the component schemas, like go-client generates them
+- A Server interface with a method per operationId, receiving a request struct with the
+  parameters and the decoded body and returning one of the responses the operation declares
+- A response type per declared status, so implementations cannot answer undeclared statuses
+- RegisterHandlers, registering the operations on a net/http ServeMux with Go 1.22 method and
+  path patterns such as "GET /products/{productId}"
+
+Unreadable parameters and bodies are answered with 400 application/problem+json responses.
+Combine the handler with the middleware package to validate requests against the schemas.
+
+Examples:
+  # Print the server code
+  openapi-converter generate go-server api.yml
+
+  # Write the server code into a package of your module
+  openapi-converter generate go-server api.yml --package api -o internal/api/server.go`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunGenerateGoServer(args[0], generateOptions, cmd.OutOrStdout())
+		},
+	}
+}
+
+// RunGenerateGoClient generates the Go client of a spec and writes it to the output file or out
+func RunGenerateGoClient(filePath string, opts GenerateOptions, out io.Writer) error {
+	conv, err := converter.NewOpenApiConverter(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to load OpenAPI specification: %w", err)
+	}
+
+	source, err := conv.WriteGoClient(opts.Package)
+	if err != nil {
+		return fmt.Errorf("failed to generate Go client: %w", err)
+	}
+
+	return writeGenerated(opts.OutputPath, source, "Go client", out)
+}
+
+// RunGenerateGoServer generates the Go server code of a spec and writes it to the output file or out
+func RunGenerateGoServer(filePath string, opts GenerateOptions, out io.Writer) error {
+	conv, err := converter.NewOpenApiConverter(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to load OpenAPI specification: %w", err)
+	}
+
+	source, err := conv.WriteGoServer(opts.Package)
+	if err != nil {
+		return fmt.Errorf("failed to generate Go server: %w", err)
+	}
+
+	return writeGenerated(opts.OutputPath, source, "Go server", out)
+}
+
+// writeGenerated writes generated code to outputPath, creating its directory, or to out
+func writeGenerated(outputPath string, source string, kind string, out io.Writer) error {
+	if outputPath == "" {
+		_, err := io.WriteString(out, source)
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	if err := os.WriteFile(outputPath, []byte(source), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", kind, err)
+	}
+
+	fmt.Fprintf(out, "✓ Generated %s: %s\n", kind, outputPath)
+	return nil
+}
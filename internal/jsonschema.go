@@ -0,0 +1,84 @@
+package internal
+
+import (
+	"fmt"
+	"github.com/nimling/openapi-converter/converter"
+	"github.com/spf13/cobra"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// JSONSchemaOptions holds the settings component schemas are exported with
+type JSONSchemaOptions struct {
+	OutputPath string
+	BaseID     string
+	Defs       bool
+}
+
+var jsonSchemaOptions JSONSchemaOptions
+
+func NewJSONSchemaCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "jsonschema [file]",
+		Short: "Export the component schemas of a specification as JSON Schema files",
+		Long: `Export every components.schemas entry as a standalone draft 2020-12 JSON Schema file.
+
+Each schema is written to <name>.json with:
+- A $schema of draft 2020-12 and an $id of its file name, resolved against --base-id
+- nullable converted into a "null" type and example into examples
+- References to other component schemas rewritten to their files, such as "Category.json",
+  or with --defs to "#/$defs/Category" with the referenced schemas embedded in $defs
+
+Examples:
+  # Export the schemas next to each other
+  openapi-converter jsonschema api.yml -o schemas/
+
+  # Publish self-contained schemas under a URL
+  openapi-converter jsonschema api.yml -o schemas/ --defs --base-id https://schemas.example.com/catalog/`,
+		Args: cobra.ExactArgs(1),
+		RunE: runJSONSchemaCommand,
+	}
+
+	cmd.Flags().StringVarP(&jsonSchemaOptions.OutputPath, "output", "o", "", "Output directory for the JSON Schema files")
+	cmd.Flags().StringVar(&jsonSchemaOptions.BaseID, "base-id", "", "Base URI the $id of every schema is resolved against")
+	cmd.Flags().BoolVar(&jsonSchemaOptions.Defs, "defs", false, "Embed referenced schemas in $defs instead of referring to their files")
+	cmd.MarkFlagRequired("output")
+
+	return cmd
+}
+
+// RunJSONSchema exports the component schemas of a spec as JSON Schema files in the output directory
+func RunJSONSchema(filePath string, opts JSONSchemaOptions, out io.Writer) error {
+	if opts.OutputPath == "" {
+		return fmt.Errorf("an output directory is required")
+	}
+
+	conv, err := converter.NewOpenApiConverter(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to load OpenAPI specification: %w", err)
+	}
+
+	files, err := conv.WriteJSONSchemas(opts.BaseID, opts.Defs)
+	if err != nil {
+		return fmt.Errorf("failed to export JSON Schemas: %w", err)
+	}
+
+	if err := os.MkdirAll(opts.OutputPath, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	for _, name := range sortedFileNames(files) {
+		outputFile := filepath.Join(opts.OutputPath, name)
+		if err := os.WriteFile(outputFile, []byte(files[name]), 0644); err != nil {
+			return fmt.Errorf("failed to write JSON Schema: %w", err)
+		}
+		fmt.Fprintf(out, "✓ Generated JSON Schema: %s\n", outputFile)
+	}
+
+	return nil
+}
+
+func runJSONSchemaCommand(cmd *cobra.Command, args []string) error {
+	return RunJSONSchema(args[0], jsonSchemaOptions, cmd.OutOrStdout())
+}
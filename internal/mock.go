@@ -0,0 +1,99 @@
+package internal
+
+import (
+	"fmt"
+	"github.com/nimling/openapi-converter/converter"
+	"github.com/spf13/cobra"
+	"net"
+	"net/http"
+	"strconv"
+)
+
+// MockOptions holds the settings of the mock server
+type MockOptions struct {
+	Host     string
+	Port     int
+	Validate bool
+}
+
+var mockOptions MockOptions
+
+func NewMockCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "mock [file]",
+		Short: "Serve mock responses for every operation of a specification",
+		Long: `Serve every path and method of a specification with mock responses.
+
+The mock server:
+- Routes requests like the gateway outputs, including templated paths such as /users/{id}
+- Validates parameters and request bodies, answering invalid requests with a 400 problem
+- Answers with the example or the examples of the response, or a payload generated from its schema
+
+The response is chosen with the Prefer header:
+  Prefer: code=404          the response declared for a status code
+  Prefer: example=missing   a named example of the response
+  Prefer: dynamic=true      a payload generated from the schema, ignoring the examples
+
+Examples:
+  # Serve a spec on port 8080
+  openapi-converter mock api.yml --port 8080
+
+  # Ask for the not found response
+  curl -H 'Prefer: code=404' http://localhost:8080/users/42`,
+		Args: cobra.ExactArgs(1),
+		RunE: runMockCommand,
+	}
+
+	cmd.Flags().StringVar(&mockOptions.Host, "host", "", "Address the mock server listens on (all interfaces by default)")
+	cmd.Flags().IntVarP(&mockOptions.Port, "port", "p", 8080, "Port the mock server listens on")
+	cmd.Flags().BoolVar(&mockOptions.Validate, "validate", true, "Reject requests that do not match their operation with a 400 problem response")
+
+	return cmd
+}
+
+// NewMockHandler loads a spec and returns the handler serving its mock responses
+func NewMockHandler(filePath string, opts MockOptions) (http.Handler, error) {
+	conv, err := converter.NewOpenApiConverter(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load OpenAPI specification: %w", err)
+	}
+
+	server, err := conv.MockServer()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create mock server: %w", err)
+	}
+	server.Validate = opts.Validate
+
+	return server, nil
+}
+
+func runMockCommand(cmd *cobra.Command, args []string) error {
+	handler, err := NewMockHandler(args[0], mockOptions)
+	if err != nil {
+		return err
+	}
+
+	address := net.JoinHostPort(mockOptions.Host, strconv.Itoa(mockOptions.Port))
+	fmt.Printf("✓ Serving mock responses for %s on http://%s\n", args[0], address)
+
+	return http.ListenAndServe(address, logRequests(handler))
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// logRequests prints the method, path and response status of every request
+func logRequests(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		handler.ServeHTTP(recorder, r)
+		fmt.Printf("%s %s %d\n", r.Method, r.URL.RequestURI(), recorder.status)
+	})
+}
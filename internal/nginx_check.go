@@ -0,0 +1,63 @@
+package internal
+
+import (
+	"fmt"
+	"github.com/nimling/openapi-converter/converter"
+	"github.com/spf13/cobra"
+	"os"
+	"strings"
+)
+
+func NewNginxCheckCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "nginx-check [files...]",
+		Short: "Check generated Nginx configuration files offline",
+		Long: `Check generated Nginx configuration files without running Nginx.
+
+The check parses the directive subset produced by the convert command and reports:
+- Unbalanced braces and directives missing a terminating ';'
+- Duplicate locations within the same block
+- Prefix locations whose path a regex location of the same block captures
+- Unknown directives
+- Invalid regexes in locations, rewrites, if conditions and maps
+- proxy_pass with a URI in regex and named locations, if and limit_except blocks
+
+The same check runs automatically after every Nginx file written by convert.
+
+Examples:
+  # Check all generated templates
+  openapi-converter nginx-check ./nginx/*.conf.template`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: runNginxCheckCommand,
+	}
+	
+	return cmd
+}
+
+func RunNginxCheck(files []string) error {
+	var failures []string
+	
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		
+		if err := converter.CheckNginxConfig(file, string(content)); err != nil {
+			failures = append(failures, err.Error())
+			continue
+		}
+		
+		fmt.Printf("✓ %s\n", file)
+	}
+	
+	if len(failures) > 0 {
+		return fmt.Errorf("%s", strings.Join(failures, "\n"))
+	}
+	
+	return nil
+}
+
+func runNginxCheckCommand(cmd *cobra.Command, args []string) error {
+	return RunNginxCheck(args)
+}
@@ -0,0 +1,83 @@
+package internal
+
+import (
+	"fmt"
+	"github.com/nimling/openapi-converter/converter"
+	"github.com/spf13/cobra"
+	"io"
+	"os"
+)
+
+// RoutesOptions holds the settings the route table is computed with
+type RoutesOptions struct {
+	OutputPath      string
+	CommonPrefix    string
+	ServerVariables map[string]string
+}
+
+var routesOptions RoutesOptions
+
+func NewRoutesCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "routes [file]",
+		Short: "Print the gateway route table of a specification as JSON",
+		Long: `Print the route table every gateway output is rendered from.
+
+The route table lists, per path:
+- The methods and the path matcher shared by all gateway formats
+- Path and operation parameters
+- The upstream servers the path is proxied to
+- The effective security requirements of every operation
+- The x-nginx, x-cors and x-rate-limit extensions and the level they were defined at
+
+Examples:
+  # Inspect the routes of a spec
+  openapi-converter routes api.yml
+
+  # Write the table to a file, stripping the common prefix like convert does
+  openapi-converter routes api.yml --common-prefix /v1 -o routes.json`,
+		Args: cobra.ExactArgs(1),
+		RunE: runRoutesCommand,
+	}
+
+	cmd.Flags().StringVarP(&routesOptions.OutputPath, "output", "o", "", "Write the route table to a file instead of stdout")
+	cmd.Flags().StringVar(&routesOptions.CommonPrefix, "common-prefix", "", "URL path prefix stripped before requests are proxied")
+	cmd.Flags().StringToStringVar(&routesOptions.ServerVariables, "server-var", nil, "Override a server variable default, e.g. --server-var region=eu (repeatable)")
+
+	return cmd
+}
+
+// RunRoutes computes the route table of a spec and writes it to the output file or out
+func RunRoutes(filePath string, opts RoutesOptions, out io.Writer) error {
+	conv, err := converter.NewOpenApiConverter(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to load OpenAPI specification: %w", err)
+	}
+
+	conv.CommonPrefix = opts.CommonPrefix
+
+	if err = conv.SetServerVariables(opts.ServerVariables); err != nil {
+		return fmt.Errorf("server variable error: %s", err)
+	}
+
+	table, err := conv.WriteRouteTable()
+	if err != nil {
+		return fmt.Errorf("failed to compute route table: %w", err)
+	}
+
+	if opts.OutputPath == "" {
+		_, err = io.WriteString(out, table)
+		return err
+	}
+
+	if err := os.WriteFile(opts.OutputPath, []byte(table), 0644); err != nil {
+		return fmt.Errorf("failed to write route table: %w", err)
+	}
+
+	fmt.Printf("✓ Generated route table: %s\n", opts.OutputPath)
+	return nil
+}
+
+func runRoutesCommand(cmd *cobra.Command, args []string) error {
+	return RunRoutes(args[0], routesOptions, cmd.OutOrStdout())
+}
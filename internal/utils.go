@@ -1,7 +1,13 @@
 package internal
 
-import "fmt"
+import (
+	"fmt"
+	"os"
+	"sort"
+)
 
+// PrintBanner writes the banner to stderr, so commands writing generated code or JSON to stdout
+// can be redirected to a file
 func PrintBanner() {
 	banner := `
  ██████╗  █████╗  ██████╗
@@ -12,5 +18,16 @@ func PrintBanner() {
  ╚═════╝ ╚═╝  ╚═╝ ╚═════╝
 OpenAPI Converter v1.0.0
 `
-	fmt.Println(banner)
-}
\ No newline at end of file
+	fmt.Fprintln(os.Stderr, banner)
+}
+
+// sortedFileNames lists the names of generated files in order
+func sortedFileNames(files map[string]string) []string {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
@@ -0,0 +1,38 @@
+package kong
+
+// FormatVersion is the decK declarative format the generated files use
+const FormatVersion = "3.0"
+
+// Config is the root of a decK declarative configuration
+type Config struct {
+	FormatVersion string     `yaml:"_format_version" json:"_format_version"`
+	Services      []*Service `yaml:"services" json:"services"`
+}
+
+// Service is an upstream API with the routes exposing it
+type Service struct {
+	Name           string    `yaml:"name" json:"name"`
+	URL            string    `yaml:"url" json:"url"`
+	ConnectTimeout int       `yaml:"connect_timeout,omitempty" json:"connect_timeout,omitempty"`
+	WriteTimeout   int       `yaml:"write_timeout,omitempty" json:"write_timeout,omitempty"`
+	ReadTimeout    int       `yaml:"read_timeout,omitempty" json:"read_timeout,omitempty"`
+	Routes         []*Route  `yaml:"routes" json:"routes"`
+	Plugins        []*Plugin `yaml:"plugins,omitempty" json:"plugins,omitempty"`
+}
+
+// Route matches requests by path and method. Paths starting with ~ are regexes, tried in order of
+// RegexPriority before the plain paths
+type Route struct {
+	Name          string    `yaml:"name" json:"name"`
+	Paths         []string  `yaml:"paths" json:"paths"`
+	Methods       []string  `yaml:"methods,omitempty" json:"methods,omitempty"`
+	RegexPriority int       `yaml:"regex_priority,omitempty" json:"regex_priority,omitempty"`
+	StripPath     bool      `yaml:"strip_path" json:"strip_path"`
+	Plugins       []*Plugin `yaml:"plugins,omitempty" json:"plugins,omitempty"`
+}
+
+// Plugin enables a Kong plugin with its configuration
+type Plugin struct {
+	Name   string                 `yaml:"name" json:"name"`
+	Config map[string]interface{} `yaml:"config,omitempty" json:"config,omitempty"`
+}
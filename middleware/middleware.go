@@ -0,0 +1,165 @@
+// Package middleware guards Go HTTP handlers with the spec they implement. Requests are routed
+// by path template and method like the gateway outputs route them, parameters and JSON bodies are
+// validated against their schemas and violations are answered with RFC 7807 problem responses
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"github.com/nimling/openapi-converter/converter"
+	"net/http"
+	"strings"
+)
+
+// Options tunes the validation of a Validator
+type Options struct {
+	// BasePath is removed from request paths before they are matched against the spec paths,
+	// typically the path of the server URL such as /v1
+	BasePath string
+	// ValidateResponses checks the responses of the next handler against the spec. Responses are
+	// buffered, and a response that violates the spec is replaced by a 500 problem response
+	ValidateResponses bool
+	// AllowUnknownPaths passes requests whose path matches no spec path to the next handler
+	// instead of answering 404
+	AllowUnknownPaths bool
+	// ProblemHandler writes the problem responses. By default the problem is written as
+	// application/problem+json
+	ProblemHandler func(w http.ResponseWriter, r *http.Request, problem *converter.Problem)
+}
+
+// Validator validates requests and responses against the resolved document of a spec
+type Validator struct {
+	table   *converter.RouteTable
+	options Options
+}
+
+type contextKey struct{}
+
+// New creates a validator from the resolved document of a converter
+func New(conv *converter.OpenAPIConverter, options Options) (*Validator, error) {
+	table, err := conv.RouteTable()
+	if err != nil {
+		return nil, err
+	}
+
+	options.BasePath = strings.TrimSuffix(options.BasePath, "/")
+	if options.ProblemHandler == nil {
+		options.ProblemHandler = func(w http.ResponseWriter, r *http.Request, problem *converter.Problem) {
+			problem.Write(w)
+		}
+	}
+
+	return &Validator{table: table, options: options}, nil
+}
+
+// Middleware wraps the next handler with the validation. Its signature fits routers accepting
+// func(http.Handler) http.Handler middlewares
+func (v *Validator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := r.URL.Path
+		if v.options.BasePath != "" {
+			if path != v.options.BasePath && !strings.HasPrefix(path, v.options.BasePath+"/") {
+				v.unknownPath(next, w, r)
+				return
+			}
+			path = strings.TrimPrefix(path, v.options.BasePath)
+		}
+
+		match := v.table.MatchRequest(r.Method, path)
+		if match == nil {
+			v.unknownPath(next, w, r)
+			return
+		}
+
+		if match.Operation == nil {
+			// Preflight requests are left to a CORS handler
+			if r.Method == http.MethodOptions {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("Allow", strings.Join(match.Route.Methods, ", "))
+			v.options.ProblemHandler(w, r, converter.NewProblem(http.StatusMethodNotAllowed, "path "+match.Route.Path+" has no "+r.Method+" operation"))
+			return
+		}
+
+		if problem := v.table.ValidateRequest(r, match); problem != nil {
+			v.options.ProblemHandler(w, r, problem)
+			return
+		}
+
+		r = r.WithContext(context.WithValue(r.Context(), contextKey{}, match))
+		if !v.options.ValidateResponses {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		recorder := &responseRecorder{header: http.Header{}, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+
+		// net/http sniffs the content type of responses without one, so the check has to as well
+		if recorder.header.Get("Content-Type") == "" && recorder.body.Len() > 0 {
+			recorder.header.Set("Content-Type", http.DetectContentType(recorder.body.Bytes()))
+		}
+
+		if errors := v.table.ValidateResponse(match.Operation, recorder.status, recorder.header, recorder.body.Bytes()); len(errors) > 0 {
+			v.options.ProblemHandler(w, r, converter.NewProblem(http.StatusInternalServerError, errors...))
+			return
+		}
+
+		for name, values := range recorder.header {
+			w.Header()[name] = values
+		}
+		w.WriteHeader(recorder.status)
+		_, _ = w.Write(recorder.body.Bytes())
+	})
+}
+
+func (v *Validator) unknownPath(next http.Handler, w http.ResponseWriter, r *http.Request) {
+	if v.options.AllowUnknownPaths {
+		next.ServeHTTP(w, r)
+		return
+	}
+
+	v.options.ProblemHandler(w, r, converter.NewProblem(http.StatusNotFound, "no path of the spec matches "+r.URL.Path))
+}
+
+// Match returns the route and operation the validator matched a request to, with the values of
+// its path parameters. It returns nil for requests that did not pass through the validator
+func Match(r *http.Request) *converter.RequestMatch {
+	match, _ := r.Context().Value(contextKey{}).(*converter.RequestMatch)
+	return match
+}
+
+// PathParam returns the value of a path parameter of the matched path template
+func PathParam(r *http.Request, name string) string {
+	if match := Match(r); match != nil {
+		return match.PathParams[name]
+	}
+
+	return ""
+}
+
+// responseRecorder buffers a response until it has been validated
+type responseRecorder struct {
+	header      http.Header
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (r *responseRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	r.wroteHeader = true
+	return r.body.Write(data)
+}
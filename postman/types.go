@@ -0,0 +1,109 @@
+package postman
+
+// SchemaURL identifies the Postman Collection v2.1 format
+const SchemaURL = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+
+// Collection is the root of a Postman Collection v2.1
+type Collection struct {
+	Info     *Info       `json:"info"`
+	Item     []*Item     `json:"item"`
+	Auth     *Auth       `json:"auth,omitempty"`
+	Variable []*Variable `json:"variable,omitempty"`
+}
+
+// Info names and describes the collection
+type Info struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version,omitempty"`
+	Schema      string `json:"schema"`
+}
+
+// Item is either a folder holding other items or a request with its saved responses
+type Item struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description,omitempty"`
+	Item        []*Item     `json:"item,omitempty"`
+	Request     *Request    `json:"request,omitempty"`
+	Response    []*Response `json:"response,omitempty"`
+}
+
+// Request describes an HTTP request. Values may refer to variables as {{name}}
+type Request struct {
+	Method      string      `json:"method"`
+	Description string      `json:"description,omitempty"`
+	Header      []*KeyValue `json:"header"`
+	URL         *URL        `json:"url"`
+	Body        *Body       `json:"body,omitempty"`
+	Auth        *Auth       `json:"auth,omitempty"`
+}
+
+// URL is a request URL split into its parts. Path variables are path segments starting with :
+type URL struct {
+	Raw      string      `json:"raw"`
+	Host     []string    `json:"host"`
+	Path     []string    `json:"path,omitempty"`
+	Query    []*KeyValue `json:"query,omitempty"`
+	Variable []*KeyValue `json:"variable,omitempty"`
+}
+
+// KeyValue is a header, query parameter or path variable. Disabled entries are listed but not sent
+type KeyValue struct {
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	Description string `json:"description,omitempty"`
+	Disabled    bool   `json:"disabled,omitempty"`
+}
+
+// Body is a request body. Raw bodies are sent as they are
+type Body struct {
+	Mode       string       `json:"mode"`
+	Raw        string       `json:"raw,omitempty"`
+	URLEncoded []*KeyValue  `json:"urlencoded,omitempty"`
+	Options    *BodyOptions `json:"options,omitempty"`
+}
+
+// BodyOptions tells Postman how to highlight a raw body
+type BodyOptions struct {
+	Raw *RawOptions `json:"raw,omitempty"`
+}
+
+// RawOptions holds the language of a raw body, such as json
+type RawOptions struct {
+	Language string `json:"language"`
+}
+
+// Response is a saved example response of a request
+type Response struct {
+	Name            string      `json:"name"`
+	OriginalRequest *Request    `json:"originalRequest,omitempty"`
+	Status          string      `json:"status,omitempty"`
+	Code            int         `json:"code,omitempty"`
+	Header          []*KeyValue `json:"header,omitempty"`
+	Body            string      `json:"body,omitempty"`
+}
+
+// Auth is the authentication of a request. The attributes are listed under the key of Type, such
+// as bearer, basic or apikey; noauth sends no credentials
+type Auth struct {
+	Type   string      `json:"type"`
+	Bearer []*AuthAttr `json:"bearer,omitempty"`
+	Basic  []*AuthAttr `json:"basic,omitempty"`
+	APIKey []*AuthAttr `json:"apikey,omitempty"`
+	OAuth2 []*AuthAttr `json:"oauth2,omitempty"`
+}
+
+// AuthAttr is an attribute of an authentication method
+type AuthAttr struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+	Type  string `json:"type"`
+}
+
+// Variable is a collection variable, referenced as {{key}}
+type Variable struct {
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	Type        string `json:"type,omitempty"`
+	Description string `json:"description,omitempty"`
+}
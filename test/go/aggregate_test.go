@@ -0,0 +1,88 @@
+package test
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"github.com/nimling/openapi-converter/internal"
+)
+
+func TestAggregateGateway(t *testing.T) {
+	tests := []struct {
+		name      string
+		specs     []string
+		aggregate string
+		want      []string
+		wantErr   string
+	}{
+		{
+			name:      "Single server block",
+			specs:     []string{"../examples/spec.yml", "../examples/gateway.yml"},
+			aggregate: internal.AggregateServer,
+			want: []string{
+				"upstream spec_upstream {",
+				"upstream orders_backend {",
+				"limit_req_zone $binary_remote_addr zone=gateway:10m rate=20r/s;",
+				"server {\n    listen 80;",
+				"    location ~ ^/users/[^/]+$ {",
+				"    location = /orders {",
+			},
+		},
+		{
+			name:      "Include index",
+			specs:     []string{"../examples/spec.yml", "../examples/gateway.yml"},
+			aggregate: internal.AggregateInclude,
+			want: []string{
+				"include conf.d/locations/spec.conf;",
+				"include conf.d/locations/gateway.conf;",
+			},
+		},
+		{
+			name:      "Conflicting routes",
+			specs:     []string{"../examples/spec.yml", "../examples/accounts.yml"},
+			aggregate: internal.AggregateServer,
+			wantErr:   "both claim location '= /users'",
+		},
+		{
+			name:      "Shadowed route",
+			specs:     []string{"../examples/spec.yml", "../examples/accounts.yml"},
+			aggregate: internal.AggregateServer,
+			wantErr:   "path '/users/{id}' shadows '../examples/accounts.yml' path '/users/me'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputPath := "../../tmp/test-aggregate"
+			defer os.RemoveAll(outputPath)
+			
+			err := internal.RunConvertWithOptions(tt.specs, internal.ConvertOptions{
+				OutputPath:  outputPath,
+				Aggregate:   tt.aggregate,
+				IncludePath: "conf.d/locations",
+			})
+			
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			
+			if err != nil {
+				t.Fatalf("RunConvert failed: %v", err)
+			}
+			
+			content, err := os.ReadFile(outputPath + "/gateway.conf.template")
+			if err != nil {
+				t.Fatalf("gateway file was not written: %v", err)
+			}
+			
+			for _, want := range tt.want {
+				if !strings.Contains(string(content), want) {
+					t.Errorf("gateway is missing %q:\n%s", want, content)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package test
+
+import (
+	"strings"
+	"testing"
+	"github.com/nimling/openapi-converter/converter"
+)
+
+func TestCaddyConfiguration(t *testing.T) {
+	conv, err := converter.NewOpenApiConverter("../examples/spec.yml")
+	if err != nil {
+		t.Fatalf("failed to load spec: %v", err)
+	}
+	conv.CommonPrefix = "/users"
+
+	config, err := conv.WriteCaddyConfiguration()
+	if err != nil {
+		t.Fatalf("WriteCaddyConfiguration failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"handle_path /users/* {",
+		"\t@spec_users_id {\n\t\tpath_regexp \"^/[^/]+$\"\n\t\tmethod GET\n\t}",
+		"@spec_users {\n\tpath /users\n\tmethod GET POST\n}",
+		"handle @spec_users {",
+		"rewrite * /v1{uri}",
+		"reverse_proxy https://api.example.com:443 {",
+		"tls_server_name api.example.com",
+		"header_down -Server",
+	} {
+		if !strings.Contains(config, want) {
+			t.Errorf("Caddyfile is missing %q:\n%s", want, config)
+		}
+	}
+
+	if strings.Index(config, "handle_path") > strings.Index(config, "handle @spec_users {") {
+		t.Errorf("routes below the prefix should come before the prefix route:\n%s", config)
+	}
+	if strings.Count(config, "{") != strings.Count(config, "}") {
+		t.Errorf("unbalanced braces:\n%s", config)
+	}
+}
+
+func TestHAProxyConfiguration(t *testing.T) {
+	conv := loadGatewayConverter(t)
+	conv.CommonPrefix = "/gateway"
+
+	config, err := conv.WriteHAProxyConfiguration()
+	if err != nil {
+		t.Fatalf("WriteHAProxyConfiguration failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"frontend gateway",
+		"acl gateway_orders_path path_reg ^/orders$",
+		"acl gateway_orders_method method GET POST",
+		"use_backend gateway_orders if gateway_orders_path gateway_orders_method",
+		"backend gateway_reports",
+		"balance leastconn",
+		"timeout server 300000ms",
+		`http-request replace-path ^/gateway/(.*) /api/\\1`,
+		`http-response set-header X-Report-Engine "v2"`,
+		"server orders_backend_1 orders-1.example.com:443 weight 3 ssl verify none sni str(orders-1.example.com)",
+		"server orders_backend_2 orders-2.example.com:443 backup",
+	} {
+		if !strings.Contains(config, want) {
+			t.Errorf("HAProxy config is missing %q:\n%s", want, config)
+		}
+	}
+
+	if strings.Contains(config, "path_reg ^/orders\n") {
+		t.Errorf("literal paths should match exactly, not as a prefix:\n%s", config)
+	}
+}
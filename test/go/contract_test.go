@@ -0,0 +1,148 @@
+package test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+	"github.com/nimling/openapi-converter/converter"
+	"github.com/nimling/openapi-converter/internal"
+)
+
+func TestContractTestsAgainstMock(t *testing.T) {
+	defer os.RemoveAll("../../tmp/contract")
+
+	// The mock server validates the requests, so a passing run also shows the runner builds
+	// requests that match the spec
+	handler, err := internal.NewMockHandler("../examples/catalog.yml", internal.MockOptions{Validate: true})
+	if err != nil {
+		t.Fatalf("NewMockHandler failed: %v", err)
+	}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	var out bytes.Buffer
+	err = internal.RunContractTests("../examples/catalog.yml", internal.ContractOptions{
+		BaseURL:   server.URL,
+		JUnitPath: "../../tmp/contract/report.xml",
+		Headers:   []string{"Authorization: Bearer token"},
+	}, &out)
+	if err != nil {
+		t.Fatalf("contract tests failed: %v\n%s", err, out.String())
+	}
+
+	for _, want := range []string{
+		"✓ POST /orders (createOrder): 201",
+		"✓ GET /products/{productId} (getProduct): 200",
+		"✓ DELETE /products/{productId} (deleteProduct): 204",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output is missing %q:\n%s", want, out.String())
+		}
+	}
+
+	data, err := os.ReadFile("../../tmp/contract/report.xml")
+	if err != nil {
+		t.Fatalf("JUnit report was not written: %v", err)
+	}
+
+	report := struct {
+		Tests    int `xml:"tests,attr"`
+		Failures int `xml:"failures,attr"`
+		Suites   []struct {
+			Name  string `xml:"name,attr"`
+			Cases []struct {
+				Name string `xml:"name,attr"`
+			} `xml:"testcase"`
+		} `xml:"testsuite"`
+	}{}
+	if err := xml.Unmarshal(data, &report); err != nil {
+		t.Fatalf("report is not valid XML: %v\n%s", err, data)
+	}
+	if report.Tests != 5 || report.Failures != 0 || report.Suites[0].Name != "catalog" || len(report.Suites[0].Cases) != 5 {
+		t.Errorf("unexpected report:\n%s", data)
+	}
+}
+
+func TestContractTestsReportViolations(t *testing.T) {
+	var requests []*http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/products":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`[{"id":"1","name":"Go","price":2000}]`))
+		case r.Method == "GET":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte("<h1>product</h1>"))
+		case r.Method == "DELETE":
+			w.WriteHeader(http.StatusTeapot)
+		default:
+			w.WriteHeader(http.StatusCreated)
+		}
+	}))
+	defer server.Close()
+
+	conv, err := converter.NewOpenApiConverter("../examples/catalog.yml")
+	if err != nil {
+		t.Fatalf("failed to load spec: %v", err)
+	}
+	runner, err := conv.ContractRunner(server.URL)
+	if err != nil {
+		t.Fatalf("ContractRunner failed: %v", err)
+	}
+	runner.Operations = []string{"listProducts", "getProduct", "deleteProduct", "createProduct"}
+
+	results := runner.Run()
+	if len(results) != 4 {
+		t.Fatalf("got %d results, want 4", len(results))
+	}
+
+	failures := map[string]string{}
+	for _, result := range results {
+		failures[result.OperationID] = strings.Join(result.Failures, "\n")
+	}
+
+	for operation, want := range map[string][]string{
+		"listProducts":  {"response[0].createdAt is required", "response[0].price must be at most 999"},
+		"getProduct":    {"response content type text/html is not one of application/json"},
+		"deleteProduct": {"status 418 is not declared, expected one of 204"},
+		"createProduct": {"response body is empty, expected application/json"},
+	} {
+		for _, message := range want {
+			if !strings.Contains(failures[operation], message) {
+				t.Errorf("%s failures %q are missing %q", operation, failures[operation], message)
+			}
+		}
+	}
+
+	// The path parameter is generated from its uuid schema and the body from NewProduct
+	for _, r := range requests {
+		if r.Method == "GET" && strings.HasPrefix(r.URL.Path, "/products/") && len(strings.TrimPrefix(r.URL.Path, "/products/")) != 36 {
+			t.Errorf("productId should be a generated uuid, got %s", r.URL.Path)
+		}
+		if r.Method == "POST" && r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("createProduct sent Content-Type %q", r.Header.Get("Content-Type"))
+		}
+	}
+
+	report, err := converter.WriteJUnitReport("catalog", results, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("WriteJUnitReport failed: %v", err)
+	}
+	for _, want := range []string{
+		`<testsuites name="catalog" tests="4" failures="4" errors="0"`,
+		`timestamp="2024-01-01T00:00:00Z"`,
+		`<testcase name="DELETE /products/{productId} (deleteProduct)" classname="catalog"`,
+		`<failure message="status 418 is not declared, expected one of 204" type="contract">`,
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report is missing %q:\n%s", want, report)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package test
+
+import (
+	"testing"
+	"github.com/nimling/openapi-converter/converter"
+	"github.com/nimling/openapi-converter/envoy"
+	"gopkg.in/yaml.v3"
+)
+
+func loadEnvoyConfig(t *testing.T, specPath string, commonPrefix string) *envoy.Config {
+	t.Helper()
+
+	conv, err := converter.NewOpenApiConverter(specPath)
+	if err != nil {
+		t.Fatalf("failed to load spec: %v", err)
+	}
+	conv.CommonPrefix = commonPrefix
+
+	output, err := conv.WriteEnvoyConfiguration()
+	if err != nil {
+		t.Fatalf("WriteEnvoyConfiguration failed: %v", err)
+	}
+
+	config := &envoy.Config{}
+	if err := yaml.Unmarshal([]byte(output), config); err != nil {
+		t.Fatalf("output is not valid YAML: %v\n%s", err, output)
+	}
+
+	return config
+}
+
+func TestEnvoyRoutes(t *testing.T) {
+	config := loadEnvoyConfig(t, "../examples/gateway.yml", "/gateway")
+
+	hosts := config.RouteConfig.VirtualHosts
+	if len(hosts) != 1 {
+		t.Fatalf("expected one virtual host, got %d", len(hosts))
+	}
+
+	routes := map[string]*envoy.Route{}
+	for _, route := range hosts[0].Routes {
+		routes[route.Name] = route
+	}
+	if len(routes) != 3 {
+		t.Fatalf("expected one route per operation, got %d", len(routes))
+	}
+
+	upload := routes["uploadOrders"]
+	if upload == nil {
+		t.Fatalf("route uploadOrders is missing")
+	}
+	if upload.Match.Path != "/orders" || upload.Match.Headers[0].StringMatch.Exact != "POST" {
+		t.Errorf("unexpected match %+v", upload.Match)
+	}
+	if rewrite := upload.Route.RegexRewrite; rewrite == nil || rewrite.Pattern.Regex != "^/gateway/(.*)" || rewrite.Substitution != `/api/\1` {
+		t.Errorf("unexpected rewrite %+v", upload.Route.RegexRewrite)
+	}
+	if routes["generateReport"].Route.Timeout != "300s" {
+		t.Errorf("path-level readTimeout not applied: %q", routes["generateReport"].Route.Timeout)
+	}
+
+	if len(config.Clusters) != 1 {
+		t.Fatalf("expected one cluster, got %d", len(config.Clusters))
+	}
+	cluster := config.Clusters[0]
+	if cluster.Name != "orders_backend" || cluster.LbPolicy != "LEAST_REQUEST" || cluster.ConnectTimeout != "5s" {
+		t.Errorf("unexpected cluster %+v", cluster)
+	}
+	if len(cluster.LoadAssignment.Endpoints) != 2 || cluster.LoadAssignment.Endpoints[1].Priority != 1 {
+		t.Errorf("backup server should be in a lower priority: %+v", cluster.LoadAssignment.Endpoints)
+	}
+	if cluster.TransportSocket == nil || cluster.TransportSocket.TypedConfig.SNI != "orders-1.example.com" {
+		t.Errorf("https upstream without TLS transport socket: %+v", cluster.TransportSocket)
+	}
+}
+
+func TestEnvoyLiteralPathsComeFirst(t *testing.T) {
+	config := loadEnvoyConfig(t, "../examples/profiles.yml", "")
+
+	routes := config.RouteConfig.VirtualHosts[0].Routes
+	if len(routes) != 2 {
+		t.Fatalf("expected one route per operation, got %d", len(routes))
+	}
+	if first := routes[0]; first.Match.Path != "/users/me" || first.Match.SafeRegex != nil {
+		t.Errorf("expected the exact literal path as first route, got %+v", first.Match)
+	}
+	if last := routes[1]; last.Match.SafeRegex == nil || last.Match.SafeRegex.Regex != "^/users/[^/]+$" || last.Match.Path != "" {
+		t.Errorf("expected the templated path as last route, got %+v", last.Match)
+	}
+}
@@ -0,0 +1,200 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"regexp"
+	"testing"
+	"time"
+	"github.com/nimling/openapi-converter/converter"
+	"github.com/nimling/openapi-converter/internal"
+)
+
+func generateCatalogExamples(t *testing.T, seed int64) map[string]interface{} {
+	t.Helper()
+
+	var out bytes.Buffer
+	if err := internal.RunExamples("../examples/catalog.yml", internal.ExamplesOptions{Seed: seed}, &out); err != nil {
+		t.Fatalf("RunExamples failed: %v", err)
+	}
+
+	examples := map[string]interface{}{}
+	if err := json.Unmarshal(out.Bytes(), &examples); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, out.String())
+	}
+
+	return examples
+}
+
+func TestExampleGenerator(t *testing.T) {
+	examples := generateCatalogExamples(t, 7)
+
+	product, ok := examples["Product"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Product example is not an object: %v", examples["Product"])
+	}
+
+	// allOf merges the properties of NewProduct with the id and timestamps
+	for _, name := range []string{"id", "createdAt", "name", "price"} {
+		if _, ok := product[name]; !ok {
+			t.Errorf("Product example is missing %s: %v", name, product)
+		}
+	}
+	if id, _ := product["id"].(string); !regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`).MatchString(id) {
+		t.Errorf("id %q is not a v4 UUID", id)
+	}
+	if _, err := time.Parse(time.RFC3339, product["createdAt"].(string)); err != nil {
+		t.Errorf("createdAt is not a date-time: %v", err)
+	}
+	if name := product["name"].(string); len(name) < 3 || len(name) > 40 {
+		t.Errorf("name %q violates the length bounds", name)
+	}
+	if price := product["price"].(float64); price < 0.5 || price > 999 {
+		t.Errorf("price %v violates the bounds", price)
+	}
+	if category := product["category"]; category != "books" && category != "games" && category != "music" {
+		t.Errorf("category %v is not one of the enum values", category)
+	}
+	if tags := product["tags"].([]interface{}); len(tags) < 1 || len(tags) > 3 {
+		t.Errorf("got %d tags, want 1 to 3", len(tags))
+	}
+
+	order := examples["Order"].(map[string]interface{})
+	if order["status"] != "pending" {
+		t.Errorf("status should keep its default, got %v", order["status"])
+	}
+	if contact, _ := order["contact"].(string); !regexp.MustCompile(`^[a-z]+\.[a-z]+@example\.com$`).MatchString(contact) {
+		t.Errorf("contact %q is not an email", contact)
+	}
+	payment := order["payment"].(map[string]interface{})
+	if method := payment["method"]; method != "card" && method != "invoice" {
+		t.Errorf("payment does not match a oneOf alternative: %v", payment)
+	}
+
+	if card := examples["CardPayment"].(map[string]interface{}); len(card["last4"].(string)) != 4 {
+		t.Errorf("last4 %q should have exactly 4 characters", card["last4"])
+	}
+
+	// NewProduct refers to itself through related, which is left out the second time
+	if related, ok := examples["NewProduct"].(map[string]interface{})["related"]; ok && len(related.([]interface{})) > 0 {
+		t.Errorf("self references should not be expanded: %v", related)
+	}
+
+	conv, err := converter.NewOpenApiConverter("../examples/catalog.yml")
+	if err != nil {
+		t.Fatalf("failed to load spec: %v", err)
+	}
+	table, err := conv.RouteTable()
+	if err != nil {
+		t.Fatalf("RouteTable failed: %v", err)
+	}
+	for name, example := range examples {
+		ref := "#/components/schemas/" + name
+		if errors := table.ValidateValue(&converter.Schema{Ref: &ref}, example, name); len(errors) > 0 {
+			t.Errorf("generated %s does not match its schema: %v", name, errors)
+		}
+	}
+}
+
+func TestExampleGeneratorSeed(t *testing.T) {
+	first, _ := json.Marshal(generateCatalogExamples(t, 3))
+	second, _ := json.Marshal(generateCatalogExamples(t, 3))
+	if !bytes.Equal(first, second) {
+		t.Errorf("the same seed produced different examples:\n%s\n%s", first, second)
+	}
+
+	other, _ := json.Marshal(generateCatalogExamples(t, 4))
+	if bytes.Equal(first, other) {
+		t.Error("different seeds produced the same examples")
+	}
+}
+
+func TestFillExamples(t *testing.T) {
+	defer os.RemoveAll("../../tmp/test-fill-docs")
+
+	cmd := internal.NewConvertCommand()
+	cmd.SetArgs([]string{"../examples/spec.yml", "-d", "../../tmp/test-fill-docs", "--common-prefix", "test", "--fill-examples"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("convert failed: %v", err)
+	}
+
+	data, err := os.ReadFile("../../tmp/test-fill-docs/test/spec.json")
+	if err != nil {
+		t.Fatalf("spec.json was not written: %v", err)
+	}
+
+	spec := struct {
+		Paths map[string]map[string]struct {
+			Parameters []struct {
+				Name    string      `json:"name"`
+				Example interface{} `json:"example"`
+			} `json:"parameters"`
+			RequestBody struct {
+				Content map[string]struct {
+					Example interface{} `json:"example"`
+				} `json:"content"`
+			} `json:"requestBody"`
+			Responses map[string]struct {
+				Content map[string]struct {
+					Example  interface{}            `json:"example"`
+					Examples map[string]interface{} `json:"examples"`
+				} `json:"content"`
+			} `json:"responses"`
+		} `json:"paths"`
+	}{}
+	if err := json.Unmarshal(data, &spec); err != nil {
+		t.Fatalf("spec.json is not valid JSON: %v", err)
+	}
+
+	users := spec.Paths["/users"]
+	if list, ok := users["get"].Responses["200"].Content["application/json"].Example.([]interface{}); !ok || len(list) == 0 {
+		t.Errorf("listUsers response example was not filled: %v", users["get"].Responses["200"])
+	}
+	if _, ok := users["get"].Parameters[0].Example.(float64); !ok {
+		t.Errorf("limit parameter example was not filled: %+v", users["get"].Parameters)
+	}
+	if created := users["post"].RequestBody.Content["application/json"].Example; created == nil {
+		t.Error("createUser request body example was not filled")
+	}
+
+	// Named examples are kept instead of adding a generated one
+	byID := spec.Paths["/users/{id}"]["get"].Responses["200"].Content["application/json"]
+	if byID.Example != nil || len(byID.Examples) != 2 {
+		t.Errorf("getUserById examples were changed: %+v", byID)
+	}
+}
+
+func TestFillExamplesOnCloneOnly(t *testing.T) {
+	conv, err := converter.NewOpenApiConverter("../examples/spec.yml")
+	if err != nil {
+		t.Fatalf("failed to load spec: %v", err)
+	}
+
+	docs, err := conv.Clone()
+	if err != nil {
+		t.Fatalf("Clone failed: %v", err)
+	}
+	docs.FillExamples(1)
+
+	limitExample := func(conv *converter.OpenAPIConverter) interface{} {
+		table, err := conv.RouteTable()
+		if err != nil {
+			t.Fatalf("RouteTable failed: %v", err)
+		}
+		for _, route := range table.Routes {
+			if route.Path == "/users" {
+				return route.Operations[0].Params[0].Example
+			}
+		}
+		t.Fatal("route /users not found")
+		return nil
+	}
+
+	if limitExample(docs) == nil {
+		t.Error("the clone did not get the filled examples")
+	}
+	if example := limitExample(conv); example != nil {
+		t.Errorf("filled examples leaked into the original document: %v", example)
+	}
+}
@@ -0,0 +1,116 @@
+package test
+
+import (
+	"bytes"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"strings"
+	"testing"
+	"github.com/nimling/openapi-converter/internal"
+)
+
+// typeCheckGo parses and type-checks a generated Go file, failing the test on any error
+func typeCheckGo(t *testing.T, path string) *types.Package {
+	t.Helper()
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("generated code does not parse: %v", err)
+	}
+
+	config := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	pkg, err := config.Check(file.Name.Name, fset, []*ast.File{file}, nil)
+	if err != nil {
+		t.Fatalf("generated code does not compile: %v", err)
+	}
+
+	return pkg
+}
+
+func TestGenerateGoClient(t *testing.T) {
+	defer os.RemoveAll("../../tmp/go-client")
+
+	var out bytes.Buffer
+	err := internal.RunGenerateGoClient("../examples/catalog.yml", internal.GenerateOptions{
+		OutputPath: "../../tmp/go-client/catalog/client.go",
+	}, &out)
+	if err != nil {
+		t.Fatalf("RunGenerateGoClient failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "✓ Generated Go client") {
+		t.Errorf("unexpected output: %s", out.String())
+	}
+
+	pkg := typeCheckGo(t, "../../tmp/go-client/catalog/client.go")
+	if pkg.Name() != "catalog" {
+		t.Errorf("package = %s, want catalog", pkg.Name())
+	}
+
+	for name, want := range map[string]string{
+		// allOf is merged into one struct with the required properties of both schemas
+		"Product":            "struct{Category *Category \"json:\\\"category,omitempty\\\"\"; CreatedAt time.Time \"json:\\\"createdAt\\\"\"; ID string \"json:\\\"id\\\"\"; Name string \"json:\\\"name\\\"\"; Price float64 \"json:\\\"price\\\"\"; Related []NewProduct \"json:\\\"related,omitempty\\\"\"; Tags []string \"json:\\\"tags,omitempty\\\"\"}",
+		"Category":           "string",
+		"OrderStatus":        "string",
+		"ListProductsParams": "struct{Limit *int64; Category *Category}",
+	} {
+		object := pkg.Scope().Lookup(name)
+		if object == nil {
+			t.Errorf("type %s is not declared", name)
+			continue
+		}
+		if got := types.TypeString(object.Type().Underlying(), types.RelativeTo(pkg)); got != want {
+			t.Errorf("%s = %s, want %s", name, got, want)
+		}
+	}
+
+	if pkg.Scope().Lookup("CategoryGames") == nil || pkg.Scope().Lookup("OrderStatusShipped") == nil {
+		t.Error("enum constants are not declared")
+	}
+
+	client := types.NewPointer(pkg.Scope().Lookup("Client").Type())
+	for name, want := range map[string]string{
+		"ListProducts":  "func(ctx context.Context, params *ListProductsParams) ([]Product, error)",
+		"CreateProduct": "func(ctx context.Context, body *NewProduct) (*Product, error)",
+		"GetProduct":    "func(ctx context.Context, productID string) (*Product, error)",
+		"DeleteProduct": "func(ctx context.Context, productID string) error",
+		"CreateOrder":   "func(ctx context.Context, body *Order) (*Order, error)",
+	} {
+		method, _, _ := types.LookupFieldOrMethod(client, true, pkg, name)
+		if method == nil {
+			t.Errorf("method %s is not declared", name)
+			continue
+		}
+		if got := types.TypeString(method.Type(), types.RelativeTo(pkg)); got != want {
+			t.Errorf("%s = %s, want %s", name, got, want)
+		}
+	}
+
+	source, _ := os.ReadFile("../../tmp/go-client/catalog/client.go")
+	for _, want := range []string{
+		"// Code generated by openapi-converter from ../examples/catalog.yml. DO NOT EDIT.",
+		`const DefaultServerURL = "https://catalog.example.com/api"`,
+		`path := "/products/" + url.PathEscape(formatParam(productID))`,
+		"// The raw JSON holds one of CardPayment, InvoicePayment",
+	} {
+		if !strings.Contains(string(source), want) {
+			t.Errorf("client is missing %q", want)
+		}
+	}
+}
+
+func TestGenerateGoClientPackageName(t *testing.T) {
+	var out bytes.Buffer
+	err := internal.RunGenerateGoClient("../examples/spec.yml", internal.GenerateOptions{Package: "users"}, &out)
+	if err != nil {
+		t.Fatalf("RunGenerateGoClient failed: %v", err)
+	}
+
+	if !strings.Contains(out.String(), "package users\n") {
+		t.Errorf("package name was not applied:\n%s", out.String())
+	}
+}
@@ -0,0 +1,202 @@
+package test
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"github.com/nimling/openapi-converter/internal"
+)
+
+// catalogServerTest implements the generated catalog server and checks the handlers over HTTP.
+// It is compiled together with the generated code
+const catalogServerTest = `package catalog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type service struct {
+	products []Product
+}
+
+func (s *service) CreateOrder(ctx context.Context, request CreateOrderRequest) (CreateOrderResponse, error) {
+	return CreateOrder201Response{Body: request.Body}, nil
+}
+
+func (s *service) ListProducts(ctx context.Context, request ListProductsRequest) (ListProductsResponse, error) {
+	products := []Product{}
+	for _, product := range s.products {
+		if request.Category == nil || (product.Category != nil && *product.Category == *request.Category) {
+			products = append(products, product)
+		}
+	}
+	if request.Limit != nil && int(*request.Limit) < len(products) {
+		products = products[:*request.Limit]
+	}
+
+	header := http.Header{"X-Total-Count": {strconv.Itoa(len(products))}}
+	return ListProducts200Response{Header: header, Body: products}, nil
+}
+
+func (s *service) CreateProduct(ctx context.Context, request CreateProductRequest) (CreateProductResponse, error) {
+	if request.Body.Price > 999 {
+		return CreateProduct400Response{Body: Problem{Title: "Bad Request", Status: 400}}, nil
+	}
+
+	return CreateProduct201Response{Body: Product{ID: "p3", Name: request.Body.Name, Price: request.Body.Price, CreatedAt: time.Now()}}, nil
+}
+
+func (s *service) DeleteProduct(ctx context.Context, request DeleteProductRequest) (DeleteProductResponse, error) {
+	return DeleteProduct204Response{}, nil
+}
+
+func (s *service) GetProduct(ctx context.Context, request GetProductRequest) (GetProductResponse, error) {
+	if request.ProductID == "boom" {
+		return nil, errors.New("database is down")
+	}
+
+	for _, product := range s.products {
+		if product.ID == request.ProductID {
+			return GetProduct200Response{Body: product}, nil
+		}
+	}
+
+	return GetProduct404Response{Body: Problem{Title: "Not Found", Status: 404}}, nil
+}
+
+func TestHandler(t *testing.T) {
+	games := CategoryGames
+	svc := &service{products: []Product{
+		{ID: "p1", Name: "Chess", Price: 12.5, Category: &games},
+		{ID: "p2", Name: "Go", Price: 20, Category: &games},
+	}}
+
+	server := httptest.NewServer(Handler(svc, HandlerOptions{BasePath: BasePath}))
+	defer server.Close()
+
+	tests := []struct {
+		method   string
+		path     string
+		body     string
+		status   int
+		contains string
+	}{
+		{"GET", "/api/products?limit=1&category=games", "", 200, ` + "`" + `[{"category":"games","createdAt":"0001-01-01T00:00:00Z","id":"p1","name":"Chess","price":12.5}]` + "`" + `},
+		{"GET", "/api/products?limit=ten", "", 400, "query parameter limit must be of type integer"},
+		{"POST", "/api/products", ` + "`" + `{"name":"Chess","price":12.5}` + "`" + `, 201, ` + "`" + `"id":"p3"` + "`" + `},
+		{"POST", "/api/products", ` + "`" + `{"name":"Chess","price":2000}` + "`" + `, 400, ` + "`" + `"title":"Bad Request"` + "`" + `},
+		{"POST", "/api/products", "", 400, "request body is required"},
+		{"POST", "/api/products", "{", 400, "request body is not valid JSON"},
+		{"GET", "/api/products/p2", "", 200, ` + "`" + `"name":"Go"` + "`" + `},
+		{"GET", "/api/products/p9", "", 404, ` + "`" + `"title":"Not Found"` + "`" + `},
+		{"GET", "/api/products/boom", "", 500, ` + "`" + `"title":"Internal Server Error"` + "`" + `},
+		{"DELETE", "/api/products/p1", "", 204, ""},
+		{"PUT", "/api/products/p1", "", 405, ""},
+	}
+
+	for _, tt := range tests {
+		req, _ := http.NewRequest(tt.method, server.URL+tt.path, strings.NewReader(tt.body))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s failed: %v", tt.method, tt.path, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode != tt.status || !strings.Contains(string(body), tt.contains) {
+			t.Errorf("%s %s = %d %s, want %d containing %s", tt.method, tt.path, resp.StatusCode, body, tt.status, tt.contains)
+		}
+		if strings.Contains(string(body), "database is down") {
+			t.Errorf("%s %s exposes the server error: %s", tt.method, tt.path, body)
+		}
+		if tt.status == 200 && tt.path == "/api/products?limit=1&category=games" && resp.Header.Get("X-Total-Count") != "1" {
+			t.Errorf("response header was not written: %v", resp.Header)
+		}
+		if tt.status == 404 && resp.Header.Get("Content-Type") != "application/problem+json" {
+			t.Errorf("404 Content-Type = %s", resp.Header.Get("Content-Type"))
+		}
+	}
+
+	var problem map[string]interface{}
+	resp, _ := http.Get(server.URL + "/api/products?limit=ten")
+	json.NewDecoder(resp.Body).Decode(&problem)
+	resp.Body.Close()
+	if problem["status"] != float64(400) || problem["type"] != "about:blank" {
+		t.Errorf("unexpected problem %v", problem)
+	}
+}
+`
+
+func TestGenerateGoServer(t *testing.T) {
+	defer os.RemoveAll("../../tmp/go-server")
+
+	var out bytes.Buffer
+	err := internal.RunGenerateGoServer("../examples/catalog.yml", internal.GenerateOptions{
+		OutputPath: "../../tmp/go-server/catalog/server.go",
+	}, &out)
+	if err != nil {
+		t.Fatalf("RunGenerateGoServer failed: %v", err)
+	}
+
+	typeCheckGo(t, "../../tmp/go-server/catalog/server.go")
+
+	source, _ := os.ReadFile("../../tmp/go-server/catalog/server.go")
+	for _, want := range []string{
+		`const BasePath = "/api"`,
+		"GetProduct(ctx context.Context, request GetProductRequest) (GetProductResponse, error)",
+		`mux.HandleFunc("GET "+basePath+"/products/{productID}", h.handleGetProduct)`,
+		`mux.HandleFunc("DELETE "+basePath+"/products/{productID}", h.handleDeleteProduct)`,
+		`if err := bindParam(r, "path", "productId", "productID", true, &request.ProductID); err != nil {`,
+		"// GetProduct404Response is the 404 response of GetProduct: The product does not exist",
+	} {
+		if !strings.Contains(string(source), want) {
+			t.Errorf("server is missing %q", want)
+		}
+	}
+
+	// The handlers are exercised by a test compiled with the generated package
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go is not installed")
+	}
+	if err := os.WriteFile("../../tmp/go-server/catalog/server_test.go", []byte(catalogServerTest), 0644); err != nil {
+		t.Fatalf("failed to write server test: %v", err)
+	}
+
+	cmd := exec.Command("go", "test", "./")
+	cmd.Dir = "../../tmp/go-server/catalog"
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("generated server test failed: %v\n%s", err, output)
+	}
+}
+
+func TestGenerateGoServerRejectsPartialSegments(t *testing.T) {
+	defer os.RemoveAll("../../tmp/go-server-partial")
+
+	data, err := os.ReadFile("../examples/catalog.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec := strings.Replace(string(data), "/products/{productId}:", "/products/{productId}.json:", 1)
+	if err := os.MkdirAll("../../tmp/go-server-partial", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile("../../tmp/go-server-partial/catalog.yml", []byte(spec), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err = internal.RunGenerateGoServer("../../tmp/go-server-partial/catalog.yml", internal.GenerateOptions{}, &bytes.Buffer{})
+	if err == nil || !strings.Contains(err.Error(), "is not a whole segment") {
+		t.Errorf("expected a whole segment error, got %v", err)
+	}
+}
@@ -0,0 +1,116 @@
+package test
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"github.com/nimling/openapi-converter/converter"
+	"github.com/nimling/openapi-converter/internal"
+)
+
+func TestHTTPFiles(t *testing.T) {
+	conv, err := converter.NewOpenApiConverter("../examples/catalog.yml")
+	if err != nil {
+		t.Fatalf("failed to load spec: %v", err)
+	}
+
+	files, err := conv.WriteHTTPFiles()
+	if err != nil {
+		t.Fatalf("WriteHTTPFiles failed: %v", err)
+	}
+	if len(files) != 2 || files[".products.http"] == "" || files[".orders.http"] == "" {
+		t.Fatalf("expected a file per tag, got %v", files)
+	}
+
+	products := files[".products.http"]
+	for _, want := range []string{
+		"@baseUrl = https://catalog.example.com/api\n@bearerAuth =\n",
+		"### Get product\n# Returns a single product.\n# @name getProduct\nGET {{baseUrl}}/products/",
+		"Authorization: Bearer {{bearerAuth}}\n",
+		"POST {{baseUrl}}/products\nAuthorization: Bearer {{bearerAuth}}\nAccept: application/json, application/problem+json\nContent-Type: application/json\n\n{\n",
+		// Optional parameters without an example are left out
+		"GET {{baseUrl}}/products\n",
+	} {
+		if !strings.Contains(products, want) {
+			t.Errorf("products.http is missing %q:\n%s", want, products)
+		}
+	}
+	if strings.Contains(products, "createOrder") || strings.Contains(products, "@apiKey") {
+		t.Errorf("products.http holds the orders requests:\n%s", products)
+	}
+
+	if !strings.Contains(files[".orders.http"], "X-API-Key: {{apiKey}}\n") {
+		t.Errorf("orders.http does not send the API key:\n%s", files[".orders.http"])
+	}
+}
+
+func TestHTTPFilesExamples(t *testing.T) {
+	conv, err := converter.NewOpenApiConverter("../examples/gateway.yml")
+	if err != nil {
+		t.Fatalf("failed to load spec: %v", err)
+	}
+
+	files, err := conv.WriteHTTPFiles()
+	if err != nil {
+		t.Fatalf("WriteHTTPFiles failed: %v", err)
+	}
+
+	// Untagged operations share one file
+	gateway := files[".http"]
+	for _, want := range []string{
+		"@basicAuthPassword =\n@basicAuthUsername =\n",
+		"Authorization: Basic {{basicAuthUsername}} {{basicAuthPassword}}\n",
+	} {
+		if !strings.Contains(gateway, want) {
+			t.Errorf(".http is missing %q:\n%s", want, gateway)
+		}
+	}
+}
+
+func TestHTTPFilesRequestExample(t *testing.T) {
+	defer os.RemoveAll("../../tmp/http-example")
+
+	data, err := os.ReadFile("../examples/catalog.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	example := "              $ref: '#/components/schemas/NewProduct'\n            example:\n              name: Chess\n              price: 12.5\n"
+	spec := strings.Replace(string(data), "              $ref: '#/components/schemas/NewProduct'\n", example, 1)
+	if err := os.MkdirAll("../../tmp/http-example", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile("../../tmp/http-example/catalog.yml", []byte(spec), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	conv, err := converter.NewOpenApiConverter("../../tmp/http-example/catalog.yml")
+	if err != nil {
+		t.Fatalf("failed to load spec: %v", err)
+	}
+	files, err := conv.WriteHTTPFiles()
+	if err != nil {
+		t.Fatalf("WriteHTTPFiles failed: %v", err)
+	}
+
+	want := "Content-Type: application/json\n\n{\n  \"name\": \"Chess\",\n  \"price\": 12.5\n}\n"
+	if !strings.Contains(files[".products.http"], want) {
+		t.Errorf("body is not the request body example:\n%s", files[".products.http"])
+	}
+}
+
+func TestConvertHTTPFiles(t *testing.T) {
+	defer os.RemoveAll("../../tmp/http")
+
+	err := internal.RunConvertWithOptions([]string{"../examples/catalog.yml"}, internal.ConvertOptions{
+		HTTPFilePath: "../../tmp/http",
+	})
+	if err != nil {
+		t.Fatalf("RunConvert failed: %v", err)
+	}
+
+	for _, name := range []string{"catalog.orders.http", "catalog.products.http"} {
+		if _, err := os.Stat("../../tmp/http/" + name); err != nil {
+			t.Errorf("%s was not written: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"github.com/nimling/openapi-converter/converter"
+	"github.com/nimling/openapi-converter/internal"
+)
+
+func loadJSONSchema(t *testing.T, path string) map[string]interface{} {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("schema was not written: %v", err)
+	}
+
+	var schema map[string]interface{}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("%s is not valid JSON: %v\n%s", path, err, data)
+	}
+
+	return schema
+}
+
+func TestJSONSchemaFiles(t *testing.T) {
+	defer os.RemoveAll("../../tmp/jsonschema")
+
+	var out bytes.Buffer
+	err := internal.RunJSONSchema("../examples/catalog.yml", internal.JSONSchemaOptions{
+		OutputPath: "../../tmp/jsonschema",
+		BaseID:     "https://schemas.example.com/catalog",
+	}, &out)
+	if err != nil {
+		t.Fatalf("RunJSONSchema failed: %v", err)
+	}
+
+	for _, name := range []string{"CardPayment", "Category", "InvoicePayment", "NewProduct", "Order", "OrderItem", "Problem", "Product"} {
+		if !strings.Contains(out.String(), name+".json") {
+			t.Errorf("%s.json was not generated:\n%s", name, out.String())
+		}
+	}
+
+	product := loadJSONSchema(t, "../../tmp/jsonschema/Product.json")
+	if product["$schema"] != "https://json-schema.org/draft/2020-12/schema" || product["$id"] != "https://schemas.example.com/catalog/Product.json" {
+		t.Errorf("unexpected $schema or $id: %v %v", product["$schema"], product["$id"])
+	}
+
+	// References to other components point at their files, references to the schema itself at the root
+	allOf := product["allOf"].([]interface{})
+	if ref := allOf[0].(map[string]interface{})["$ref"]; ref != "NewProduct.json" {
+		t.Errorf("allOf reference = %v, want NewProduct.json", ref)
+	}
+	related := loadJSONSchema(t, "../../tmp/jsonschema/NewProduct.json")["properties"].(map[string]interface{})["related"]
+	if ref := related.(map[string]interface{})["items"].(map[string]interface{})["$ref"]; ref != "#" {
+		t.Errorf("self reference = %v, want #", ref)
+	}
+}
+
+func TestJSONSchemaDefs(t *testing.T) {
+	conv, err := converter.NewOpenApiConverter("../examples/catalog.yml")
+	if err != nil {
+		t.Fatalf("failed to load spec: %v", err)
+	}
+
+	files, err := conv.WriteJSONSchemas("", true)
+	if err != nil {
+		t.Fatalf("WriteJSONSchemas failed: %v", err)
+	}
+
+	var product map[string]interface{}
+	if err := json.Unmarshal([]byte(files["Product.json"]), &product); err != nil {
+		t.Fatalf("Product.json is not valid JSON: %v", err)
+	}
+
+	// Every schema reachable from Product is embedded, with references into $defs
+	defs, _ := product["$defs"].(map[string]interface{})
+	if len(defs) != 2 || defs["NewProduct"] == nil || defs["Category"] == nil {
+		t.Errorf("$defs = %v, want NewProduct and Category", defs)
+	}
+	if !strings.Contains(files["Product.json"], `"$ref": "#/$defs/NewProduct"`) || !strings.Contains(files["Product.json"], `"$ref": "#/$defs/Category"`) {
+		t.Errorf("references are not rewritten to $defs:\n%s", files["Product.json"])
+	}
+	if product["$id"] != "Product.json" {
+		t.Errorf("$id = %v, want Product.json", product["$id"])
+	}
+}
+
+func TestJSONSchemaNullable(t *testing.T) {
+	defer os.RemoveAll("../../tmp/jsonschema-nullable")
+
+	data, err := os.ReadFile("../examples/catalog.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec := strings.Replace(string(data), "        contact:\n          type: string\n", "        contact:\n          type: string\n          nullable: true\n          example: ada@example.com\n", 1)
+	if err := os.MkdirAll("../../tmp/jsonschema-nullable", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile("../../tmp/jsonschema-nullable/catalog.yml", []byte(spec), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	conv, err := converter.NewOpenApiConverter("../../tmp/jsonschema-nullable/catalog.yml")
+	if err != nil {
+		t.Fatalf("failed to load spec: %v", err)
+	}
+	files, err := conv.WriteJSONSchemas("", false)
+	if err != nil {
+		t.Fatalf("WriteJSONSchemas failed: %v", err)
+	}
+
+	var order map[string]interface{}
+	if err := json.Unmarshal([]byte(files["Order.json"]), &order); err != nil {
+		t.Fatalf("Order.json is not valid JSON: %v", err)
+	}
+
+	contact := order["properties"].(map[string]interface{})["contact"].(map[string]interface{})
+	if !reflect.DeepEqual(contact["type"], []interface{}{"string", "null"}) {
+		t.Errorf("nullable type = %v, want [string null]", contact["type"])
+	}
+	if !reflect.DeepEqual(contact["examples"], []interface{}{"ada@example.com"}) || contact["nullable"] != nil || contact["example"] != nil {
+		t.Errorf("OpenAPI keywords are not converted: %v", contact)
+	}
+}
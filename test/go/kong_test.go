@@ -0,0 +1,185 @@
+package test
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"github.com/nimling/openapi-converter/converter"
+	"github.com/nimling/openapi-converter/kong"
+	"gopkg.in/yaml.v3"
+)
+
+func loadKongConfig(t *testing.T, specPath string, commonPrefix string) *kong.Config {
+	t.Helper()
+
+	conv, err := converter.NewOpenApiConverter(specPath)
+	if err != nil {
+		t.Fatalf("failed to load spec: %v", err)
+	}
+	conv.CommonPrefix = commonPrefix
+
+	output, err := conv.WriteKongConfiguration()
+	if err != nil {
+		t.Fatalf("WriteKongConfiguration failed: %v", err)
+	}
+
+	config := &kong.Config{}
+	if err := yaml.Unmarshal([]byte(output), config); err != nil {
+		t.Fatalf("output is not valid YAML: %v\n%s", err, output)
+	}
+
+	return config
+}
+
+func pluginNames(plugins []*kong.Plugin) []string {
+	names := make([]string, 0, len(plugins))
+	for _, plugin := range plugins {
+		names = append(names, plugin.Name)
+	}
+
+	return names
+}
+
+func TestKongDeclarativeConfig(t *testing.T) {
+	config := loadKongConfig(t, "../examples/gateway.yml", "")
+
+	if config.FormatVersion != kong.FormatVersion || len(config.Services) != 1 {
+		t.Fatalf("unexpected config %+v", config)
+	}
+
+	service := config.Services[0]
+	if service.URL != "https://orders.example.com/api" {
+		t.Errorf("service url = %q, want servers[0]", service.URL)
+	}
+	if service.ConnectTimeout != 5000 || service.ReadTimeout != 30000 {
+		t.Errorf("timeouts not taken from x-nginx: connect=%d read=%d", service.ConnectTimeout, service.ReadTimeout)
+	}
+	if got := pluginNames(service.Plugins); len(got) != 2 || got[0] != "cors" || got[1] != "rate-limiting" {
+		t.Errorf("document-level plugins = %v, want [cors rate-limiting]", got)
+	}
+
+	routes := map[string]*kong.Route{}
+	for _, route := range service.Routes {
+		routes[route.Name] = route
+	}
+
+	cases := map[string][]string{
+		"gateway_listOrders":       {"jwt"},
+		"gateway_uploadOrders":     {"key-auth", "rate-limiting"},
+		"gateway_generateReport":   {"basic-auth", "cors"},
+		"gateway_orders_preflight": {},
+	}
+	for name, want := range cases {
+		route, ok := routes[name]
+		if !ok {
+			t.Errorf("route %s is missing", name)
+			continue
+		}
+
+		got := pluginNames(route.Plugins)
+		if len(got) != len(want) {
+			t.Errorf("route %s plugins = %v, want %v", name, got, want)
+			continue
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("route %s plugins = %v, want %v", name, got, want)
+				break
+			}
+		}
+	}
+
+	keyAuth := routes["gateway_uploadOrders"].Plugins[0]
+	if names, ok := keyAuth.Config["key_names"].([]interface{}); !ok || len(names) != 1 || names[0] != "X-API-Key" {
+		t.Errorf("key-auth key_names = %v", keyAuth.Config["key_names"])
+	}
+	if second := routes["gateway_uploadOrders"].Plugins[1].Config["second"]; second != 1 {
+		t.Errorf("operation rate limit = %v, want 1 per second", second)
+	}
+}
+
+func TestKongRegexPathsAndStripPath(t *testing.T) {
+	config := loadKongConfig(t, "../examples/spec.yml", "/users")
+
+	routes := map[string]*kong.Route{}
+	for _, route := range config.Services[0].Routes {
+		routes[route.Name] = route
+	}
+
+	// Kong 3.x routers support no lookarounds, so nothing may use them
+	for name, route := range routes {
+		for _, path := range route.Paths {
+			if strings.Contains(path, "(?=") {
+				t.Errorf("route %s uses a lookahead: %s", name, path)
+			}
+		}
+	}
+
+	// The prefix matches exactly and is stripped like the Nginx rewrite strips it
+	literal := routes["spec_listUsers"]
+	if literal == nil || len(literal.Paths) != 1 || literal.Paths[0] != "~/users$" || !literal.StripPath {
+		t.Errorf("prefix route = %+v, want stripped ~/users$", literal)
+	}
+
+	// Paths below the prefix keep the rest of the path behind the base path
+	templated := routes["spec_getUserById"]
+	if templated == nil || len(templated.Paths) != 1 || templated.Paths[0] != "~/users(?P<rest>/[^/]+)$" {
+		t.Fatalf("templated route = %+v, want an anchored regex capturing the rest", templated)
+	}
+	if templated.StripPath || templated.RegexPriority >= literal.RegexPriority {
+		t.Errorf("templated route must not strip and must be tried after literal paths: %+v", templated)
+	}
+	if got := pluginNames(templated.Plugins); len(got) != 1 || got[0] != "request-transformer" {
+		t.Fatalf("templated route plugins = %v, want [request-transformer]", got)
+	}
+	replace, _ := templated.Plugins[0].Config["replace"].(map[string]interface{})
+	if replace["uri"] != "/v1$(uri_captures['rest'])" {
+		t.Errorf("request-transformer uri = %v", replace["uri"])
+	}
+}
+
+func TestKongLiteralPathsBeforeTemplated(t *testing.T) {
+	config := loadKongConfig(t, "../examples/profiles.yml", "")
+
+	routes := map[string]*kong.Route{}
+	for _, route := range config.Services[0].Routes {
+		routes[route.Name] = route
+	}
+
+	literal, templated := routes["profiles_getCurrentProfile"], routes["profiles_deleteProfile"]
+	if literal == nil || len(literal.Paths) != 1 || literal.Paths[0] != "~/users/me$" {
+		t.Fatalf("literal route = %+v, want an anchored ~/users/me$", literal)
+	}
+	if templated == nil || templated.RegexPriority >= literal.RegexPriority {
+		t.Errorf("/users/{id} must be tried after /users/me: %+v", templated)
+	}
+}
+
+func TestKongRejectsUnenforceableAuth(t *testing.T) {
+	data, err := os.ReadFile("../examples/gateway.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Kong runs every plugin of a route, so alternative requirements cannot be expressed
+	spec := strings.Replace(string(data), "      operationId: listOrders\n", "      operationId: listOrders\n      security:\n        - apiKey: []\n        - bearerAuth: []\n", 1)
+	if err := os.MkdirAll("../../tmp/kong-reject", 0755); err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll("../../tmp/kong-reject")
+	if err := os.WriteFile("../../tmp/kong-reject/gateway.yml", []byte(spec), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config := loadKongConfig(t, "../../tmp/kong-reject/gateway.yml", "")
+
+	for _, route := range config.Services[0].Routes {
+		if route.Name != "gateway_listOrders" {
+			continue
+		}
+		if got := pluginNames(route.Plugins); len(got) == 0 || got[0] != "request-termination" || route.Plugins[0].Config["status_code"] != 401 {
+			t.Errorf("listOrders plugins = %v, want a request-termination answering 401", got)
+		}
+		return
+	}
+	t.Error("route gateway_listOrders is missing")
+}
@@ -1,8 +1,10 @@
 package test
 
 import (
+	"io"
 	"os"
 	"testing"
+	"github.com/nimling/openapi-converter/internal"
 )
 
 func TestMain(m *testing.M) {
@@ -15,4 +17,20 @@ func TestMain(m *testing.M) {
 	os.RemoveAll("../examples/output")
 	
 	os.Exit(code)
-}
\ No newline at end of file
+}
+func TestBannerLeavesStdoutClean(t *testing.T) {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = writer
+	internal.PrintBanner()
+	os.Stdout = stdout
+	writer.Close()
+
+	// generate and routes write code and JSON to stdout, which must stay redirectable to a file
+	if output, _ := io.ReadAll(reader); len(output) > 0 {
+		t.Errorf("banner was written to stdout: %q", output)
+	}
+}
@@ -0,0 +1,180 @@
+package test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"github.com/nimling/openapi-converter/converter"
+	"github.com/nimling/openapi-converter/middleware"
+)
+
+// catalogService is a handler implementing the catalog spec, with a few deliberate violations
+func catalogService() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/products/{productId}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("broken") != "" {
+			// price is a string and createdAt is missing
+			w.Write([]byte(`{"id":"` + middleware.PathParam(r, "productId") + `","name":"Chess","price":"12"}`))
+			return
+		}
+		w.Write([]byte(`{"id":"` + middleware.PathParam(r, "productId") + `","name":"Chess","price":12.5,"createdAt":"2024-01-01T00:00:00Z"}`))
+	})
+	mux.HandleFunc("POST /api/products", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(strings.TrimSuffix(string(body), "}") + `,"id":"3fa85f64-5717-4562-b3fc-2c963f66afa6","createdAt":"2024-01-01T00:00:00Z"}`))
+	})
+	mux.HandleFunc("/api/health", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+
+	return mux
+}
+
+func TestValidationMiddleware(t *testing.T) {
+	conv, err := converter.NewOpenApiConverter("../examples/catalog.yml")
+	if err != nil {
+		t.Fatalf("failed to load spec: %v", err)
+	}
+
+	validator, err := middleware.New(conv, middleware.Options{BasePath: "/api/", ValidateResponses: true})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	server := httptest.NewServer(validator.Middleware(catalogService()))
+	defer server.Close()
+
+	const product = "/api/products/3fa85f64-5717-4562-b3fc-2c963f66afa6"
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		status   int
+		contains string
+	}{
+		{"valid request and response", "GET", product, "", 200, `"name":"Chess"`},
+		{"invalid parameter type", "GET", "/api/products?limit=ten", "", 400, "query parameter limit must be of type integer"},
+		{"invalid query parameter", "GET", "/api/products?limit=0", "", 400, "query parameter limit must be at least 1"},
+		{"enum violation", "GET", "/api/products?category=films", "", 400, `query parameter category must be one of [\"books\",\"games\",\"music\"]`},
+		{"missing required property", "POST", "/api/products", `{"name":"Chess"}`, 400, "body.price is required"},
+		{"bounds violation", "POST", "/api/products", `{"name":"Go","price":1}`, 400, "body.name must be at least 3 characters long"},
+		{"valid body", "POST", "/api/products", `{"name":"Chess","price":12.5}`, 201, `"price":12.5`},
+		{"invalid response", "GET", product + "?broken=1", "", 500, "response.price must be of type number"},
+		{"undeclared method", "PUT", product, "", 405, "has no PUT operation"},
+		{"unknown path", "GET", "/api/health", "", 404, "no path of the spec matches /api/health"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, server.URL+tt.path, strings.NewReader(tt.body))
+			if tt.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+
+			if resp.StatusCode != tt.status {
+				t.Errorf("status = %d, want %d: %s", resp.StatusCode, tt.status, body)
+			}
+			if !strings.Contains(string(body), tt.contains) {
+				t.Errorf("body %s does not contain %q", body, tt.contains)
+			}
+
+			if resp.StatusCode >= 400 {
+				problem := &converter.Problem{}
+				if err := json.Unmarshal(body, problem); err != nil || resp.Header.Get("Content-Type") != "application/problem+json" {
+					t.Errorf("error response is not a problem: %s", body)
+				}
+				if problem.Status != resp.StatusCode || problem.Title != http.StatusText(resp.StatusCode) {
+					t.Errorf("unexpected problem %+v", problem)
+				}
+			}
+		})
+	}
+}
+
+func TestValidationMiddlewareOptions(t *testing.T) {
+	conv, err := converter.NewOpenApiConverter("../examples/catalog.yml")
+	if err != nil {
+		t.Fatalf("failed to load spec: %v", err)
+	}
+
+	var handled *converter.Problem
+	validator, err := middleware.New(conv, middleware.Options{
+		BasePath:          "/api",
+		AllowUnknownPaths: true,
+		ProblemHandler: func(w http.ResponseWriter, r *http.Request, problem *converter.Problem) {
+			handled = problem
+			w.WriteHeader(problem.Status)
+		},
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	handler := validator.Middleware(catalogService())
+
+	// Paths outside the spec reach the service
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/api/health", nil))
+	if recorder.Code != 200 || recorder.Body.String() != "ok" {
+		t.Errorf("unknown path was not passed through: %d %s", recorder.Code, recorder.Body.String())
+	}
+
+	// Responses are not validated unless asked for
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/api/products/3fa85f64-5717-4562-b3fc-2c963f66afa6?broken=1", nil))
+	if recorder.Code != 200 {
+		t.Errorf("status = %d, want the unvalidated 200", recorder.Code)
+	}
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("POST", "/api/products", strings.NewReader("name=chess")))
+	if recorder.Code != 415 || handled == nil || handled.Status != 415 {
+		t.Errorf("custom problem handler was not called: %d %+v", recorder.Code, handled)
+	}
+}
+
+func TestValidationMiddlewareLiteralSibling(t *testing.T) {
+	conv, err := converter.NewOpenApiConverter("../examples/profiles.yml")
+	if err != nil {
+		t.Fatalf("failed to load spec: %v", err)
+	}
+
+	validator, err := middleware.New(conv, middleware.Options{})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	handler := validator.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+
+	tests := []struct {
+		path   string
+		status int
+	}{
+		{"/users/me", 200},
+		{"/users/meXYZ", 405},
+		{"/users/me/extra", 404},
+	}
+
+	for _, tt := range tests {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest("GET", tt.path, nil))
+		if recorder.Code != tt.status {
+			t.Errorf("GET %s: status = %d, want %d: %s", tt.path, recorder.Code, tt.status, recorder.Body)
+		}
+	}
+}
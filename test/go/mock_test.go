@@ -0,0 +1,111 @@
+package test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"github.com/nimling/openapi-converter/converter"
+	"github.com/nimling/openapi-converter/internal"
+)
+
+func TestMockServer(t *testing.T) {
+	handler, err := internal.NewMockHandler("../examples/spec.yml", internal.MockOptions{Validate: true})
+	if err != nil {
+		t.Fatalf("NewMockHandler failed: %v", err)
+	}
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		headers     map[string]string
+		body        string
+		status      int
+		contentType string
+		contains    string
+		excludes    string
+	}{
+		{"first named example", "GET", "/users/1", nil, "", 200, "application/json", `"name":"Alice"`, ""},
+		{"preferred example", "GET", "/users/2", map[string]string{"Prefer": "example=bob"}, "", 200, "application/json", `"name":"Bob"`, ""},
+		{"preferred status", "GET", "/users/3", map[string]string{"Prefer": "code=404"}, "", 404, "", "", ""},
+		{"undeclared status", "GET", "/users/3", map[string]string{"Prefer": "code=418"}, "", 500, "application/problem+json", "no response for status 418", ""},
+		{"synthesized from schema", "GET", "/users", nil, "", 200, "application/json", `@example.com"`, ""},
+		{"dynamic payload", "GET", "/users/1", map[string]string{"Prefer": "dynamic=true"}, "", 200, "application/json", `@example.com"`, "alice@example.com"},
+		{"invalid query parameter", "GET", "/users?limit=ten", nil, "", 400, "application/problem+json", "query parameter limit must be of type integer", ""},
+		{"missing required property", "POST", "/users", map[string]string{"Content-Type": "application/json"}, `{"id":"1","name":"Alice"}`, 400, "application/problem+json", "body.email is required", ""},
+		{"unsupported content type", "POST", "/users", map[string]string{"Content-Type": "text/plain"}, "hello", 415, "application/problem+json", "content type text/plain", ""},
+		{"valid body", "POST", "/users", map[string]string{"Content-Type": "application/json"}, `{"id":"1","name":"Alice","email":"alice@example.com"}`, 201, "application/json", `"id":`, ""},
+		{"unknown path", "GET", "/orders", nil, "", 404, "application/problem+json", "no path", ""},
+		{"undeclared method", "DELETE", "/users/1", nil, "", 405, "application/problem+json", "has no DELETE operation", ""},
+		{"not acceptable", "GET", "/users/1", map[string]string{"Accept": "text/html"}, "", 406, "application/problem+json", "only available as application/json", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, server.URL+tt.path, strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatalf("failed to create request: %v", err)
+			}
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+
+			if resp.StatusCode != tt.status {
+				t.Errorf("status = %d, want %d: %s", resp.StatusCode, tt.status, body)
+			}
+			if got := resp.Header.Get("Content-Type"); got != tt.contentType {
+				t.Errorf("Content-Type = %q, want %q", got, tt.contentType)
+			}
+			if !strings.Contains(string(body), tt.contains) {
+				t.Errorf("body %s does not contain %q", body, tt.contains)
+			}
+			if tt.excludes != "" && strings.Contains(string(body), tt.excludes) {
+				t.Errorf("body %s contains %q", body, tt.excludes)
+			}
+		})
+	}
+}
+
+func TestMockServerProblemResponse(t *testing.T) {
+	conv, err := converter.NewOpenApiConverter("../examples/spec.yml")
+	if err != nil {
+		t.Fatalf("failed to load spec: %v", err)
+	}
+
+	server, err := conv.MockServer()
+	if err != nil {
+		t.Fatalf("MockServer failed: %v", err)
+	}
+
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, httptest.NewRequest("POST", "/users", strings.NewReader("{")))
+
+	problem := &converter.Problem{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), problem); err != nil {
+		t.Fatalf("response is not a problem: %v\n%s", err, recorder.Body.String())
+	}
+	if problem.Status != 415 || problem.Title != "Unsupported Media Type" || problem.Type != "about:blank" {
+		t.Errorf("unexpected problem %+v", problem)
+	}
+
+	// Validation can be turned off to mock clients that are still being written
+	server.Validate = false
+	recorder = httptest.NewRecorder()
+	server.ServeHTTP(recorder, httptest.NewRequest("POST", "/users", strings.NewReader("{")))
+	if recorder.Code != 201 {
+		t.Errorf("status = %d without validation, want 201", recorder.Code)
+	}
+}
@@ -0,0 +1,142 @@
+package test
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"github.com/nimling/openapi-converter/converter"
+	"github.com/nimling/openapi-converter/internal"
+)
+
+func TestNginxAuthFromSecurityRequirements(t *testing.T) {
+	conv := loadGatewayConverter(t)
+	conv.HTTPContext = converter.NewHTTPContext()
+
+	config, err := conv.WriteNginxConfiguration()
+	if err != nil {
+		t.Fatalf("WriteNginxConfiguration failed: %v", err)
+	}
+
+	for _, want := range []string{
+		// uploadOrders needs an API key, listOrders a bearer token
+		"if ($auth_key_allowed_gateway_orders_apiKey = 0) {\n        return 401;",
+		"auth_request /_auth/gateway_orders;",
+		"location = /_auth/gateway_orders {\n    internal;",
+		"if ($request_method !~ ^(GET)$) {\n        return 204;",
+		"proxy_pass https://auth.example.com/oauth2/introspect;",
+		"proxy_pass_request_body off;",
+		`proxy_set_header X-Required-Scopes "orders:read";`,
+		// generateReport needs Basic auth for every method of /reports
+		`auth_basic "basicAuth";`,
+		"auth_basic_user_file /etc/nginx/auth/gateway_basicAuth.htpasswd;",
+	} {
+		if !strings.Contains(config, want) {
+			t.Errorf("config is missing %q:\n%s", want, config)
+		}
+	}
+
+	if strings.Contains(config, "_by_lua_block") {
+		t.Errorf("config still contains Lua claim checks:\n%s", config)
+	}
+
+	// Preflight requests are answered before any credentials are checked
+	orders := config[strings.Index(config, "location = /orders"):]
+	if strings.Index(orders, "return 204;") > strings.Index(orders, "auth_request") {
+		t.Errorf("CORS preflight must come before the authentication checks:\n%s", orders)
+	}
+
+	httpContext, err := conv.HTTPContext.Render()
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"map $http_x_api_key $auth_key_gateway_apiKey {\n    default 0;\n    include /etc/nginx/auth/gateway_apiKey.map;\n}",
+		"map \"$request_method:$auth_key_gateway_apiKey\" $auth_key_allowed_gateway_orders_apiKey {\n    default 1;\n    POST:0 0;\n}",
+	} {
+		if !strings.Contains(httpContext, want) {
+			t.Errorf("http context is missing %q:\n%s", want, httpContext)
+		}
+	}
+
+	if err := converter.CheckNginxConfig("gateway.conf", config); err != nil {
+		t.Errorf("generated config fails the check: %v", err)
+	}
+	if err := converter.CheckNginxConfig("http.conf", httpContext); err != nil {
+		t.Errorf("generated http context fails the check: %v", err)
+	}
+}
+
+func TestConvertUnenforceableAuth(t *testing.T) {
+	defer os.RemoveAll("../../tmp/unenforced-nginx")
+
+	// catalog.yml uses bearer tokens without x-auth, which Nginx cannot check, so those
+	// operations fail closed
+	err := internal.RunConvertWithOptions([]string{"../examples/catalog.yml"}, internal.ConvertOptions{
+		OutputPath: "../../tmp/unenforced-nginx",
+	})
+	if err != nil {
+		t.Fatalf("RunConvert failed: %v", err)
+	}
+
+	data, err := os.ReadFile("../../tmp/unenforced-nginx/catalog.conf.template")
+	if err != nil {
+		t.Fatalf("config was not written: %v", err)
+	}
+	config := string(data)
+
+	products := config[strings.Index(config, "location = /products {"):]
+	products = products[:strings.Index(products, "\n}\n")]
+	for _, want := range []string{
+		"# rejected, cannot be enforced: GET: security scheme 'bearerAuth' needs x-auth introspectionUrl to check bearer tokens",
+		"    return 401;",
+	} {
+		if !strings.Contains(products, want) {
+			t.Errorf("products location is missing %q:\n%s", want, products)
+		}
+	}
+	if strings.Contains(config, "auth_request") {
+		t.Errorf("config sends tokens to an introspection endpoint it does not have:\n%s", config)
+	}
+}
+
+func TestNginxRejectsUnenforceableMethods(t *testing.T) {
+	data, err := os.ReadFile("../examples/catalog.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// deleteProduct needs an API key, getProduct keeps the bearer token Nginx cannot check
+	spec := strings.Replace(string(data), "      operationId: deleteProduct\n", "      operationId: deleteProduct\n      security:\n        - apiKey: []\n", 1)
+	if err := os.MkdirAll("../../tmp/nginx-reject", 0755); err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll("../../tmp/nginx-reject")
+	if err := os.WriteFile("../../tmp/nginx-reject/catalog.yml", []byte(spec), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	conv, err := converter.NewOpenApiConverter("../../tmp/nginx-reject/catalog.yml")
+	if err != nil {
+		t.Fatalf("failed to load spec: %v", err)
+	}
+	conv.HTTPContext = converter.NewHTTPContext()
+
+	config, err := conv.WriteNginxConfiguration()
+	if err != nil {
+		t.Fatalf("WriteNginxConfiguration failed: %v", err)
+	}
+
+	want := "    if ($auth_rejected_catalog_products_productId = 1) {\n        return 401;\n    }"
+	if !strings.Contains(config, want) {
+		t.Errorf("config is missing %q:\n%s", want, config)
+	}
+
+	httpConfig, err := conv.HTTPContext.Render()
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	want = "map $request_method $auth_rejected_catalog_products_productId {\n    default 0;\n    GET 1;\n}"
+	if !strings.Contains(httpConfig, want) {
+		t.Errorf("http context is missing %q:\n%s", want, httpConfig)
+	}
+}
@@ -0,0 +1,151 @@
+package test
+
+import (
+	"strings"
+	"testing"
+	"github.com/nimling/openapi-converter/converter"
+	"github.com/nimling/openapi-converter/internal"
+)
+
+func TestCheckNginxConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		wantErr string
+	}{
+		{
+			name:   "Valid config",
+			config: "location ~ ^/users/[^/]+$ {\n    proxy_pass https://${REGION}_upstream;\n    add_header X-Test \"a;b{c}\";\n}",
+		},
+		{
+			name:    "Unclosed block",
+			config:  "location /users {\n    proxy_pass http://users;\n",
+			wantErr: "is not closed",
+		},
+		{
+			name:    "Unexpected closing brace",
+			config:  "location /users {\n}\n}",
+			wantErr: "unexpected '}'",
+		},
+		{
+			name:    "Missing semicolon",
+			config:  "location /users {\n    proxy_pass http://users\n}",
+			wantErr: "missing a terminating ';'",
+		},
+		{
+			name:    "Duplicate location",
+			config:  "location /users {\n}\nlocation /users {\n}",
+			wantErr: "duplicate location '/users'",
+		},
+		{
+			name:    "Unknown directive",
+			config:  "location /users {\n    proxy_pas http://users;\n}",
+			wantErr: "unknown directive 'proxy_pas'",
+		},
+		{
+			name:    "Invalid location regex",
+			config:  "location ~ ^/users/([^/]+$ {\n}",
+			wantErr: "invalid regex",
+		},
+		{
+			name:    "Invalid map regex",
+			config:  "map $http_origin $cors {\n    default \"\";\n    \"~^https://(foo\" $http_origin;\n}",
+			wantErr: "invalid regex",
+		},
+		{
+			name:    "URI in proxy_pass of a regex location",
+			config:  "location ~ ^/users/[^/]+$ {\n    proxy_pass https://users/v1;\n}",
+			wantErr: "proxy_pass cannot have a URI part in a regex location",
+		},
+		{
+			name:    "URI in proxy_pass of a named location",
+			config:  "location @users {\n    proxy_pass https://users/v1;\n}",
+			wantErr: "proxy_pass cannot have a URI part in a named location",
+		},
+		{
+			name:    "URI in proxy_pass inside if",
+			config:  "location /users {\n    if ($request_method = POST) {\n        proxy_pass https://users/v1;\n    }\n}",
+			wantErr: "proxy_pass cannot have a URI part in an if block",
+		},
+		{
+			name:    "URI in proxy_pass inside limit_except",
+			config:  "location /users {\n    limit_except GET {\n        proxy_pass https://users/v1;\n    }\n}",
+			wantErr: "proxy_pass cannot have a URI part in a limit_except block",
+		},
+		{
+			name:   "URI in proxy_pass of a prefix location",
+			config: "location /users {\n    proxy_pass https://users/v1;\n}\nlocation = /introspect {\n    proxy_pass https://auth.example.com/introspect;\n}",
+		},
+		{
+			name:    "Prefix location captured by a regex sibling",
+			config:  "location /users/me {\n}\nlocation ~ ^/users/[^/]+$ {\n}",
+			wantErr: "prefix location '/users/me' is captured by regex location '^/users/[^/]+$' on line 3",
+		},
+		{
+			name:   "Exact location next to a regex sibling",
+			config: "location = /users/me {\n}\nlocation ~ ^/users/[^/]+$ {\n}",
+		},
+		{
+			name:    "Templated path in bare location",
+			config:  "location /users/{id} {\n}",
+			wantErr: "missing a terminating ';'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := converter.CheckNginxConfig("test.conf", tt.config)
+			
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGeneratedNginxConfigPassesCheck(t *testing.T) {
+	for _, spec := range []string{"../examples/spec.yml", "../examples/gateway.yml", "../examples/regional.yml"} {
+		conv, err := converter.NewOpenApiConverter(spec)
+		if err != nil {
+			t.Fatalf("failed to load %s: %v", spec, err)
+		}
+		conv.HTTPContext = converter.NewHTTPContext()
+		
+		config, err := conv.WriteNginxConfiguration()
+		if err != nil {
+			t.Fatalf("WriteNginxConfiguration failed for %s: %v", spec, err)
+		}
+		
+		upstream, err := conv.WriteNginxUpstream()
+		if err != nil {
+			t.Fatalf("WriteNginxUpstream failed for %s: %v", spec, err)
+		}
+		
+		httpConfig, err := conv.HTTPContext.Render()
+		if err != nil {
+			t.Fatalf("Render failed for %s: %v", spec, err)
+		}
+		
+		for name, content := range map[string]string{"locations": config, "upstream": upstream, "http": httpConfig} {
+			if err := converter.CheckNginxConfig(name, content); err != nil {
+				t.Errorf("%s %s output failed the check: %v", spec, name, err)
+			}
+		}
+	}
+}
+
+func TestNginxCheckCommand(t *testing.T) {
+	cmd := internal.NewNginxCheckCommand()
+	cmd.SetArgs([]string{"../examples/missing.conf.template"})
+	
+	if err := cmd.Execute(); err == nil {
+		t.Errorf("expected an error for a missing file")
+	}
+}
@@ -0,0 +1,312 @@
+package test
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"github.com/nimling/openapi-converter/converter"
+)
+
+func loadGatewayConverter(t *testing.T) *converter.OpenAPIConverter {
+	t.Helper()
+
+	conv, err := converter.NewOpenApiConverter("../examples/gateway.yml")
+	if err != nil {
+		t.Fatalf("failed to load spec: %v", err)
+	}
+
+	return conv
+}
+
+func TestNginxConfigIsNotHTMLEscaped(t *testing.T) {
+	conv := loadGatewayConverter(t)
+
+	config, err := conv.WriteNginxConfiguration()
+	if err != nil {
+		t.Fatalf("WriteNginxConfiguration failed: %v", err)
+	}
+
+	if !strings.Contains(config, "# Summary: GET: List the customer's orders") {
+		t.Errorf("summary was escaped or missing:\n%s", config)
+	}
+
+	if strings.Contains(config, "&#39;") || strings.Contains(config, "&lt;") || strings.Contains(config, "&amp;") {
+		t.Errorf("config contains HTML entities:\n%s", config)
+	}
+
+	for _, line := range strings.Split(config, "\n") {
+		if strings.Contains(line, "location /evil") && !strings.HasPrefix(line, "#") {
+			t.Errorf("multiline description escaped its comment: %q", line)
+		}
+	}
+}
+
+func TestNginxUpstreamFromExtension(t *testing.T) {
+	conv := loadGatewayConverter(t)
+
+	upstream, err := conv.WriteNginxUpstream()
+	if err != nil {
+		t.Fatalf("WriteNginxUpstream failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"upstream orders_backend {",
+		"least_conn;",
+		"server orders-1.example.com:443 weight=3 max_fails=2 fail_timeout=10s;",
+		"server orders-2.example.com:443 backup;",
+		"keepalive 16;",
+	} {
+		if !strings.Contains(upstream, want) {
+			t.Errorf("upstream is missing %q:\n%s", want, upstream)
+		}
+	}
+
+	config, err := conv.WriteNginxConfiguration()
+	if err != nil {
+		t.Fatalf("WriteNginxConfiguration failed: %v", err)
+	}
+
+	if !strings.Contains(config, "rewrite ^/(.*) /api/$1 break;\n    proxy_pass https://orders_backend;") {
+		t.Errorf("location does not proxy to the upstream:\n%s", config)
+	}
+}
+
+func TestNginxTemplatedPathLocation(t *testing.T) {
+	conv, err := converter.NewOpenApiConverter("../examples/spec.yml")
+	if err != nil {
+		t.Fatalf("failed to load spec: %v", err)
+	}
+
+	config, err := conv.WriteNginxConfiguration()
+	if err != nil {
+		t.Fatalf("WriteNginxConfiguration failed: %v", err)
+	}
+
+	// Regex locations do not allow a URI in proxy_pass, the base path comes from the rewrite
+	want := "location ~ ^/users/[^/]+$ {"
+	if !strings.Contains(config, want) {
+		t.Errorf("config is missing %q:\n%s", want, config)
+	}
+	if strings.Contains(config, "proxy_pass https://spec_upstream/v1;") {
+		t.Errorf("proxy_pass still carries the base path:\n%s", config)
+	}
+	if !strings.Contains(config, "rewrite ^/(.*) /v1/$1 break;\n    proxy_pass https://spec_upstream;") {
+		t.Errorf("base path is not added by a rewrite:\n%s", config)
+	}
+}
+
+func TestNginxSettingsFromExtension(t *testing.T) {
+	conv := loadGatewayConverter(t)
+
+	config, err := conv.WriteNginxConfiguration()
+	if err != nil {
+		t.Fatalf("WriteNginxConfiguration failed: %v", err)
+	}
+
+	locations := strings.Split(config, "\n\n# Summary")
+	if len(locations) != 2 {
+		t.Fatalf("expected 2 locations, got %d:\n%s", len(locations), config)
+	}
+
+	orders, reports := locations[0], locations[1]
+
+	for _, want := range []string{
+		"proxy_connect_timeout 5s;",
+		"proxy_read_timeout 30s;",
+		"client_max_body_size 50m;",
+		"proxy_buffering off;",
+		"add_header Pragma no-cache;",
+	} {
+		if !strings.Contains(orders, want) {
+			t.Errorf("orders location is missing %q:\n%s", want, orders)
+		}
+	}
+
+	for _, want := range []string{
+		"proxy_connect_timeout 5s;",
+		"proxy_read_timeout 300s;",
+		`add_header Cache-Control "public, max-age=60";`,
+		`add_header X-Report-Engine "v2";`,
+		"proxy_request_buffering off;",
+	} {
+		if !strings.Contains(reports, want) {
+			t.Errorf("reports location is missing %q:\n%s", want, reports)
+		}
+	}
+
+	if strings.Contains(reports, "client_max_body_size") || strings.Contains(reports, "Pragma") {
+		t.Errorf("reports location inherited settings it should not have:\n%s", reports)
+	}
+}
+
+func TestNginxOperationSettingsOnSharedPath(t *testing.T) {
+	data, err := os.ReadFile("../examples/gateway.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// listOrders shares the /orders location with uploadOrders
+	spec := strings.Replace(string(data), "      operationId: listOrders\n", "      operationId: listOrders\n      x-nginx:\n        readTimeout: 5s\n", 1)
+	if err := os.MkdirAll("../../tmp/nginx-operation", 0755); err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll("../../tmp/nginx-operation")
+	if err := os.WriteFile("../../tmp/nginx-operation/gateway.yml", []byte(spec), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	conv, err := converter.NewOpenApiConverter("../../tmp/nginx-operation/gateway.yml")
+	if err != nil {
+		t.Fatalf("failed to load spec: %v", err)
+	}
+
+	if _, err := conv.WriteNginxConfiguration(); err == nil || !strings.Contains(err.Error(), "x-nginx would apply to every method of the path") {
+		t.Errorf("expected operation x-nginx on a shared path to be rejected, got %v", err)
+	}
+}
+
+func TestNginxRateLimitZones(t *testing.T) {
+	conv := loadGatewayConverter(t)
+	conv.HTTPContext = converter.NewHTTPContext()
+
+	config, err := conv.WriteNginxConfiguration()
+	if err != nil {
+		t.Fatalf("WriteNginxConfiguration failed: %v", err)
+	}
+
+	// GET keeps the document limit, the POST-only limit of uploadOrders is added next to it
+	orders := strings.Split(config, "\n\n# Summary")[0]
+	want := "limit_req zone=gateway burst=40 nodelay;\n    limit_req zone=uploadOrders;\n    limit_req_status 429;"
+	if !strings.Contains(orders, want) {
+		t.Errorf("orders location is missing %q:\n%s", want, orders)
+	}
+
+	reports := config[strings.Index(config, "location = /reports"):]
+	if !strings.Contains(reports, "limit_req zone=gateway burst=40 nodelay;") || strings.Contains(reports, "uploadOrders") {
+		t.Errorf("reports location does not use the document limit only:\n%s", reports)
+	}
+
+	httpConfig, err := conv.HTTPContext.Render()
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"limit_req_zone $binary_remote_addr zone=gateway:10m rate=20r/s;",
+		"limit_req_zone $rate_limit_key_uploadOrders zone=uploadOrders:10m rate=1r/s;",
+		// Requests with an empty key are not counted, so uploadOrders only counts POST
+		"map $request_method $rate_limit_key_uploadOrders {\n    default \"\";\n    POST $rate_limit_subject_uploadOrders;\n}",
+		// uploadOrders is secured by an API key, requests without one are counted by address
+		"map $http_x_api_key $rate_limit_subject_uploadOrders {\n    default $http_x_api_key;\n    \"\" $binary_remote_addr;\n}",
+	} {
+		if !strings.Contains(httpConfig, want) {
+			t.Errorf("http context is missing %q:\n%s", want, httpConfig)
+		}
+	}
+
+	regional, err := converter.NewOpenApiConverter("../examples/regional.yml")
+	if err != nil {
+		t.Fatalf("failed to load spec: %v", err)
+	}
+	regional.HTTPContext = conv.HTTPContext
+
+	if _, err := regional.WriteNginxConfiguration(); err == nil || !strings.Contains(err.Error(), "collides") {
+		t.Errorf("expected zone collision across specs, got %v", err)
+	}
+}
+
+func TestNginxRateLimitZoneOwnedBySpec(t *testing.T) {
+	data, err := os.ReadFile("../examples/gateway.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// An identical copy defines the same zones with the same key, size and rate
+	if err := os.MkdirAll("../../tmp/nginx-zones", 0755); err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll("../../tmp/nginx-zones")
+	if err := os.WriteFile("../../tmp/nginx-zones/orders.yml", data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	httpContext := converter.NewHTTPContext()
+	for i, spec := range []string{"../examples/gateway.yml", "../../tmp/nginx-zones/orders.yml"} {
+		conv, err := converter.NewOpenApiConverter(spec)
+		if err != nil {
+			t.Fatalf("failed to load %s: %v", spec, err)
+		}
+		conv.HTTPContext = httpContext
+
+		_, err = conv.WriteNginxConfiguration()
+		if i == 0 && err != nil {
+			t.Fatalf("WriteNginxConfiguration failed: %v", err)
+		}
+		if i == 1 && (err == nil || !strings.Contains(err.Error(), "rate limit zone 'gateway'") || !strings.Contains(err.Error(), "collides")) {
+			t.Errorf("expected the zone of another spec to be rejected, got %v", err)
+		}
+	}
+}
+
+func TestNginxCORSFromExtension(t *testing.T) {
+	conv := loadGatewayConverter(t)
+	conv.HTTPContext = converter.NewHTTPContext()
+
+	config, err := conv.WriteNginxConfiguration()
+	if err != nil {
+		t.Fatalf("WriteNginxConfiguration failed: %v", err)
+	}
+
+	orders := strings.Split(config, "\n\n# Summary")[0]
+	for _, want := range []string{
+		"limit_except GET POST OPTIONS {",
+		"if ($request_method = OPTIONS) {",
+		`add_header Access-Control-Allow-Methods "GET, POST, OPTIONS" always;`,
+		`add_header Access-Control-Allow-Headers "Authorization, Content-Type, X-Request-Id" always;`,
+		"add_header Access-Control-Max-Age 600 always;",
+		"return 204;",
+		"add_header Access-Control-Allow-Origin $cors_origin_gateway always;",
+		`add_header Access-Control-Expose-Headers "X-Request-Id" always;`,
+	} {
+		if !strings.Contains(orders, want) {
+			t.Errorf("orders location is missing %q:\n%s", want, orders)
+		}
+	}
+
+	httpConfig, err := conv.HTTPContext.Render()
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"map $http_origin $cors_origin_gateway {",
+		`"https://shop.example.com" $http_origin;`,
+		`"~^https://[a-z]+\\.preview\\.example\\.com$" $http_origin;`,
+		"map $http_origin $cors_origin_gateway_reports {\n    default \"*\";",
+	} {
+		if !strings.Contains(httpConfig, want) {
+			t.Errorf("http context is missing %q:\n%s", want, httpConfig)
+		}
+	}
+}
+
+func TestNginxLiteralPathBeatsTemplatedSibling(t *testing.T) {
+	conv, err := converter.NewOpenApiConverter("../examples/profiles.yml")
+	if err != nil {
+		t.Fatalf("failed to load spec: %v", err)
+	}
+
+	config, err := conv.WriteNginxConfiguration()
+	if err != nil {
+		t.Fatalf("WriteNginxConfiguration failed: %v", err)
+	}
+
+	// Nginx prefers regex locations over prefix locations, only an exact location keeps /users/me
+	for _, want := range []string{"location = /users/me {", "location ~ ^/users/[^/]+$ {"} {
+		if !strings.Contains(config, want) {
+			t.Errorf("config is missing %q:\n%s", want, config)
+		}
+	}
+
+	if err := converter.CheckNginxConfig("profiles.conf", config); err != nil {
+		t.Errorf("generated config does not pass the check: %v", err)
+	}
+}
@@ -0,0 +1,193 @@
+package test
+
+import (
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+	"github.com/nimling/openapi-converter/converter"
+)
+
+func TestNjsValidationModule(t *testing.T) {
+	conv, err := converter.NewOpenApiConverter("../examples/spec.yml")
+	if err != nil {
+		t.Fatalf("failed to load spec: %v", err)
+	}
+
+	module, err := conv.WriteNjsValidation()
+	if err != nil {
+		t.Fatalf("WriteNjsValidation failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"// Generated from ../examples/spec.yml",
+		// Component schemas are referenced by name
+		`"User": {`,
+		`"$ref": "User"`,
+		`"contentTypes": [`,
+		`"pattern": "^/users/([^/]+)$"`,
+		"spec_users: function (r) {\n        validate(r, 'spec_users');",
+		"spec_users_id: function (r) {",
+		"r.internalRedirect('@' + name);",
+		"problem(r, 415, 'Unsupported Media Type'",
+	} {
+		if !strings.Contains(module, want) {
+			t.Errorf("module is missing %q:\n%s", want, module)
+		}
+	}
+}
+
+func TestNjsValidationWiring(t *testing.T) {
+	conv, err := converter.NewOpenApiConverter("../examples/spec.yml")
+	if err != nil {
+		t.Fatalf("failed to load spec: %v", err)
+	}
+	conv.HTTPContext = converter.NewHTTPContext()
+
+	config, err := conv.WriteNginxConfiguration()
+	if err != nil {
+		t.Fatalf("WriteNginxConfiguration failed: %v", err)
+	}
+	if strings.Contains(config, "js_content") {
+		t.Errorf("locations are wired to njs without NjsImportPath:\n%s", config)
+	}
+
+	conv.NjsImportPath = "/etc/nginx/njs/"
+	config, err = conv.WriteNginxConfiguration()
+	if err != nil {
+		t.Fatalf("WriteNginxConfiguration failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"    js_content spec_validation.spec_users;\n}\n\nlocation @spec_users {",
+		"js_content spec_validation.spec_users_id;",
+		// proxy_pass cannot have a URI part in a named location
+		"rewrite ^/(.*) /v1/$1 break;\n    proxy_pass https://spec_upstream;",
+	} {
+		if !strings.Contains(config, want) {
+			t.Errorf("config is missing %q:\n%s", want, config)
+		}
+	}
+
+	httpContext, err := conv.HTTPContext.Render()
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if !strings.Contains(httpContext, "js_import spec_validation from /etc/nginx/njs/spec.validate.js;") {
+		t.Errorf("http context is missing the module import:\n%s", httpContext)
+	}
+
+	if err := converter.CheckNginxConfig("spec.conf", config); err != nil {
+		t.Errorf("generated config fails the check: %v", err)
+	}
+	if err := converter.CheckNginxConfig("http.conf", httpContext); err != nil {
+		t.Errorf("generated http context fails the check: %v", err)
+	}
+}
+
+func TestNjsNamedLocationReachesBasePath(t *testing.T) {
+	tests := []struct {
+		name   string
+		prefix string
+		want   map[string]string
+	}{
+		{
+			name: "Without prefix",
+			want: map[string]string{"/users": "/v1/users", "/users/1": "/v1/users/1"},
+		},
+		{
+			name:   "Stripped prefix",
+			prefix: "/users",
+			want:   map[string]string{"/users": "/v1/", "/users/1": "/v1/1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conv, err := converter.NewOpenApiConverter("../examples/spec.yml")
+			if err != nil {
+				t.Fatalf("failed to load spec: %v", err)
+			}
+			conv.CommonPrefix = tt.prefix
+			conv.HTTPContext = converter.NewHTTPContext()
+			conv.NjsImportPath = "/etc/nginx/njs/"
+
+			config, err := conv.WriteNginxConfiguration()
+			if err != nil {
+				t.Fatalf("WriteNginxConfiguration failed: %v", err)
+			}
+
+			start := strings.Index(config, "location @spec_users {")
+			if start < 0 {
+				t.Fatalf("config has no named location:\n%s", config)
+			}
+			location := config[start:]
+			location = location[:strings.Index(location, "\n}")]
+
+			for uri, want := range tt.want {
+				if got := applyNginxRewrites(t, location, uri); got != want {
+					t.Errorf("%s reaches the upstream as %q, want %q:\n%s", uri, got, want, location)
+				}
+			}
+		})
+	}
+}
+
+func TestNjsValidatingLocationReadsLargeBodies(t *testing.T) {
+	data, err := os.ReadFile("../examples/spec.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec := strings.Replace(string(data), "  /users:\n", "  /users:\n    x-nginx:\n      clientMaxBodySize: 20m\n      directives:\n        - client_body_buffer_size 1m\n", 1)
+	if err := os.MkdirAll("../../tmp/njs-body", 0755); err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll("../../tmp/njs-body")
+	if err := os.WriteFile("../../tmp/njs-body/spec.yml", []byte(spec), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	conv, err := converter.NewOpenApiConverter("../../tmp/njs-body/spec.yml")
+	if err != nil {
+		t.Fatalf("failed to load spec: %v", err)
+	}
+	conv.HTTPContext = converter.NewHTTPContext()
+	conv.NjsImportPath = "/etc/nginx/njs/"
+
+	config, err := conv.WriteNginxConfiguration()
+	if err != nil {
+		t.Fatalf("WriteNginxConfiguration failed: %v", err)
+	}
+
+	// js_content reads the body in the outer location, before the redirect to the named one
+	start := strings.Index(config, "location = /users {")
+	if start < 0 {
+		t.Fatalf("config has no /users location:\n%s", config)
+	}
+	validating := config[start:]
+	validating = validating[:strings.Index(validating, "\n}")]
+
+	want := "    client_max_body_size 20m;\n    client_body_buffer_size 1m;\n    js_content spec_validation.spec_users;"
+	if !strings.Contains(validating, want) {
+		t.Errorf("validating location is missing %q:\n%s", want, validating)
+	}
+}
+
+var nginxRewriteLine = regexp.MustCompile(`(?m)^\s*rewrite (\S+) (\S+) break;$`)
+
+// applyNginxRewrites applies the first matching rewrite ... break rule of a location like Nginx does
+func applyNginxRewrites(t *testing.T, location string, uri string) string {
+	t.Helper()
+
+	for _, match := range nginxRewriteLine.FindAllStringSubmatch(location, -1) {
+		re, err := regexp.Compile(match[1])
+		if err != nil {
+			t.Fatalf("invalid rewrite regex %q: %v", match[1], err)
+		}
+		if re.MatchString(uri) {
+			return re.ReplaceAllString(uri, match[2])
+		}
+	}
+
+	return uri
+}
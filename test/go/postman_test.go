@@ -0,0 +1,133 @@
+package test
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"github.com/nimling/openapi-converter/converter"
+	"github.com/nimling/openapi-converter/internal"
+	"github.com/nimling/openapi-converter/postman"
+)
+
+func loadPostmanCollection(t *testing.T, specPath string) *postman.Collection {
+	t.Helper()
+
+	conv, err := converter.NewOpenApiConverter(specPath)
+	if err != nil {
+		t.Fatalf("failed to load spec: %v", err)
+	}
+
+	output, err := conv.WritePostmanCollection()
+	if err != nil {
+		t.Fatalf("WritePostmanCollection failed: %v", err)
+	}
+
+	collection := &postman.Collection{}
+	if err := json.Unmarshal([]byte(output), collection); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, output)
+	}
+
+	return collection
+}
+
+func findPostmanItem(items []*postman.Item, name string) *postman.Item {
+	for _, item := range items {
+		if item.Name == name {
+			return item
+		}
+		if found := findPostmanItem(item.Item, name); found != nil {
+			return found
+		}
+	}
+
+	return nil
+}
+
+func TestPostmanCollection(t *testing.T) {
+	collection := loadPostmanCollection(t, "../examples/catalog.yml")
+
+	if collection.Info.Name != "Catalog API" || collection.Info.Schema != postman.SchemaURL {
+		t.Errorf("unexpected info %+v", collection.Info)
+	}
+
+	// Folders per tag, in the order the tags first appear
+	if len(collection.Item) != 2 || collection.Item[0].Name != "orders" || collection.Item[1].Name != "products" {
+		t.Fatalf("expected the folders orders and products, got %+v", collection.Item)
+	}
+	if len(collection.Item[1].Item) != 4 {
+		t.Errorf("products folder has %d requests, want 4", len(collection.Item[1].Item))
+	}
+
+	variables := map[string]string{}
+	for _, variable := range collection.Variable {
+		variables[variable.Key] = variable.Value
+	}
+	if variables["baseUrl"] != "https://catalog.example.com/api" {
+		t.Errorf("baseUrl = %q", variables["baseUrl"])
+	}
+	if _, ok := variables["bearerAuth"]; !ok {
+		t.Errorf("credentials variable bearerAuth is missing: %v", variables)
+	}
+
+	get := findPostmanItem(collection.Item, "Get product")
+	if get == nil {
+		t.Fatal("request Get product is missing")
+	}
+	if get.Request.URL.Raw != "{{baseUrl}}/products/:productId" || len(get.Request.URL.Variable) != 1 || get.Request.URL.Variable[0].Key != "productId" {
+		t.Errorf("unexpected URL %+v", get.Request.URL)
+	}
+	if get.Request.Auth == nil || get.Request.Auth.Type != "bearer" || get.Request.Auth.Bearer[0].Value != "{{bearerAuth}}" {
+		t.Errorf("unexpected auth %+v", get.Request.Auth)
+	}
+
+	// Optional query parameters without an example are listed but not sent
+	list := findPostmanItem(collection.Item, "List products")
+	if list == nil || len(list.Request.URL.Query) != 2 || !list.Request.URL.Query[0].Disabled || list.Request.URL.Raw != "{{baseUrl}}/products" {
+		t.Errorf("unexpected query parameters %+v", list.Request.URL)
+	}
+
+	order := findPostmanItem(collection.Item, "Place order")
+	if order == nil || order.Request.Body == nil || order.Request.Body.Mode != "raw" || order.Request.Body.Options.Raw.Language != "json" {
+		t.Fatalf("Place order has no JSON body: %+v", order)
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal([]byte(order.Request.Body.Raw), &body); err != nil || body["items"] == nil {
+		t.Errorf("body is not an example order: %s", order.Request.Body.Raw)
+	}
+	if order.Request.Auth == nil || order.Request.Auth.Type != "apikey" || order.Request.Auth.APIKey[0].Value != "X-API-Key" {
+		t.Errorf("unexpected auth %+v", order.Request.Auth)
+	}
+}
+
+func TestPostmanResponseExamples(t *testing.T) {
+	collection := loadPostmanCollection(t, "../examples/spec.yml")
+
+	// Untagged operations are listed at the top level
+	get := findPostmanItem(collection.Item, "Get user by ID")
+	if get == nil || len(collection.Item) != 3 {
+		t.Fatalf("expected three top level requests, got %+v", collection.Item)
+	}
+	if len(get.Response) != 1 || get.Response[0].Code != 200 || get.Response[0].Status != "OK" {
+		t.Fatalf("unexpected saved responses %+v", get.Response)
+	}
+
+	var body map[string]interface{}
+	if err := json.Unmarshal([]byte(get.Response[0].Body), &body); err != nil || body["name"] != "Alice" {
+		t.Errorf("saved response does not hold the example: %s", get.Response[0].Body)
+	}
+}
+
+func TestConvertPostman(t *testing.T) {
+	defer os.RemoveAll("../../tmp/postman")
+
+	err := internal.RunConvertWithOptions([]string{"../examples/catalog.yml"}, internal.ConvertOptions{
+		PostmanPath: "../../tmp/postman",
+	})
+	if err != nil {
+		t.Fatalf("RunConvert failed: %v", err)
+	}
+
+	if _, err := os.Stat("../../tmp/postman/catalog.postman_collection.json"); err != nil {
+		t.Errorf("collection was not written: %v", err)
+	}
+}
@@ -0,0 +1,184 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"github.com/nimling/openapi-converter/converter"
+	"github.com/nimling/openapi-converter/internal"
+)
+
+func TestRouteTable(t *testing.T) {
+	conv, err := converter.NewOpenApiConverter("../examples/gateway.yml")
+	if err != nil {
+		t.Fatalf("failed to load spec: %v", err)
+	}
+	conv.CommonPrefix = "/orders"
+
+	table, err := conv.RouteTable()
+	if err != nil {
+		t.Fatalf("RouteTable failed: %v", err)
+	}
+
+	if table.Spec != "gateway" || table.Prefix != "/orders" {
+		t.Errorf("spec = %q, prefix = %q", table.Spec, table.Prefix)
+	}
+
+	if table.Upstream.Name != "orders_backend" || table.Upstream.BasePath != "/api" || len(table.Upstream.Servers) != 2 {
+		t.Errorf("unexpected upstream %+v", table.Upstream)
+	}
+	if backup := table.Upstream.Servers[1]; !backup.Backup || backup.PortOrDefault() != "443" {
+		t.Errorf("unexpected backup server %+v", backup)
+	}
+
+	if len(table.Routes) != 2 {
+		t.Fatalf("got %d routes, want 2", len(table.Routes))
+	}
+
+	orders := table.Routes[0]
+	if orders.Name != "gateway_orders" || orders.Match.Templated || orders.Match.Regex != "^/orders$" {
+		t.Errorf("unexpected orders route %+v", orders)
+	}
+	if !reflect.DeepEqual(orders.Methods, []string{"GET", "POST"}) {
+		t.Errorf("methods = %v", orders.Methods)
+	}
+
+	list, upload := orders.Operations[0], orders.Operations[1]
+	if !reflect.DeepEqual(list.Security, []map[string][]string{{"bearerAuth": {"orders:read"}}}) {
+		t.Errorf("listOrders should inherit the document security, got %v", list.Security)
+	}
+	if !reflect.DeepEqual(upload.Security, []map[string][]string{{"apiKey": {}}}) {
+		t.Errorf("uploadOrders security = %v", upload.Security)
+	}
+	if upload.RateLimit == nil || upload.RateLimit.Rate != "1r/s" {
+		t.Errorf("uploadOrders rate limit = %+v", upload.RateLimit)
+	}
+
+	if orders.CORSScope != converter.ScopeDocument || orders.RateLimitScope != converter.ScopeDocument {
+		t.Errorf("orders scopes = %q, %q", orders.CORSScope, orders.RateLimitScope)
+	}
+	if orders.Settings.ClientMaxBodySize != "50m" {
+		t.Errorf("operation x-nginx should raise the route body size, got %q", orders.Settings.ClientMaxBodySize)
+	}
+
+	reports := table.Routes[1]
+	if reports.CORSScope != converter.ScopePath || reports.Settings.ReadTimeout != "300s" {
+		t.Errorf("unexpected reports route %+v", reports)
+	}
+	cors := reports.EffectiveCORS()
+	if !reflect.DeepEqual(cors.Methods, []string{"GET", "OPTIONS"}) || len(cors.Headers) == 0 {
+		t.Errorf("effective CORS = %+v", cors)
+	}
+
+	scheme, err := table.SecurityScheme("apiKey")
+	if err != nil || scheme.Name != "X-API-Key" {
+		t.Errorf("SecurityScheme(apiKey) = %+v, %v", scheme, err)
+	}
+	if _, err := table.SecurityScheme("oauth"); err == nil {
+		t.Error("expected an error for an undefined scheme")
+	}
+}
+
+func TestRouteTableTemplatedPaths(t *testing.T) {
+	conv, err := converter.NewOpenApiConverter("../examples/spec.yml")
+	if err != nil {
+		t.Fatalf("failed to load spec: %v", err)
+	}
+
+	table, err := conv.RouteTable()
+	if err != nil {
+		t.Fatalf("RouteTable failed: %v", err)
+	}
+
+	ordered := table.OrderedRoutes()
+	seenTemplated := false
+	for _, route := range ordered {
+		if !route.Match.Templated {
+			if seenTemplated {
+				t.Errorf("literal route %s ordered after a templated one", route.Path)
+			}
+			continue
+		}
+
+		seenTemplated = true
+		if len(route.Match.Params) == 0 {
+			t.Errorf("templated route %s lists no parameters", route.Path)
+		}
+		for _, name := range route.Match.Params {
+			found := false
+			for _, param := range route.Params {
+				found = found || (param.Name == name && param.In == "path" && param.Required)
+			}
+			if !found {
+				t.Errorf("route %s is missing required path parameter %s", route.Path, name)
+			}
+		}
+	}
+}
+
+func TestRoutesCommand(t *testing.T) {
+	var out bytes.Buffer
+	if err := internal.RunRoutes("../examples/gateway.yml", internal.RoutesOptions{}, &out); err != nil {
+		t.Fatalf("RunRoutes failed: %v", err)
+	}
+
+	table := &converter.RouteTable{}
+	if err := json.Unmarshal(out.Bytes(), table); err != nil {
+		t.Fatalf("output is not a JSON route table: %v\n%s", err, out.String())
+	}
+
+	if len(table.Routes) != 2 || table.Routes[1].Path != "/reports" {
+		t.Errorf("unexpected routes %+v", table.Routes)
+	}
+	if !bytes.Contains(out.Bytes(), []byte(`"description": "Returns orders where total > 100 & status is <open>.`)) {
+		t.Error("descriptions should not be HTML escaped")
+	}
+}
+
+func TestMatchRequestLiteralBeforeTemplated(t *testing.T) {
+	conv, err := converter.NewOpenApiConverter("../examples/profiles.yml")
+	if err != nil {
+		t.Fatalf("failed to load spec: %v", err)
+	}
+
+	table, err := conv.RouteTable()
+	if err != nil {
+		t.Fatalf("RouteTable failed: %v", err)
+	}
+
+	tests := []struct {
+		method    string
+		path      string
+		route     string
+		operation string
+	}{
+		{"GET", "/users/me", "/users/me", "getCurrentProfile"},
+		{"DELETE", "/users/42", "/users/{id}", "deleteProfile"},
+		// Literal paths match exactly, so a longer segment is a value of the templated sibling
+		{"GET", "/users/meXYZ", "/users/{id}", ""},
+		{"GET", "/users/me/extra", "", ""},
+	}
+
+	for _, tt := range tests {
+		match := table.MatchRequest(tt.method, tt.path)
+		if tt.route == "" {
+			if match != nil {
+				t.Errorf("%s %s matched %s, want no route", tt.method, tt.path, match.Route.Path)
+			}
+			continue
+		}
+
+		if match == nil || match.Route.Path != tt.route {
+			t.Errorf("%s %s matched %+v, want %s", tt.method, tt.path, match, tt.route)
+			continue
+		}
+		operation := ""
+		if match.Operation != nil {
+			operation = match.Operation.OperationID
+		}
+		if operation != tt.operation {
+			t.Errorf("%s %s operation = %q, want %q", tt.method, tt.path, operation, tt.operation)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package test
+
+import (
+	"strings"
+	"testing"
+	"github.com/nimling/openapi-converter/converter"
+)
+
+func TestServerVariables(t *testing.T) {
+	tests := []struct {
+		name         string
+		values       map[string]string
+		placeholders bool
+		wantServer   string
+		wantProxy    string
+		wantErr      bool
+	}{
+		{
+			name:       "Defaults are substituted",
+			wantServer: "server eu.api.example.com:443;",
+			wantProxy:  "rewrite ^/(.*) /v2/$1 break;\n    proxy_pass https://regional_upstream;",
+		},
+		{
+			name:       "Overrides replace defaults",
+			values:     map[string]string{"region": "us", "unknown": "ignored"},
+			wantServer: "server us.api.example.com:443;",
+			wantProxy:  "rewrite ^/(.*) /v2/$1 break;\n    proxy_pass https://regional_upstream;",
+		},
+		{
+			name:         "Placeholders for envsubst",
+			placeholders: true,
+			wantServer:   "server ${REGION}.api.example.com:443;",
+			wantProxy:    "rewrite ^/(.*) /${BASE_PATH}/$1 break;\n    proxy_pass https://regional_upstream;",
+		},
+		{
+			name:    "Override outside enum",
+			values:  map[string]string{"region": "asia"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conv, err := converter.NewOpenApiConverter("../examples/regional.yml")
+			if err != nil {
+				t.Fatalf("failed to load spec: %v", err)
+			}
+			conv.ServerVariablePlaceholders = tt.placeholders
+
+			err = conv.SetServerVariables(tt.values)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SetServerVariables error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			upstream, err := conv.WriteNginxUpstream()
+			if err != nil {
+				t.Fatalf("WriteNginxUpstream failed: %v", err)
+			}
+			if !strings.Contains(upstream, tt.wantServer) {
+				t.Errorf("upstream is missing %q:\n%s", tt.wantServer, upstream)
+			}
+
+			config, err := conv.WriteNginxConfiguration()
+			if err != nil {
+				t.Fatalf("WriteNginxConfiguration failed: %v", err)
+			}
+			if !strings.Contains(config, tt.wantProxy) {
+				t.Errorf("config is missing %q:\n%s", tt.wantProxy, config)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package test
+
+import (
+	"testing"
+	"github.com/nimling/openapi-converter/converter"
+	"github.com/nimling/openapi-converter/traefik"
+	"gopkg.in/yaml.v3"
+)
+
+func loadTraefikConfig(t *testing.T, specPath string, commonPrefix string) *traefik.Config {
+	t.Helper()
+
+	conv, err := converter.NewOpenApiConverter(specPath)
+	if err != nil {
+		t.Fatalf("failed to load spec: %v", err)
+	}
+	conv.CommonPrefix = commonPrefix
+
+	output, err := conv.WriteTraefikConfiguration()
+	if err != nil {
+		t.Fatalf("WriteTraefikConfiguration failed: %v", err)
+	}
+
+	config := &traefik.Config{}
+	if err := yaml.Unmarshal([]byte(output), config); err != nil {
+		t.Fatalf("output is not valid YAML: %v\n%s", err, output)
+	}
+
+	return config
+}
+
+func TestTraefikConfiguration(t *testing.T) {
+	config := loadTraefikConfig(t, "../examples/gateway.yml", "/gateway")
+
+	orders, ok := config.HTTP.Routers["gateway_orders"]
+	if !ok {
+		t.Fatalf("router gateway_orders is missing: %+v", config.HTTP.Routers)
+	}
+	wantRule := "PathRegexp(`^/orders$`) && (Method(`GET`) || Method(`POST`) || Method(`OPTIONS`))"
+	if orders.Rule != wantRule {
+		t.Errorf("rule = %q, want %q", orders.Rule, wantRule)
+	}
+	if orders.Service != "orders_backend" {
+		t.Errorf("service = %q, want orders_backend", orders.Service)
+	}
+
+	service := config.HTTP.Services["orders_backend"]
+	if service == nil || len(service.LoadBalancer.Servers) != 1 {
+		t.Fatalf("expected one non-backup server, got %+v", service)
+	}
+	if server := service.LoadBalancer.Servers[0]; server.URL != "https://orders-1.example.com" || server.Weight != 3 {
+		t.Errorf("unexpected server %+v", server)
+	}
+
+	strip := config.HTTP.Middlewares["gateway_strip_prefix"]
+	if strip == nil || strip.StripPrefix == nil || strip.StripPrefix.Prefixes[0] != "/gateway" {
+		t.Errorf("strip prefix middleware is wrong: %+v", strip)
+	}
+	base := config.HTTP.Middlewares["gateway_base_path"]
+	if base == nil || base.AddPrefix == nil || base.AddPrefix.Prefix != "/api" {
+		t.Errorf("base path middleware is wrong: %+v", base)
+	}
+
+	headers := config.HTTP.Middlewares["gateway_reports_headers"]
+	if headers == nil || headers.Headers == nil {
+		t.Fatalf("reports headers middleware is missing")
+	}
+	if got := headers.Headers.CustomResponseHeaders["X-Report-Engine"]; got != "v2" {
+		t.Errorf("x-nginx header = %q, want v2", got)
+	}
+	if got := headers.Headers.CustomResponseHeaders["Cache-Control"]; got != "public, max-age=60" {
+		t.Errorf("Cache-Control = %q", got)
+	}
+	if len(headers.Headers.AccessControlAllowOriginList) != 1 || headers.Headers.AccessControlAllowOriginList[0] != "*" {
+		t.Errorf("path-level CORS origins = %v", headers.Headers.AccessControlAllowOriginList)
+	}
+
+	orderHeaders := config.HTTP.Middlewares["gateway_orders_headers"].Headers
+	if len(orderHeaders.AccessControlAllowOriginListRegex) != 1 || !orderHeaders.AccessControlAllowCredentials {
+		t.Errorf("document-level CORS not applied: %+v", orderHeaders)
+	}
+}
+
+func TestTraefikTemplatedPaths(t *testing.T) {
+	config := loadTraefikConfig(t, "../examples/spec.yml", "")
+
+	router, ok := config.HTTP.Routers["spec_users_id"]
+	if !ok {
+		t.Fatalf("router spec_users_id is missing: %+v", config.HTTP.Routers)
+	}
+
+	wantRule := "PathRegexp(`^/users/[^/]+$`) && (Method(`GET`))"
+	if router.Rule != wantRule {
+		t.Errorf("rule = %q, want %q", router.Rule, wantRule)
+	}
+
+	if _, ok := config.HTTP.Middlewares["spec_strip_prefix"]; ok {
+		t.Errorf("a prefix without a leading slash is not stripped by Nginx and should not be by Traefik")
+	}
+}
@@ -0,0 +1,61 @@
+package test
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"github.com/nimling/openapi-converter/converter"
+	"github.com/nimling/openapi-converter/internal"
+)
+
+func TestTypeScriptDeclarations(t *testing.T) {
+	conv, err := converter.NewOpenApiConverter("../examples/catalog.yml")
+	if err != nil {
+		t.Fatalf("failed to load spec: %v", err)
+	}
+
+	types, err := conv.WriteTypeScript()
+	if err != nil {
+		t.Fatalf("WriteTypeScript failed: %v", err)
+	}
+
+	for _, want := range []string{
+		// Enums are literal unions, allOf an intersection and oneOf a union
+		`export type Category = "books" | "games" | "music";`,
+		"export type Product = NewProduct & {\n  createdAt: string;\n  id: string;\n};",
+		"  payment: CardPayment | InvoicePayment;",
+		// Properties that are not required are optional
+		"export interface NewProduct {\n  category?: Category;\n  name: string;",
+		"  related?: NewProduct[];",
+		// The operations map
+		"  getProduct: {\n    path: \"/products/{productId}\";\n    method: \"GET\";\n    params: {\n      path: {\n        productId: string;\n      };\n    };\n    requestBody: never;",
+		"      query?: {\n        limit?: number;\n        category?: Category;\n      };",
+		"    requestBody: NewProduct;",
+		"      404: Problem;",
+		"      204: undefined;",
+		"export type OperationId = keyof Operations;",
+	} {
+		if !strings.Contains(types, want) {
+			t.Errorf("declarations are missing %q:\n%s", want, types)
+		}
+	}
+}
+
+func TestConvertTypeScript(t *testing.T) {
+	defer os.RemoveAll("../../tmp/typescript")
+
+	err := internal.RunConvertWithOptions([]string{"../examples/spec.yml"}, internal.ConvertOptions{
+		TypeScriptPath: "../../tmp/typescript",
+	})
+	if err != nil {
+		t.Fatalf("RunConvert failed: %v", err)
+	}
+
+	types, err := os.ReadFile("../../tmp/typescript/spec.d.ts")
+	if err != nil {
+		t.Fatalf("declarations were not written: %v", err)
+	}
+	if !strings.Contains(string(types), "export interface Operations {") {
+		t.Errorf("declarations have no operations map:\n%s", types)
+	}
+}
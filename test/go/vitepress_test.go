@@ -0,0 +1,45 @@
+package test
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"github.com/nimling/openapi-converter/converter"
+)
+
+func TestVitePressFrontmatterIsNotHTMLEscaped(t *testing.T) {
+	data, err := os.ReadFile("../examples/spec.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec := strings.Replace(string(data), "  title: Example API\n", "  title: \"O'Reilly <Books> & API\"\n", 1)
+	if err := os.MkdirAll("../../tmp/vitepress-title", 0755); err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll("../../tmp/vitepress-title")
+	if err := os.WriteFile("../../tmp/vitepress-title/spec.yml", []byte(spec), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	conv, err := converter.NewOpenApiConverter("../../tmp/vitepress-title/spec.yml")
+	if err != nil {
+		t.Fatalf("failed to load spec: %v", err)
+	}
+	conv.WriteIntroduction = true
+
+	if err := conv.WriteVitePressDocs("../../tmp/vitepress-title/docs"); err != nil {
+		t.Fatalf("WriteVitePressDocs failed: %v", err)
+	}
+
+	for _, page := range []string{"[tag].md", "introduction.md"} {
+		content, err := os.ReadFile("../../tmp/vitepress-title/docs/" + page)
+		if err != nil {
+			t.Fatalf("%s was not written: %v", page, err)
+		}
+
+		want := `title: "O'Reilly <Books> & API"`
+		if !strings.Contains(string(content), want) {
+			t.Errorf("%s frontmatter is missing %q:\n%s", page, want, content)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package traefik
+
+// Config is the root of a Traefik file-provider dynamic configuration
+type Config struct {
+	HTTP *HTTPConfig `yaml:"http" json:"http"`
+}
+
+// HTTPConfig holds the HTTP routers, services and middlewares
+type HTTPConfig struct {
+	Routers     map[string]*Router     `yaml:"routers" json:"routers"`
+	Services    map[string]*Service    `yaml:"services" json:"services"`
+	Middlewares map[string]*Middleware `yaml:"middlewares,omitempty" json:"middlewares,omitempty"`
+}
+
+// Router matches requests with a rule and hands them to a service
+type Router struct {
+	Rule        string   `yaml:"rule" json:"rule"`
+	Service     string   `yaml:"service" json:"service"`
+	EntryPoints []string `yaml:"entryPoints,omitempty" json:"entryPoints,omitempty"`
+	Middlewares []string `yaml:"middlewares,omitempty" json:"middlewares,omitempty"`
+	Priority    int      `yaml:"priority,omitempty" json:"priority,omitempty"`
+}
+
+// Service describes where matched requests are forwarded to
+type Service struct {
+	LoadBalancer *LoadBalancer `yaml:"loadBalancer" json:"loadBalancer"`
+}
+
+// LoadBalancer spreads requests over its servers
+type LoadBalancer struct {
+	Servers        []*Server `yaml:"servers" json:"servers"`
+	PassHostHeader *bool     `yaml:"passHostHeader,omitempty" json:"passHostHeader,omitempty"`
+}
+
+// Server is one backend of a load balancer
+type Server struct {
+	URL    string `yaml:"url" json:"url"`
+	Weight int    `yaml:"weight,omitempty" json:"weight,omitempty"`
+}
+
+// Middleware holds exactly one middleware definition
+type Middleware struct {
+	StripPrefix *StripPrefix `yaml:"stripPrefix,omitempty" json:"stripPrefix,omitempty"`
+	AddPrefix   *AddPrefix   `yaml:"addPrefix,omitempty" json:"addPrefix,omitempty"`
+	Headers     *Headers     `yaml:"headers,omitempty" json:"headers,omitempty"`
+}
+
+// StripPrefix removes path prefixes before forwarding
+type StripPrefix struct {
+	Prefixes []string `yaml:"prefixes" json:"prefixes"`
+}
+
+// AddPrefix prepends a path prefix before forwarding
+type AddPrefix struct {
+	Prefix string `yaml:"prefix" json:"prefix"`
+}
+
+// Headers manages request and response headers, including CORS and security headers
+type Headers struct {
+	CustomRequestHeaders              map[string]string `yaml:"customRequestHeaders,omitempty" json:"customRequestHeaders,omitempty"`
+	CustomResponseHeaders             map[string]string `yaml:"customResponseHeaders,omitempty" json:"customResponseHeaders,omitempty"`
+	AccessControlAllowOriginList      []string          `yaml:"accessControlAllowOriginList,omitempty" json:"accessControlAllowOriginList,omitempty"`
+	AccessControlAllowOriginListRegex []string          `yaml:"accessControlAllowOriginListRegex,omitempty" json:"accessControlAllowOriginListRegex,omitempty"`
+	AccessControlAllowMethods         []string          `yaml:"accessControlAllowMethods,omitempty" json:"accessControlAllowMethods,omitempty"`
+	AccessControlAllowHeaders         []string          `yaml:"accessControlAllowHeaders,omitempty" json:"accessControlAllowHeaders,omitempty"`
+	AccessControlExposeHeaders        []string          `yaml:"accessControlExposeHeaders,omitempty" json:"accessControlExposeHeaders,omitempty"`
+	AccessControlAllowCredentials     bool              `yaml:"accessControlAllowCredentials,omitempty" json:"accessControlAllowCredentials,omitempty"`
+	AccessControlMaxAge               int               `yaml:"accessControlMaxAge,omitempty" json:"accessControlMaxAge,omitempty"`
+	AddVaryHeader                     bool              `yaml:"addVaryHeader,omitempty" json:"addVaryHeader,omitempty"`
+	ContentTypeNosniff                bool              `yaml:"contentTypeNosniff,omitempty" json:"contentTypeNosniff,omitempty"`
+	BrowserXSSFilter                  bool              `yaml:"browserXssFilter,omitempty" json:"browserXssFilter,omitempty"`
+	STSSeconds                        int               `yaml:"stsSeconds,omitempty" json:"stsSeconds,omitempty"`
+	STSIncludeSubdomains              bool              `yaml:"stsIncludeSubdomains,omitempty" json:"stsIncludeSubdomains,omitempty"`
+}
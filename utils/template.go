@@ -2,19 +2,67 @@ package utils
 
 import (
 	"bytes"
-	"html/template"
+	htmltemplate "html/template"
+	"strconv"
+	"strings"
+	"text/template"
 )
 
-func ExecuteTemplate(name string, tmpl string, data interface{}) (string, error) {
-	t, err := template.New(name).Parse(tmpl)
-	if err != nil {
-		return "", err
-	}
+// TemplateFormat selects the escaping applied to values rendered into a template
+type TemplateFormat int
+
+const (
+	// FormatRaw renders values verbatim, for Nginx configs, JavaScript and other non-HTML output
+	FormatRaw TemplateFormat = iota
+	// FormatHTML escapes values for HTML contexts, for Markdown pages that embed Vue/HTML components
+	FormatHTML
+)
 
+var templateFuncs = map[string]interface{}{
+	"comment": SanitizeComment,
+	"quote":   QuoteConfigValue,
+	"yaml":    QuoteYAMLValue,
+}
+
+func ExecuteTemplate(format TemplateFormat, name string, tmpl string, data interface{}) (string, error) {
 	var buf bytes.Buffer
-	if err := t.Execute(&buf, data); err != nil {
-		return "", err
+
+	switch format {
+	case FormatHTML:
+		t, err := htmltemplate.New(name).Funcs(templateFuncs).Parse(tmpl)
+		if err != nil {
+			return "", err
+		}
+		if err := t.Execute(&buf, data); err != nil {
+			return "", err
+		}
+	default:
+		t, err := template.New(name).Funcs(templateFuncs).Parse(tmpl)
+		if err != nil {
+			return "", err
+		}
+		if err := t.Execute(&buf, data); err != nil {
+			return "", err
+		}
 	}
 
 	return buf.String(), nil
 }
+
+// SanitizeComment folds a value onto a single line so it can be placed after a
+// "#" in a configuration file without a line break ending the comment early
+func SanitizeComment(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
+
+// QuoteYAMLValue renders a value as a double-quoted YAML scalar, for frontmatter fields.
+// Go escapes are a subset of the escapes YAML allows in double-quoted scalars
+func QuoteYAMLValue(value string) string {
+	return strconv.Quote(value)
+}
+
+// QuoteConfigValue wraps a value in double quotes for Nginx-style configuration files
+func QuoteConfigValue(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", " ", "\r", " ")
+	return `"` + replacer.Replace(value) + `"`
+}